@@ -2,15 +2,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sso/pkg/bot"
 	"sso/pkg/config"
 	"sso/pkg/handler"
+	"sso/pkg/notifier"
+	"sso/pkg/outbox"
+	"sso/pkg/ratelimit"
 	"sso/pkg/repository"
 	"sso/pkg/services"
+	"sso/pkg/telegram"
+	"sso/pkg/webhook"
 	"syscall"
+	"time"
 
 	_ "github.com/lib/pq" // PostgreSQL драйвер
 )
@@ -18,8 +26,8 @@ import (
 // main является точкой входа в приложение
 func main() {
 	// Загружаем конфигурацию приложения
+	// Конфигурация не выводится в лог: она содержит пароль базы данных, токен бота и другие секреты
 	cfg := config.LoadConfig()
-	fmt.Printf("Config: %+v\n", cfg)
 
 	// Инициализируем подключение к базе данных PostgreSQL
 	db, err := repository.NewPostgresDB(cfg.DB)
@@ -31,11 +39,50 @@ func main() {
 	// Создаем репозиторий для работы с базой данных
 	authRepo := repository.NewRepository(db)
 
+	// Клиент Telegram Bot API создается, только если задан токен бота
+	var botClient *telegram.Client
+	var messenger services.Messenger
+	if cfg.Telegram.BotToken != "" {
+		botClient = telegram.NewClient(cfg.Telegram.APIURL, cfg.Telegram.BotToken)
+		messenger = botClient
+	}
+
 	// Инициализируем сервисы с бизнес-логикой
-	authService := services.NewAuthService(authRepo)
+	authService := services.NewAuthService(authRepo, cfg, messenger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Получатели событий outbox; уведомления через Telegram работают, только если задан токен бота
+	// Событие доставляется с репозиторием арендатора, в данных которого оно произошло
+	webhookStores := func(tenantID int) webhook.Store { return authRepo.ForTenant(tenantID) }
+	sinks := []outbox.Sink{webhook.NewSink(webhookStores, cfg.Webhooks)}
+	if botClient != nil {
+		chatStores := func(tenantID int) notifier.ChatStore { return authRepo.ForTenant(tenantID) }
+		sinks = append(sinks, notifier.NewTelegram(botClient, chatStores))
+
+		// Запускаем Telegram бота для привязки чатов и команд работы с очередями
+		location, err := time.LoadLocation(cfg.Calendar.TimeZone)
+		if err != nil {
+			location = time.UTC
+		}
+		go bot.NewBot(botClient, authService, cfg.Telegram.PollTimeout, location).Run(ctx)
+		log.Println("Telegram bot started")
+	}
+
+	// Запускаем доставку сообщений outbox
+	go outbox.NewDispatcher(authRepo, cfg.Outbox, sinks...).Run(ctx)
+
+	// Ограничения частоты входа хранятся в памяти или, для нескольких экземпляров, в PostgreSQL
+	var limitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.SignIn.RateLimitStore == "postgres" {
+		limitStore = ratelimit.StoreFunc(authRepo.TakeRateLimitToken)
+	}
+	ipLimiter := ratelimit.NewLimiter(limitStore, cfg.SignIn.IPPerMinute, cfg.SignIn.IPBurst)
+	accountLimiter := ratelimit.NewLimiter(limitStore, cfg.SignIn.AccountPerMinute, cfg.SignIn.AccountBurst)
 
 	// Создаем HTTP обработчики
-	handlers := handler.NewHandler(authService)
+	handlers := handler.NewHandler(authService, ipLimiter, accountLimiter)
 
 	// Инициализируем маршруты API
 	router := handlers.InitRoutes()
@@ -55,6 +102,7 @@ func main() {
 	// Ожидаем сигнал завершения приложения
 	<-killChan
 	log.Println("Shutting down server...")
+	cancel()
 
 	// Закрываем подключение к базе данных
 	if err := db.Close(); err != nil {
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS queue_slot_bookings;
+
+ALTER TABLE queues DROP CONSTRAINT IF EXISTS Queues_slot_duration_check;
+ALTER TABLE queues DROP CONSTRAINT IF EXISTS Queues_mode_check;
+ALTER TABLE queues DROP COLUMN IF EXISTS slot_duration;
+ALTER TABLE queues DROP COLUMN IF EXISTS mode;
+
+ALTER TABLE queues
+    ALTER COLUMN time_start TYPE time without time zone USING time_start::time,
+    ALTER COLUMN time_end TYPE time without time zone USING time_end::time;
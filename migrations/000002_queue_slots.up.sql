@@ -0,0 +1,34 @@
+-- Переводим время приема в timestamp: для расписания слотов важна дата, а не только время суток
+ALTER TABLE queues
+    ALTER COLUMN time_start TYPE timestamp with time zone USING (CURRENT_DATE + time_start),
+    ALTER COLUMN time_end TYPE timestamp with time zone USING (CURRENT_DATE + time_end);
+
+-- Режим работы очереди: живая очередь (fifo) или запись на слоты (slots)
+ALTER TABLE queues
+    ADD COLUMN IF NOT EXISTS mode varchar(16) NOT NULL DEFAULT 'fifo', -- Режим работы очереди
+    ADD COLUMN IF NOT EXISTS slot_duration integer NOT NULL DEFAULT 0; -- Длина слота в минутах (только для режима slots)
+
+ALTER TABLE queues
+    ADD CONSTRAINT Queues_mode_check CHECK (mode IN ('fifo', 'slots'));
+
+ALTER TABLE queues
+    ADD CONSTRAINT Queues_slot_duration_check CHECK (mode <> 'slots' OR slot_duration > 0);
+
+-- Таблица для хранения записей на слоты
+CREATE TABLE IF NOT EXISTS queue_slot_bookings (
+    id serial PRIMARY KEY, -- Уникальный идентификатор записи
+    queue_id integer NOT NULL, -- Идентификатор очереди
+    user_id integer NOT NULL, -- Идентификатор пользователя
+    slot_number integer NOT NULL, -- Номер слота (начиная с 1)
+    booked_at timestamp without time zone NOT NULL DEFAULT NOW(), -- Время записи или последнего переноса
+    CONSTRAINT Queue_slot_bookings_slot_unique UNIQUE (queue_id, slot_number), -- Один слот занимает не более одного пользователя
+    CONSTRAINT Queue_slot_bookings_user_unique UNIQUE (queue_id, user_id) -- Пользователь занимает не более одного слота в очереди
+);
+
+-- Внешний ключ для связи записей на слоты с очередями
+ALTER TABLE queue_slot_bookings
+    ADD CONSTRAINT Queue_slot_bookings_queue_fk FOREIGN KEY (queue_id) REFERENCES queues(id) ON DELETE CASCADE;
+
+-- Внешний ключ для связи записей на слоты с пользователями
+ALTER TABLE queue_slot_bookings
+    ADD CONSTRAINT Queue_slot_bookings_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
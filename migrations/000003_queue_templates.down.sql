@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS queue_template_groups;
+DROP TABLE IF EXISTS queue_templates;
+DROP TABLE IF EXISTS queue_groups;
+
+ALTER TABLE queues DROP COLUMN IF EXISTS desks;
+ALTER TABLE queues DROP COLUMN IF EXISTS capacity;
@@ -0,0 +1,47 @@
+-- Настройки очереди, которые переносятся при клонировании и в шаблоны
+ALTER TABLE queues
+    ADD COLUMN IF NOT EXISTS capacity integer NOT NULL DEFAULT 0, -- Максимальное число активных участников (0 - без ограничения)
+    ADD COLUMN IF NOT EXISTS desks integer NOT NULL DEFAULT 1; -- Количество столов приема
+
+-- Таблица групп, допущенных в очередь (пустой список - очередь открыта для всех)
+CREATE TABLE IF NOT EXISTS queue_groups (
+    queue_id integer NOT NULL, -- Идентификатор очереди
+    group_id integer NOT NULL, -- Идентификатор допущенной группы
+    PRIMARY KEY (queue_id, group_id) -- Первичный ключ
+);
+
+-- Таблица шаблонов очередей
+CREATE TABLE IF NOT EXISTS queue_templates (
+    id serial PRIMARY KEY, -- Уникальный идентификатор шаблона
+    title varchar(255) NOT NULL, -- Шаблон названия очереди (поддерживает {date} и {n})
+    duration integer NOT NULL, -- Длительность приема в минутах
+    mode varchar(16) NOT NULL DEFAULT 'fifo', -- Режим работы очереди
+    slot_duration integer NOT NULL DEFAULT 0, -- Длина слота в минутах (для режима slots)
+    capacity integer NOT NULL DEFAULT 0, -- Максимальное число активных участников
+    desks integer NOT NULL DEFAULT 1, -- Количество столов приема
+    created_at timestamp without time zone NOT NULL DEFAULT NOW() -- Время создания шаблона
+);
+
+-- Таблица групп, допущенных в очереди, создаваемые по шаблону
+CREATE TABLE IF NOT EXISTS queue_template_groups (
+    template_id integer NOT NULL, -- Идентификатор шаблона
+    group_id integer NOT NULL, -- Идентификатор допущенной группы
+    PRIMARY KEY (template_id, group_id) -- Первичный ключ
+);
+
+-- Внешние ключи для связи допущенных групп с очередями и группами
+ALTER TABLE queue_groups
+    ADD CONSTRAINT Queue_groups_queue_fk FOREIGN KEY (queue_id) REFERENCES queues(id) ON DELETE CASCADE;
+
+ALTER TABLE queue_groups
+    ADD CONSTRAINT Queue_groups_group_fk FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
+
+-- Внешние ключи для связи групп шаблона с шаблонами и группами
+ALTER TABLE queue_template_groups
+    ADD CONSTRAINT Queue_template_groups_template_fk FOREIGN KEY (template_id) REFERENCES queue_templates(id) ON DELETE CASCADE;
+
+ALTER TABLE queue_template_groups
+    ADD CONSTRAINT Queue_template_groups_group_fk FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
+
+-- Индекс для ускорения поиска очередей, доступных группе
+CREATE INDEX IF NOT EXISTS queue_groups_group_index ON queue_groups (group_id);
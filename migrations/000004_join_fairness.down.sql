@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS queue_participants_user_joined_index;
+
+ALTER TABLE queue_participants DROP COLUMN IF EXISTS left_at;
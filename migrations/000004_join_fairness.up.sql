@@ -0,0 +1,6 @@
+-- Время выхода участника из очереди, нужно для проверки паузы перед повторным присоединением
+ALTER TABLE queue_participants
+    ADD COLUMN IF NOT EXISTS left_at timestamp with time zone; -- Время выхода из очереди
+
+-- Индекс для ускорения подсчета присоединений пользователя за период
+CREATE INDEX IF NOT EXISTS queue_participants_user_joined_index ON queue_participants (user_id, joined_at);
@@ -0,0 +1,29 @@
+-- Таблица истории переходов участников очередей между состояниями
+CREATE TABLE IF NOT EXISTS queue_events (
+    id bigserial PRIMARY KEY, -- Уникальный идентификатор события
+    queue_id integer NOT NULL, -- Идентификатор очереди
+    user_id integer NOT NULL, -- Идентификатор пользователя
+    group_id integer, -- Группа пользователя на момент события
+    event_type varchar(32) NOT NULL, -- Тип события (joined, left, served, no_show, booked, rescheduled, cancelled)
+    position integer, -- Позиция или номер слота участника на момент события
+    wait_seconds integer, -- Время ожидания в секундах (для served и no_show)
+    created_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время события
+);
+
+-- Внешний ключ для связи событий с очередями
+ALTER TABLE queue_events
+    ADD CONSTRAINT Queue_events_queue_fk FOREIGN KEY (queue_id) REFERENCES queues(id) ON DELETE CASCADE;
+
+-- Внешний ключ для связи событий с пользователями
+ALTER TABLE queue_events
+    ADD CONSTRAINT Queue_events_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+-- Внешний ключ для связи событий с группами
+ALTER TABLE queue_events
+    ADD CONSTRAINT Queue_events_group_fk FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE SET NULL;
+
+-- Индекс для ускорения выборки событий очереди за период
+CREATE INDEX IF NOT EXISTS queue_events_queue_created_index ON queue_events (queue_id, created_at);
+
+-- Индекс для ускорения выборки событий за период по всем очередям
+CREATE INDEX IF NOT EXISTS queue_events_created_index ON queue_events (created_at);
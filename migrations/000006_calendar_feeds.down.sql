@@ -0,0 +1,4 @@
+ALTER TABLE queues DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE queues DROP COLUMN IF EXISTS sequence;
+
+ALTER TABLE users DROP COLUMN IF EXISTS calendar_token;
@@ -0,0 +1,8 @@
+-- Токен персональной ленты календаря пользователя
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS calendar_token varchar(64) UNIQUE; -- Секретный токен ленты iCalendar
+
+-- Версия очереди для обновления событий в календарях подписчиков
+ALTER TABLE queues
+    ADD COLUMN IF NOT EXISTS sequence integer NOT NULL DEFAULT 0, -- Номер ревизии очереди (SEQUENCE в iCalendar)
+    ADD COLUMN IF NOT EXISTS updated_at timestamp with time zone NOT NULL DEFAULT NOW(); -- Время последнего изменения очереди
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS telegram_link_codes;
+
+ALTER TABLE users DROP COLUMN IF EXISTS tg_chat_id;
@@ -0,0 +1,14 @@
+-- Чат Telegram, в который бот отправляет уведомления пользователю
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS tg_chat_id bigint UNIQUE; -- ID чата с ботом (NULL - чат не привязан)
+
+-- Таблица одноразовых кодов привязки чата через ссылку t.me/<бот>?start=<код>
+CREATE TABLE IF NOT EXISTS telegram_link_codes (
+    code varchar(64) PRIMARY KEY, -- Код привязки
+    user_id integer NOT NULL, -- Идентификатор пользователя, запросившего привязку
+    expires_at timestamp with time zone NOT NULL -- Время истечения кода
+);
+
+-- Внешний ключ для связи кодов привязки с пользователями
+ALTER TABLE telegram_link_codes
+    ADD CONSTRAINT Telegram_link_codes_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+-- Подтвержденный аккаунт Telegram, через который пользователь входит с помощью Telegram Login Widget
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS tg_user_id bigint UNIQUE; -- ID пользователя Telegram (NULL - вход через Telegram не подключен)
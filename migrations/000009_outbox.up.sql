@@ -0,0 +1,18 @@
+-- Таблица исходящих сообщений (transactional outbox): сообщения записываются в одной транзакции
+-- с изменением очереди и доставляются получателям фоновым диспетчером
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id bigserial PRIMARY KEY, -- Уникальный идентификатор сообщения
+    event_type varchar(64) NOT NULL, -- Тип события (queue.created, participant.joined, ...)
+    payload jsonb NOT NULL, -- Данные события
+    status varchar(16) NOT NULL DEFAULT 'pending', -- Состояние доставки (pending, delivered, dead)
+    delivered_sinks text[] NOT NULL DEFAULT '{}', -- Получатели, которым сообщение уже доставлено
+    attempts integer NOT NULL DEFAULT 0, -- Число неудачных попыток доставки
+    next_attempt_at timestamp with time zone NOT NULL DEFAULT NOW(), -- Время следующей попытки
+    last_error text, -- Текст последней ошибки доставки
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(), -- Время создания сообщения
+    delivered_at timestamp with time zone, -- Время успешной доставки всем получателям
+    CONSTRAINT outbox_messages_status_check CHECK (status IN ('pending', 'delivered', 'dead'))
+);
+
+-- Индекс для выборки сообщений, ожидающих доставки
+CREATE INDEX IF NOT EXISTS outbox_messages_pending_index ON outbox_messages (next_attempt_at) WHERE status = 'pending';
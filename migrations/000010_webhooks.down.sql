@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+
+ALTER TABLE queues DROP COLUMN IF EXISTS closed_at;
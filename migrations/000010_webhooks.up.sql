@@ -0,0 +1,33 @@
+-- Время закрытия очереди: после закрытия к очереди нельзя присоединиться
+ALTER TABLE queues
+    ADD COLUMN IF NOT EXISTS closed_at timestamp with time zone; -- Время закрытия очереди (NULL - очередь открыта)
+
+-- Таблица исходящих webhook-подписок
+CREATE TABLE IF NOT EXISTS webhooks (
+    id serial PRIMARY KEY, -- Уникальный идентификатор подписки
+    url varchar(2048) NOT NULL, -- Адрес, на который отправляются события
+    secret varchar(255) NOT NULL, -- Секрет для подписи HMAC-SHA256
+    event_types text[] NOT NULL, -- Типы событий подписки
+    is_active boolean NOT NULL DEFAULT TRUE, -- Флаг активности подписки
+    created_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время создания подписки
+);
+
+-- Таблица журнала доставки webhook
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id bigserial PRIMARY KEY, -- Уникальный идентификатор попытки доставки
+    webhook_id integer NOT NULL, -- Идентификатор подписки
+    outbox_message_id bigint NOT NULL, -- Идентификатор доставляемого сообщения outbox
+    event_type varchar(64) NOT NULL, -- Тип события
+    status_code integer, -- HTTP статус ответа (NULL - ответ не получен)
+    success boolean NOT NULL, -- Флаг успешной доставки
+    error text, -- Текст ошибки доставки
+    duration_ms integer NOT NULL, -- Длительность запроса в миллисекундах
+    created_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время попытки
+);
+
+-- Внешний ключ для связи доставок с подписками
+ALTER TABLE webhook_deliveries
+    ADD CONSTRAINT Webhook_deliveries_webhook_fk FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE;
+
+-- Индекс для проверки успешной доставки сообщения подписке и просмотра журнала
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_message_index ON webhook_deliveries (webhook_id, outbox_message_id);
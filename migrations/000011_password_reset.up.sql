@@ -0,0 +1,14 @@
+-- Таблица одноразовых токенов сброса пароля, выдаваемых администратором
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    token_hash varchar(64) PRIMARY KEY, -- SHA-256 токена (сам токен не хранится)
+    user_id integer NOT NULL, -- Идентификатор пользователя, для которого выдан токен
+    created_by integer, -- Администратор, выдавший токен
+    expires_at timestamp with time zone NOT NULL, -- Время истечения токена
+    created_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время выдачи токена
+);
+
+-- Внешние ключи для связи токенов с пользователями
+ALTER TABLE password_reset_tokens
+    ADD CONSTRAINT Password_reset_tokens_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE password_reset_tokens
+    ADD CONSTRAINT Password_reset_tokens_created_by_fk FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL;
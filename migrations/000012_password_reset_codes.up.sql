@@ -0,0 +1,12 @@
+-- Таблица одноразовых кодов сброса пароля, отправляемых в привязанный чат Telegram
+CREATE TABLE IF NOT EXISTS password_reset_codes (
+    user_id integer PRIMARY KEY, -- Идентификатор пользователя (у пользователя не больше одного активного кода)
+    code_hash varchar(64) NOT NULL, -- SHA-256 кода (сам код не хранится)
+    attempts integer NOT NULL DEFAULT 0, -- Число попыток ввода кода
+    expires_at timestamp with time zone NOT NULL, -- Время истечения кода
+    created_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время отправки кода
+);
+
+-- Внешний ключ для связи кодов сброса с пользователями
+ALTER TABLE password_reset_codes
+    ADD CONSTRAINT Password_reset_codes_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,13 @@
+-- Счетчик неудачных попыток входа и временная блокировка аккаунта
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS failed_sign_ins integer NOT NULL DEFAULT 0, -- Неудачных попыток входа подряд
+    ADD COLUMN IF NOT EXISTS locked_until timestamp with time zone; -- Время окончания блокировки (NULL - не заблокирован)
+
+-- Корзины токенов ограничения частоты входа, общие для всех экземпляров приложения
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key varchar(255) PRIMARY KEY, -- Ключ ограничения (IP-адрес или аккаунт)
+    tokens double precision NOT NULL, -- Оставшиеся токены
+    updated_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время последнего пересчета
+);
+
+CREATE INDEX IF NOT EXISTS rate_limit_buckets_updated_at_idx ON rate_limit_buckets (updated_at);
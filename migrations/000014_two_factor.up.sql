@@ -0,0 +1,20 @@
+-- Двухфакторная аутентификация по одноразовым кодам (TOTP)
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS totp_secret varchar(64), -- Секрет TOTP в base32 (NULL - не настраивался)
+    ADD COLUMN IF NOT EXISTS totp_enabled boolean NOT NULL DEFAULT false, -- Подтверждено ли подключение TOTP
+    ADD COLUMN IF NOT EXISTS totp_last_step bigint; -- Последний использованный временной шаг (защита от повтора кода)
+
+-- Таблица резервных кодов для входа без приложения-аутентификатора
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id serial PRIMARY KEY, -- Уникальный идентификатор кода
+    user_id integer NOT NULL, -- Идентификатор владельца
+    code_hash varchar(64) NOT NULL, -- SHA-256 кода (сам код не хранится)
+    used_at timestamp with time zone, -- Время использования (NULL - не использован)
+    created_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время выдачи
+);
+
+-- Внешний ключ для связи резервных кодов с пользователями
+ALTER TABLE recovery_codes
+    ADD CONSTRAINT Recovery_codes_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS recovery_codes_user_id_idx ON recovery_codes (user_id);
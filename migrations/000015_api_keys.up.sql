@@ -0,0 +1,19 @@
+-- Таблица персональных API-ключей для скриптов и интеграций
+CREATE TABLE IF NOT EXISTS api_keys (
+    id serial PRIMARY KEY, -- Уникальный идентификатор ключа
+    user_id integer NOT NULL, -- Владелец ключа; запросы по ключу выполняются от его имени
+    name varchar(255) NOT NULL, -- Название ключа, заданное владельцем
+    prefix varchar(16) NOT NULL UNIQUE, -- Открытая часть ключа для поиска и отображения
+    key_hash varchar(64) NOT NULL, -- SHA-256 ключа (сам ключ не хранится)
+    scopes text[] NOT NULL DEFAULT '{}', -- Области доступа: read, write, admin
+    expires_at timestamp with time zone, -- Время истечения (NULL - бессрочный)
+    last_used_at timestamp with time zone, -- Время последнего использования
+    revoked_at timestamp with time zone, -- Время отзыва (NULL - действует)
+    created_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время создания
+);
+
+-- Внешний ключ для связи API-ключей с пользователями
+ALTER TABLE api_keys
+    ADD CONSTRAINT Api_keys_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,17 @@
+-- Таблица сессий: каждому выданному JWT токену соответствует сессия, которую можно отозвать
+CREATE TABLE IF NOT EXISTS sessions (
+    id serial PRIMARY KEY, -- Уникальный идентификатор сессии (claim sid в JWT токене)
+    user_id integer NOT NULL, -- Пользователь, вошедший в систему
+    user_agent text NOT NULL DEFAULT '', -- User-Agent клиента при входе
+    ip varchar(64) NOT NULL DEFAULT '', -- IP-адрес клиента при входе
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(), -- Время входа
+    last_seen_at timestamp with time zone NOT NULL DEFAULT NOW(), -- Время последнего запроса с токеном сессии
+    expires_at timestamp with time zone NOT NULL, -- Время истечения токена
+    revoked_at timestamp with time zone -- Время отзыва (NULL - активна)
+);
+
+-- Внешний ключ для связи сессий с пользователями
+ALTER TABLE sessions
+    ADD CONSTRAINT Sessions_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
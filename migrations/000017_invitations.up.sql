@@ -0,0 +1,40 @@
+-- Таблица модераторов групп: модератор выдает приглашения в свою группу
+CREATE TABLE IF NOT EXISTS group_moderators (
+    group_id integer NOT NULL, -- Группа
+    user_id integer NOT NULL, -- Модератор группы
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(), -- Время назначения
+    PRIMARY KEY (group_id, user_id)
+);
+
+-- Внешний ключ для связи модераторов с группами
+ALTER TABLE group_moderators
+    ADD CONSTRAINT Group_moderators_group_fk FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
+
+-- Внешний ключ для связи модераторов с пользователями
+ALTER TABLE group_moderators
+    ADD CONSTRAINT Group_moderators_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS group_moderators_user_id_idx ON group_moderators (user_id);
+
+-- Таблица приглашений для регистрации в группе
+CREATE TABLE IF NOT EXISTS invitations (
+    id serial PRIMARY KEY, -- Уникальный идентификатор приглашения
+    group_id integer NOT NULL, -- Группа, в которую регистрируется приглашенный
+    code_hash varchar(64) NOT NULL UNIQUE, -- SHA-256 кода приглашения (сам код не хранится)
+    created_by integer, -- Модератор или администратор, выдавший приглашение
+    max_uses integer NOT NULL DEFAULT 1, -- Максимальное число регистраций по приглашению
+    uses integer NOT NULL DEFAULT 0, -- Число регистраций по приглашению
+    expires_at timestamp with time zone NOT NULL, -- Время истечения приглашения
+    revoked_at timestamp with time zone, -- Время отзыва (NULL - действует)
+    created_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время создания
+);
+
+-- Внешний ключ для связи приглашений с группами
+ALTER TABLE invitations
+    ADD CONSTRAINT Invitations_group_fk FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
+
+-- Внешний ключ для связи приглашений с пользователем, выдавшим их
+ALTER TABLE invitations
+    ADD CONSTRAINT Invitations_created_by_fk FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS invitations_group_id_idx ON invitations (group_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS users_pending_group_idx;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS Users_approved_by_fk,
+    DROP CONSTRAINT IF EXISTS Users_status_check,
+    DROP COLUMN IF EXISTS approved_at,
+    DROP COLUMN IF EXISTS approved_by,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,15 @@
+-- Статус пользователя: зарегистрировавшийся сам ждет подтверждения модератором группы
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS status varchar(16) NOT NULL DEFAULT 'active', -- pending - ждет подтверждения, active - подтвержден
+    ADD COLUMN IF NOT EXISTS approved_by integer, -- Модератор или администратор, подтвердивший регистрацию
+    ADD COLUMN IF NOT EXISTS approved_at timestamp with time zone; -- Время подтверждения
+
+ALTER TABLE users
+    ADD CONSTRAINT Users_status_check CHECK (status IN ('pending', 'active'));
+
+-- Внешний ключ для связи пользователя с подтвердившим его модератором
+ALTER TABLE users
+    ADD CONSTRAINT Users_approved_by_fk FOREIGN KEY (approved_by) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Индекс для выборки ожидающих подтверждения пользователей группы
+CREATE INDEX IF NOT EXISTS users_pending_group_idx ON users (group_id) WHERE status = 'pending';
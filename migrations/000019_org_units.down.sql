@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS queue_org_units;
+DROP TABLE IF EXISTS org_unit_admins;
+
+ALTER TABLE groups
+    DROP CONSTRAINT IF EXISTS Groups_org_unit_fk,
+    DROP COLUMN IF EXISTS org_unit_id;
+
+DROP TABLE IF EXISTS org_units;
@@ -0,0 +1,58 @@
+-- Таблица организационных единиц: факультеты и кафедры образуют дерево, группы - его листья
+CREATE TABLE IF NOT EXISTS org_units (
+    id serial PRIMARY KEY, -- Уникальный идентификатор единицы
+    parent_id integer, -- Родительская единица (NULL - корень дерева)
+    kind varchar(16) NOT NULL, -- Тип единицы: faculty или department
+    code varchar(64) NOT NULL UNIQUE, -- Код единицы (например, "ИУ" или "ИУ7")
+    title varchar(255) NOT NULL DEFAULT '', -- Полное название
+    created_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время создания
+);
+
+ALTER TABLE org_units
+    ADD CONSTRAINT Org_units_kind_check CHECK (kind IN ('faculty', 'department'));
+
+-- Внешний ключ для связи единицы с родительской; непустую единицу удалить нельзя
+ALTER TABLE org_units
+    ADD CONSTRAINT Org_units_parent_fk FOREIGN KEY (parent_id) REFERENCES org_units(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS org_units_parent_id_idx ON org_units (parent_id);
+
+-- Группа входит в организационную единицу (NULL - вне дерева)
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS org_unit_id integer;
+
+ALTER TABLE groups
+    ADD CONSTRAINT Groups_org_unit_fk FOREIGN KEY (org_unit_id) REFERENCES org_units(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS groups_org_unit_id_idx ON groups (org_unit_id);
+
+-- Таблица администраторов организационных единиц: права действуют на все поддерево
+CREATE TABLE IF NOT EXISTS org_unit_admins (
+    org_unit_id integer NOT NULL, -- Организационная единица
+    user_id integer NOT NULL, -- Администратор единицы
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(), -- Время назначения
+    PRIMARY KEY (org_unit_id, user_id)
+);
+
+ALTER TABLE org_unit_admins
+    ADD CONSTRAINT Org_unit_admins_unit_fk FOREIGN KEY (org_unit_id) REFERENCES org_units(id) ON DELETE CASCADE;
+
+ALTER TABLE org_unit_admins
+    ADD CONSTRAINT Org_unit_admins_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS org_unit_admins_user_id_idx ON org_unit_admins (user_id);
+
+-- Таблица организационных единиц, допущенных в очередь, вместе со всеми группами своего поддерева
+CREATE TABLE IF NOT EXISTS queue_org_units (
+    queue_id integer NOT NULL, -- Идентификатор очереди
+    org_unit_id integer NOT NULL, -- Идентификатор допущенной единицы
+    PRIMARY KEY (queue_id, org_unit_id)
+);
+
+ALTER TABLE queue_org_units
+    ADD CONSTRAINT Queue_org_units_queue_fk FOREIGN KEY (queue_id) REFERENCES queues(id) ON DELETE CASCADE;
+
+ALTER TABLE queue_org_units
+    ADD CONSTRAINT Queue_org_units_unit_fk FOREIGN KEY (org_unit_id) REFERENCES org_units(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS queue_org_units_unit_idx ON queue_org_units (org_unit_id);
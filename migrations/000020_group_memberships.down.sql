@@ -0,0 +1,50 @@
+DROP INDEX IF EXISTS users_pending_idx;
+
+-- Возвращаем пользователю одну группу: первую, в которой он участник, иначе первую, которую он модерирует
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS group_id integer;
+
+UPDATE users u SET group_id = (
+    SELECT m.group_id FROM group_memberships m
+    WHERE m.user_id = u.id
+    ORDER BY m.role = 'member' DESC, m.group_id
+    LIMIT 1
+);
+
+-- Пользователей без групп нельзя вернуть в схему с обязательной группой; откат прерывается,
+-- чтобы не удалить их аккаунты вместе с историей: сначала добавьте таких пользователей в группу
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE group_id IS NULL) THEN
+        RAISE EXCEPTION 'users without groups exist: add them to a group before rolling back';
+    END IF;
+END $$;
+
+ALTER TABLE users
+    ALTER COLUMN group_id SET NOT NULL;
+
+ALTER TABLE users
+    ADD CONSTRAINT Users_in_Groups_fk FOREIGN KEY (group_id) REFERENCES groups(id);
+
+CREATE INDEX IF NOT EXISTS users_group_id_index ON users (group_id);
+CREATE INDEX IF NOT EXISTS users_pending_group_idx ON users (group_id) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS group_moderators (
+    group_id integer NOT NULL,
+    user_id integer NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+ALTER TABLE group_moderators
+    ADD CONSTRAINT Group_moderators_group_fk FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
+
+ALTER TABLE group_moderators
+    ADD CONSTRAINT Group_moderators_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS group_moderators_user_id_idx ON group_moderators (user_id);
+
+INSERT INTO group_moderators (group_id, user_id, created_at)
+SELECT group_id, user_id, created_at FROM group_memberships WHERE role = 'moderator';
+
+DROP TABLE IF EXISTS group_memberships;
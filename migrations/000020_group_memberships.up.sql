@@ -0,0 +1,40 @@
+-- Таблица членства пользователей в группах: пользователь может состоять в нескольких группах с ролью в каждой
+CREATE TABLE IF NOT EXISTS group_memberships (
+    group_id integer NOT NULL, -- Группа
+    user_id integer NOT NULL, -- Участник группы
+    role varchar(16) NOT NULL DEFAULT 'member', -- Роль в группе: member или moderator
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(), -- Время вступления в группу
+    PRIMARY KEY (group_id, user_id)
+);
+
+ALTER TABLE group_memberships
+    ADD CONSTRAINT Group_memberships_role_check CHECK (role IN ('member', 'moderator'));
+
+-- Внешние ключи для связи членства с группами и пользователями
+ALTER TABLE group_memberships
+    ADD CONSTRAINT Group_memberships_group_fk FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
+
+ALTER TABLE group_memberships
+    ADD CONSTRAINT Group_memberships_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS group_memberships_user_id_idx ON group_memberships (user_id);
+
+-- Переносим группу пользователя и модераторов групп в таблицу членства
+INSERT INTO group_memberships (group_id, user_id, role)
+SELECT group_id, id, 'member' FROM users WHERE group_id IS NOT NULL;
+
+INSERT INTO group_memberships (group_id, user_id, role, created_at)
+SELECT group_id, user_id, 'moderator', created_at FROM group_moderators
+ON CONFLICT (group_id, user_id) DO UPDATE SET role = 'moderator';
+
+DROP TABLE IF EXISTS group_moderators;
+
+DROP INDEX IF EXISTS users_pending_group_idx;
+DROP INDEX IF EXISTS users_group_id_index;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS Users_in_Groups_fk,
+    DROP COLUMN IF EXISTS group_id;
+
+-- Индекс для выборки ожидающих подтверждения пользователей
+CREATE INDEX IF NOT EXISTS users_pending_idx ON users (id) WHERE status = 'pending';
@@ -0,0 +1,67 @@
+DROP INDEX IF EXISTS webhooks_tenant_id_idx;
+DROP INDEX IF EXISTS queue_templates_tenant_id_idx;
+DROP INDEX IF EXISTS queues_tenant_id_idx;
+
+-- Данные других арендаторов нельзя вернуть в схему без арендаторов
+DELETE FROM outbox_messages WHERE tenant_id <> 1;
+DELETE FROM webhooks WHERE tenant_id <> 1;
+DELETE FROM queue_templates WHERE tenant_id <> 1;
+DELETE FROM queue_participants WHERE tenant_id <> 1;
+DELETE FROM queues WHERE tenant_id <> 1;
+DELETE FROM users WHERE tenant_id <> 1;
+DELETE FROM groups WHERE tenant_id <> 1;
+DELETE FROM org_units WHERE tenant_id <> 1;
+
+ALTER TABLE org_units DROP CONSTRAINT IF EXISTS Org_units_tenant_code_unique;
+ALTER TABLE org_units ADD CONSTRAINT org_units_code_key UNIQUE (code);
+
+ALTER TABLE groups DROP CONSTRAINT IF EXISTS Groups_tenant_code_unique;
+ALTER TABLE groups ADD CONSTRAINT groups_code_key UNIQUE (code);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS Users_tenant_tg_user_id_unique;
+ALTER TABLE users ADD CONSTRAINT users_tg_user_id_key UNIQUE (tg_user_id);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS Users_tenant_tg_nick_unique;
+ALTER TABLE users ADD CONSTRAINT users_tg_nick_key UNIQUE (tg_nick);
+
+ALTER TABLE queue_slot_bookings
+    DROP CONSTRAINT IF EXISTS Queue_slot_bookings_user_tenant_fk,
+    DROP CONSTRAINT IF EXISTS Queue_slot_bookings_queue_tenant_fk,
+    DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE queue_participants
+    DROP CONSTRAINT IF EXISTS Queue_participants_user_tenant_fk,
+    DROP CONSTRAINT IF EXISTS Queue_participants_queue_tenant_fk,
+    DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE outbox_messages
+    DROP CONSTRAINT IF EXISTS Outbox_messages_tenant_fk,
+    DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE webhooks
+    DROP CONSTRAINT IF EXISTS Webhooks_tenant_fk,
+    DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE org_units
+    DROP CONSTRAINT IF EXISTS Org_units_tenant_fk,
+    DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE queue_templates
+    DROP CONSTRAINT IF EXISTS Queue_templates_tenant_fk,
+    DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE queues
+    DROP CONSTRAINT IF EXISTS Queues_id_tenant_unique,
+    DROP CONSTRAINT IF EXISTS Queues_tenant_fk,
+    DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE groups
+    DROP CONSTRAINT IF EXISTS Groups_tenant_fk,
+    DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS Users_id_tenant_unique,
+    DROP CONSTRAINT IF EXISTS Users_tenant_fk,
+    DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
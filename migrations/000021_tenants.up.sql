@@ -0,0 +1,96 @@
+-- Таблица арендаторов: университетов или факультетов, обслуживаемых одним экземпляром приложения
+CREATE TABLE IF NOT EXISTS tenants (
+    id serial PRIMARY KEY, -- Уникальный идентификатор арендатора
+    code varchar(64) NOT NULL UNIQUE, -- Код арендатора, передаваемый в заголовке X-Tenant
+    title varchar(255) NOT NULL, -- Название арендатора
+    created_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время создания арендатора
+);
+
+-- Основной арендатор получает все существующие данные; его администраторы управляют арендаторами
+INSERT INTO tenants (id, code, title) VALUES (1, 'default', 'Default') ON CONFLICT DO NOTHING;
+SELECT setval('tenants_id_seq', (SELECT MAX(id) FROM tenants));
+
+-- Арендатор пользователей, групп, очередей, их участников и остальных корневых таблиц.
+-- Значение по умолчанию нужно только для переноса существующих строк
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id integer NOT NULL DEFAULT 1;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS tenant_id integer NOT NULL DEFAULT 1;
+ALTER TABLE queues ADD COLUMN IF NOT EXISTS tenant_id integer NOT NULL DEFAULT 1;
+ALTER TABLE queue_participants ADD COLUMN IF NOT EXISTS tenant_id integer NOT NULL DEFAULT 1;
+ALTER TABLE queue_slot_bookings ADD COLUMN IF NOT EXISTS tenant_id integer NOT NULL DEFAULT 1;
+ALTER TABLE queue_templates ADD COLUMN IF NOT EXISTS tenant_id integer NOT NULL DEFAULT 1;
+ALTER TABLE org_units ADD COLUMN IF NOT EXISTS tenant_id integer NOT NULL DEFAULT 1;
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS tenant_id integer NOT NULL DEFAULT 1;
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS tenant_id integer NOT NULL DEFAULT 1;
+
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE groups ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE queues ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE queue_participants ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE queue_slot_bookings ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE queue_templates ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE org_units ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE webhooks ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE outbox_messages ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- Внешние ключи для связи с арендатором; удаление арендатора удаляет все его данные
+ALTER TABLE users
+    ADD CONSTRAINT Users_tenant_fk FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+ALTER TABLE groups
+    ADD CONSTRAINT Groups_tenant_fk FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+ALTER TABLE queues
+    ADD CONSTRAINT Queues_tenant_fk FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+ALTER TABLE queue_templates
+    ADD CONSTRAINT Queue_templates_tenant_fk FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+ALTER TABLE org_units
+    ADD CONSTRAINT Org_units_tenant_fk FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+ALTER TABLE webhooks
+    ADD CONSTRAINT Webhooks_tenant_fk FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+ALTER TABLE outbox_messages
+    ADD CONSTRAINT Outbox_messages_tenant_fk FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+-- Участник очереди и запись на слот принадлежат тому же арендатору, что очередь и пользователь
+ALTER TABLE users
+    ADD CONSTRAINT Users_id_tenant_unique UNIQUE (id, tenant_id);
+
+ALTER TABLE queues
+    ADD CONSTRAINT Queues_id_tenant_unique UNIQUE (id, tenant_id);
+
+ALTER TABLE queue_participants
+    ADD CONSTRAINT Queue_participants_queue_tenant_fk FOREIGN KEY (queue_id, tenant_id) REFERENCES queues(id, tenant_id) ON DELETE CASCADE;
+
+ALTER TABLE queue_participants
+    ADD CONSTRAINT Queue_participants_user_tenant_fk FOREIGN KEY (user_id, tenant_id) REFERENCES users(id, tenant_id) ON DELETE CASCADE;
+
+ALTER TABLE queue_slot_bookings
+    ADD CONSTRAINT Queue_slot_bookings_queue_tenant_fk FOREIGN KEY (queue_id, tenant_id) REFERENCES queues(id, tenant_id) ON DELETE CASCADE;
+
+ALTER TABLE queue_slot_bookings
+    ADD CONSTRAINT Queue_slot_bookings_user_tenant_fk FOREIGN KEY (user_id, tenant_id) REFERENCES users(id, tenant_id) ON DELETE CASCADE;
+
+-- Ники, коды групп и единиц и аккаунты Telegram уникальны в пределах арендатора
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_tg_nick_key;
+ALTER TABLE users
+    ADD CONSTRAINT Users_tenant_tg_nick_unique UNIQUE (tenant_id, tg_nick);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_tg_user_id_key;
+ALTER TABLE users
+    ADD CONSTRAINT Users_tenant_tg_user_id_unique UNIQUE (tenant_id, tg_user_id);
+
+ALTER TABLE groups DROP CONSTRAINT IF EXISTS groups_code_key;
+ALTER TABLE groups
+    ADD CONSTRAINT Groups_tenant_code_unique UNIQUE (tenant_id, code);
+
+ALTER TABLE org_units DROP CONSTRAINT IF EXISTS org_units_code_key;
+ALTER TABLE org_units
+    ADD CONSTRAINT Org_units_tenant_code_unique UNIQUE (tenant_id, code);
+
+-- Индексы для выборки данных арендатора
+CREATE INDEX IF NOT EXISTS queues_tenant_id_idx ON queues (tenant_id);
+CREATE INDEX IF NOT EXISTS queue_templates_tenant_id_idx ON queue_templates (tenant_id);
+CREATE INDEX IF NOT EXISTS webhooks_tenant_id_idx ON webhooks (tenant_id);
@@ -0,0 +1,9 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS failed_sign_ins integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS locked_until timestamp with time zone;
+
+UPDATE users u SET failed_sign_ins = f.failures, locked_until = f.locked_until
+FROM sign_in_failures f
+WHERE f.tenant_id = u.tenant_id AND f.tg_nick = LOWER(u.tg_nick);
+
+DROP TABLE IF EXISTS sign_in_failures;
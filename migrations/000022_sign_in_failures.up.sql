@@ -0,0 +1,22 @@
+-- Неудачные попытки входа и блокировки ведутся по нику, а не по пользователю: несуществующий ник блокируется
+-- так же, как существующий, и по ответу нельзя узнать, есть ли аккаунт
+CREATE TABLE IF NOT EXISTS sign_in_failures (
+    tenant_id integer NOT NULL REFERENCES tenants(id) ON DELETE CASCADE, -- Арендатор, в котором выполнялся вход
+    tg_nick varchar(255) NOT NULL, -- Telegram ник в нижнем регистре
+    failures integer NOT NULL DEFAULT 0, -- Неудачных попыток входа подряд
+    locked_until timestamp with time zone, -- Время окончания блокировки (NULL - не заблокирован)
+    updated_at timestamp with time zone NOT NULL DEFAULT NOW(), -- Время последней неудачной попытки
+    PRIMARY KEY (tenant_id, tg_nick)
+);
+
+CREATE INDEX IF NOT EXISTS sign_in_failures_updated_at_idx ON sign_in_failures (updated_at);
+
+-- Переносим счетчики и действующие блокировки пользователей
+INSERT INTO sign_in_failures (tenant_id, tg_nick, failures, locked_until)
+SELECT tenant_id, LOWER(tg_nick), failed_sign_ins, locked_until FROM users
+WHERE failed_sign_ins > 0 OR locked_until > NOW()
+ON CONFLICT (tenant_id, tg_nick) DO NOTHING;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS failed_sign_ins;
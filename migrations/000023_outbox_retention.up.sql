@@ -0,0 +1,2 @@
+-- Индекс для удаления доставленных сообщений outbox старше срока хранения
+CREATE INDEX IF NOT EXISTS outbox_messages_delivered_index ON outbox_messages (delivered_at) WHERE status = 'delivered';
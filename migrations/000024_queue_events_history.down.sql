@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS queue_events_tenant_created_index;
+
+-- Прежние внешние ключи не допускают событий удаленных очередей и пользователей
+DELETE FROM queue_events WHERE user_id IS NULL OR queue_id NOT IN (SELECT id FROM queues);
+
+ALTER TABLE queue_events DROP CONSTRAINT IF EXISTS Queue_events_user_fk;
+ALTER TABLE queue_events ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE queue_events
+    ADD CONSTRAINT Queue_events_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE queue_events
+    ADD CONSTRAINT Queue_events_queue_fk FOREIGN KEY (queue_id) REFERENCES queues(id) ON DELETE CASCADE;
+
+ALTER TABLE queue_events DROP CONSTRAINT IF EXISTS Queue_events_tenant_fk;
+ALTER TABLE queue_events DROP COLUMN IF EXISTS queue_title;
+ALTER TABLE queue_events DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,25 @@
+-- История переходов участников сохраняется после удаления очереди или пользователя:
+-- событие хранит арендатора и название очереди, а ссылка на очередь не является внешним ключом
+ALTER TABLE queue_events ADD COLUMN IF NOT EXISTS tenant_id integer;
+ALTER TABLE queue_events ADD COLUMN IF NOT EXISTS queue_title varchar(255);
+
+UPDATE queue_events e SET tenant_id = q.tenant_id, queue_title = q.title FROM queues q WHERE q.id = e.queue_id;
+
+ALTER TABLE queue_events ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE queue_events ALTER COLUMN queue_title SET NOT NULL;
+
+-- Удаление арендатора удаляет и его историю
+ALTER TABLE queue_events
+    ADD CONSTRAINT Queue_events_tenant_fk FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+-- ID удаленной очереди остается в событиях, чтобы ее статистика не смешивалась с другими удаленными очередями
+ALTER TABLE queue_events DROP CONSTRAINT IF EXISTS Queue_events_queue_fk;
+
+-- События удаленного пользователя остаются обезличенными
+ALTER TABLE queue_events ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE queue_events DROP CONSTRAINT IF EXISTS Queue_events_user_fk;
+ALTER TABLE queue_events
+    ADD CONSTRAINT Queue_events_user_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Индекс для ускорения выборки событий арендатора за период
+CREATE INDEX IF NOT EXISTS queue_events_tenant_created_index ON queue_events (tenant_id, created_at);
@@ -0,0 +1,12 @@
+-- Таблица организационных единиц, допущенных в очереди по шаблону, вместе со всеми группами своего поддерева
+CREATE TABLE IF NOT EXISTS queue_template_org_units (
+    template_id integer NOT NULL, -- Идентификатор шаблона
+    org_unit_id integer NOT NULL, -- Идентификатор допущенной единицы
+    PRIMARY KEY (template_id, org_unit_id)
+);
+
+ALTER TABLE queue_template_org_units
+    ADD CONSTRAINT Queue_template_org_units_template_fk FOREIGN KEY (template_id) REFERENCES queue_templates(id) ON DELETE CASCADE;
+
+ALTER TABLE queue_template_org_units
+    ADD CONSTRAINT Queue_template_org_units_unit_fk FOREIGN KEY (org_unit_id) REFERENCES org_units(id) ON DELETE CASCADE;
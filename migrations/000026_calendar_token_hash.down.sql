@@ -0,0 +1,4 @@
+-- Токены по хешу не восстанавливаются, пользователи получат новые ссылки на ленты
+UPDATE users SET calendar_token_hash = NULL;
+
+ALTER TABLE users RENAME COLUMN calendar_token_hash TO calendar_token;
@@ -0,0 +1,5 @@
+-- В базе хранится только SHA-256 токена ленты календаря; выданные ранее ссылки продолжают работать
+ALTER TABLE users RENAME COLUMN calendar_token TO calendar_token_hash;
+
+UPDATE users SET calendar_token_hash = encode(sha256(convert_to(calendar_token_hash, 'UTF8')), 'hex')
+WHERE calendar_token_hash IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Удаленные очереди остаются в лентах календаря отмененными событиями, пока не выйдут за срок показа
+CREATE TABLE IF NOT EXISTS deleted_queues (
+    id integer PRIMARY KEY, -- Идентификатор удаленной очереди (UID события в календаре)
+    tenant_id integer NOT NULL, -- Идентификатор арендатора
+    title varchar(255) NOT NULL, -- Название очереди
+    time_start timestamp with time zone NOT NULL, -- Время начала приема
+    time_end timestamp with time zone NOT NULL, -- Время окончания приема
+    sequence integer NOT NULL, -- Номер ревизии отмененного события
+    allowed_groups integer[] NOT NULL DEFAULT '{}', -- Группы, допущенные в очередь
+    allowed_org_units integer[] NOT NULL DEFAULT '{}', -- Организационные единицы, допущенные в очередь
+    user_ids integer[] NOT NULL DEFAULT '{}', -- Участники и записавшиеся на момент удаления
+    deleted_at timestamp with time zone NOT NULL DEFAULT NOW() -- Время удаления очереди
+);
+
+-- Удаление арендатора удаляет и его удаленные очереди
+ALTER TABLE deleted_queues
+    ADD CONSTRAINT Deleted_queues_tenant_fk FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+-- Индекс для ускорения выборки удаленных очередей арендатора за срок показа
+CREATE INDEX IF NOT EXISTS deleted_queues_tenant_time_end_index ON deleted_queues (tenant_id, time_end);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS users_tenant_lower_tg_nick_unique;
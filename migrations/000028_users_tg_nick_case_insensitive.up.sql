@@ -0,0 +1,11 @@
+-- Ники ищутся без учета регистра, поэтому и уникальны они без учета регистра. Если ники различаются
+-- только регистром, миграция прерывается: такие аккаунты нужно сначала объединить или переименовать
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users GROUP BY tenant_id, LOWER(tg_nick) HAVING COUNT(*) > 1) THEN
+        RAISE EXCEPTION 'users with nicks differing only in case exist: rename them before migrating';
+    END IF;
+END $$;
+
+-- Индекс обеспечивает уникальность и ускоряет поиск пользователя по нику
+CREATE UNIQUE INDEX IF NOT EXISTS users_tenant_lower_tg_nick_unique ON users (tenant_id, LOWER(tg_nick));
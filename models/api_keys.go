@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Области доступа API-ключей
+const (
+	APIKeyScopeRead  = "read"  // Только чтение (GET-запросы)
+	APIKeyScopeWrite = "write" // Любые запросы от имени владельца
+	APIKeyScopeAdmin = "admin" // Права администратора (только для ключей администраторов)
+)
+
+// APIKeyScopes перечисляет допустимые области доступа API-ключей
+var APIKeyScopes = []string{APIKeyScopeRead, APIKeyScopeWrite, APIKeyScopeAdmin}
+
+// APIKey представляет персональный API-ключ, соответствует таблице "ApiKeys" в БД
+type APIKey struct {
+	ID          int            `db:"id" json:"id"`                     // Уникальный идентификатор ключа
+	UserID      int            `db:"user_id" json:"user_id"`           // Владелец ключа
+	Name        string         `db:"name" json:"name"`                 // Название ключа
+	Prefix      string         `db:"prefix" json:"prefix"`             // Открытая часть ключа
+	KeyHash     string         `db:"key_hash" json:"-"`                // SHA-256 ключа (не возвращается в JSON)
+	Scopes      pq.StringArray `db:"scopes" json:"scopes"`             // Области доступа
+	ExpiresAt   *time.Time     `db:"expires_at" json:"expires_at"`     // Время истечения (nil - бессрочный)
+	LastUsedAt  *time.Time     `db:"last_used_at" json:"last_used_at"` // Время последнего использования
+	RevokedAt   *time.Time     `db:"revoked_at" json:"revoked_at"`     // Время отзыва
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`     // Время создания
+	OwnerAdmin  bool           `db:"owner_is_admin" json:"-"`          // Является ли владелец администратором
+	OwnerStatus string         `db:"owner_status" json:"-"`            // Статус владельца
+	OwnerTenant int            `db:"owner_tenant_id" json:"-"`         // Арендатор владельца
+}
+
+// CreateAPIKeyRequest представляет запрос на создание API-ключа
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"` // Название ключа (обязательное поле)
+	Scopes    []string   `json:"scopes"`                  // Области доступа (по умолчанию read)
+	ExpiresAt *time.Time `json:"expires_at"`              // Время истечения (не задано - бессрочный)
+}
+
+// CreatedAPIKey представляет только что созданный ключ; сам ключ показывается один раз
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"` // Полный ключ для заголовка Authorization
+}
+
+// APIKeyIdentity представляет пользователя и права, с которыми выполняется запрос по API-ключу
+type APIKeyIdentity struct {
+	UserID   int      // ID владельца ключа
+	IsAdmin  bool     // Права администратора (владелец - администратор и у ключа есть область admin)
+	Scopes   []string // Области доступа ключа
+	TenantID int      // Арендатор владельца ключа
+}
+
+// HasScope проверяет, выдана ли ключу область доступа
+func (k APIKeyIdentity) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
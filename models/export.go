@@ -0,0 +1,23 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Статусы участника в выгрузке очереди
+const (
+	ExportStatusWaiting = "waiting" // Участник ожидает в живой очереди
+	ExportStatusBooked  = "booked"  // Участник записан на слот
+)
+
+// ParticipantExportRow представляет строку выгрузки участников очереди
+type ParticipantExportRow struct {
+	Username  string       `db:"username" json:"username"`     // Имя пользователя
+	TgNick    string       `db:"tg_nick" json:"tg_nick"`       // Telegram никнейм
+	GroupCode string       `db:"group_code" json:"group_code"` // Код группы
+	Position  int          `db:"position" json:"position"`     // Позиция в очереди или номер слота
+	Status    string       `db:"status" json:"status"`         // Статус: waiting, booked, served или no_show
+	JoinedAt  time.Time    `db:"joined_at" json:"joined_at"`   // Время присоединения или записи
+	ServedAt  sql.NullTime `db:"served_at" json:"served_at"`   // Время приема или фиксации неявки
+}
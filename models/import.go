@@ -0,0 +1,32 @@
+package models
+
+// Статусы строк импорта пользователей
+const (
+	ImportStatusNew     = "new"     // Пользователь будет создан (пробный запуск)
+	ImportStatusCreated = "created" // Пользователь создан
+	ImportStatusExists  = "exists"  // Пользователь с таким ником уже есть и не изменяется
+	ImportStatusError   = "error"   // Строка содержит ошибку
+)
+
+// ImportUserRow представляет строку файла импорта пользователей и результат ее обработки
+type ImportUserRow struct {
+	Line            int    `json:"line"`                       // Номер строки в файле
+	Username        string `json:"username"`                   // Имя пользователя
+	TgNick          string `json:"tg_nick"`                    // Telegram никнейм
+	Group           string `json:"group"`                      // Код группы
+	Status          string `json:"status"`                     // Результат обработки строки
+	Error           string `json:"error,omitempty"`            // Описание ошибки в строке
+	UserID          int    `json:"user_id,omitempty"`          // ID созданного пользователя
+	ActivationToken string `json:"activation_token,omitempty"` // Одноразовый токен для установки пароля через POST /auth/password-reset
+	TokenHash       string `json:"-"`                          // SHA-256 токена активации
+}
+
+// ImportUsersResult представляет итог импорта пользователей
+type ImportUsersResult struct {
+	DryRun        bool            `json:"dry_run"`        // Пробный запуск без изменений в БД
+	Valid         bool            `json:"valid"`          // Все строки прошли проверку
+	Created       int             `json:"created"`        // Число созданных (при пробном запуске - создаваемых) пользователей
+	Existing      int             `json:"existing"`       // Число уже существующих пользователей
+	GroupsCreated []string        `json:"groups_created"` // Коды созданных (создаваемых) групп
+	Rows          []ImportUserRow `json:"rows"`           // Результаты по строкам
+}
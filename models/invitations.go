@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// RegistrationConfig содержит правила регистрации новых пользователей
+type RegistrationConfig struct {
+	OpenRegistration bool          // Разрешить регистрацию по коду группы без приглашения
+	InvitationTTL    time.Duration // Время жизни приглашения по умолчанию
+	RequireApproval  bool          // Доступ после регистрации только после подтверждения модератором группы
+}
+
+// Invitation представляет приглашение для регистрации в группе, соответствует таблице "Invitations" в БД
+type Invitation struct {
+	ID        int        `db:"id" json:"id"`                 // Уникальный идентификатор приглашения
+	GroupID   int        `db:"group_id" json:"group_id"`     // Группа, в которую регистрируется приглашенный
+	CreatedBy *int       `db:"created_by" json:"created_by"` // Пользователь, выдавший приглашение
+	MaxUses   int        `db:"max_uses" json:"max_uses"`     // Максимальное число регистраций
+	Uses      int        `db:"uses" json:"uses"`             // Число регистраций по приглашению
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"` // Время истечения
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at"` // Время отзыва
+	CreatedAt time.Time  `db:"created_at" json:"created_at"` // Время создания
+}
+
+// CreateInvitationRequest представляет запрос на выдачу приглашения
+type CreateInvitationRequest struct {
+	MaxUses   int        `json:"max_uses"`   // Максимальное число регистраций (по умолчанию 1)
+	ExpiresAt *time.Time `json:"expires_at"` // Время истечения (по умолчанию registration.invitation_ttl)
+}
+
+// CreatedInvitation представляет только что выданное приглашение; код показывается один раз
+type CreatedInvitation struct {
+	Invitation
+	Code string `json:"code"` // Код приглашения для регистрации
+}
@@ -4,12 +4,23 @@ package models
 import (
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // Config представляет конфигурацию приложения
 type Config struct {
-	Port string   // Порт для запуска HTTP сервера
-	DB   DBConfig // Конфигурация базы данных
+	Port         string             // Порт для запуска HTTP сервера
+	DB           DBConfig           // Конфигурация базы данных
+	Fairness     FairnessConfig     // Правила честного присоединения к очередям
+	Calendar     CalendarConfig     // Параметры лент iCalendar
+	Telegram     TelegramConfig     // Параметры Telegram бота
+	Outbox       OutboxConfig       // Параметры доставки сообщений outbox
+	Webhooks     WebhookConfig      // Параметры webhook-подписок
+	Password     PasswordConfig     // Правила паролей и их сброса
+	SignIn       SignInConfig       // Защита входа от перебора паролей
+	TwoFactor    TwoFactorConfig    // Двухфакторная аутентификация
+	Registration RegistrationConfig // Правила регистрации пользователей
 }
 
 // DBConfig содержит параметры подключения к базе данных PostgreSQL
@@ -22,6 +33,67 @@ type DBConfig struct {
 	SSLMode  string // Режим SSL подключения
 }
 
+// FairnessConfig содержит правила присоединения к очередям; нулевое значение отключает правило
+type FairnessConfig struct {
+	MaxActiveQueues int           // Максимальное число очередей, в которых пользователь состоит одновременно
+	RejoinCooldown  time.Duration // Пауза после выхода из очереди перед повторным присоединением к ней
+	DailyJoinLimit  int           // Максимальное число присоединений к очередям за сутки
+}
+
+// CalendarConfig содержит параметры формирования лент iCalendar
+type CalendarConfig struct {
+	PublicURL string        // Внешний адрес API для ссылок на ленты календаря
+	TimeZone  string        // Часовой пояс для отображения событий в календаре
+	Lookback  time.Duration // Насколько давно закончившиеся очереди остаются в персональной ленте
+}
+
+// TelegramConfig содержит параметры Telegram бота; пустой токен отключает бота и уведомления
+type TelegramConfig struct {
+	BotToken    string        // Токен бота
+	BotName     string        // Имя бота без @ для ссылок t.me
+	APIURL      string        // Адрес Bot API (по умолчанию api.telegram.org)
+	PollTimeout time.Duration // Время ожидания обновлений при long polling
+	LinkCodeTTL time.Duration // Время жизни кода привязки чата
+	LoginMaxAge time.Duration // Максимальная давность данных Telegram Login Widget (по умолчанию 24 часа)
+}
+
+// OutboxConfig содержит параметры фоновой доставки сообщений outbox
+type OutboxConfig struct {
+	PollInterval time.Duration // Интервал опроса таблицы outbox
+	BatchSize    int           // Число сообщений, выбираемых за один опрос
+	MaxAttempts  int           // Число попыток доставки до перевода в dead-letter
+	BaseBackoff  time.Duration // Задержка перед первой повторной попыткой
+	MaxBackoff   time.Duration // Максимальная задержка между попытками
+	Retention    time.Duration // Срок хранения доставленных сообщений
+}
+
+// WebhookConfig содержит параметры доставки событий на webhook-подписки
+type WebhookConfig struct {
+	AllowPrivateNetworks bool // Разрешить адреса loopback и частных сетей (только для разработки и тестов)
+}
+
+// PasswordConfig содержит требования к паролям и параметры сброса пароля
+type PasswordConfig struct {
+	MinLength         int           // Минимальная длина нового пароля
+	ResetTokenTTL     time.Duration // Время жизни токена сброса пароля
+	ResetCodeTTL      time.Duration // Время жизни кода сброса, отправленного в Telegram
+	ResetCodeAttempts int           // Число попыток ввода кода сброса
+	ResetCodeInterval time.Duration // Минимальный интервал между отправками кода
+	ActivationTTL     time.Duration // Время жизни токена активации импортированного пользователя
+}
+
+// SignInConfig содержит ограничения частоты попыток входа и параметры блокировки аккаунта;
+// нулевое значение отключает соответствующее ограничение
+type SignInConfig struct {
+	IPPerMinute      int           // Попыток входа в минуту с одного IP-адреса
+	IPBurst          int           // Попыток входа подряд с одного IP-адреса
+	AccountPerMinute int           // Попыток входа в минуту в один аккаунт
+	AccountBurst     int           // Попыток входа подряд в один аккаунт
+	MaxFailures      int           // Неудачных попыток подряд до блокировки аккаунта
+	LockoutDuration  time.Duration // Длительность блокировки аккаунта
+	RateLimitStore   string        // Хранилище ограничений: memory (по умолчанию) или postgres
+}
+
 // CreateGroupRequest представляет запрос на создание новой группы
 type CreateGroupRequest struct {
 	Code    string `json:"code" binding:"required"` // Код группы (обязательное поле)
@@ -30,35 +102,117 @@ type CreateGroupRequest struct {
 
 // Group представляет группу студентов, соответствует таблице "Groups" в БД
 type Group struct {
-	ID      int            `db:"id" json:"id"`                        // Уникальный идентификатор группы
-	Code    string         `db:"code" json:"code" binding:"required"` // Код группы (например, "ИУ7-12Б")
-	Comment sql.NullString `db:"comment" json:"comment"`              // Описание группы (может быть NULL)
+	ID        int            `db:"id" json:"id"`                        // Уникальный идентификатор группы
+	Code      string         `db:"code" json:"code" binding:"required"` // Код группы (например, "ИУ7-12Б")
+	Comment   sql.NullString `db:"comment" json:"comment"`              // Описание группы (может быть NULL)
+	OrgUnitID *int           `db:"org_unit_id" json:"org_unit_id"`      // Организационная единица группы (nil - вне дерева)
 }
 
 // RegisterUser представляет данные для регистрации нового пользователя
 type RegisterUser struct {
-	Username string `json:"username"` // Имя пользователя
-	Password string `json:"password"` // Пароль пользователя
-	TgNick   string `json:"tg_nick"`  // Telegram никнейм
-	Group    string `json:"group"`    // Код группы пользователя
+	Username   string `json:"username"`   // Имя пользователя
+	Password   string `json:"password"`   // Пароль пользователя
+	TgNick     string `json:"tg_nick"`    // Telegram никнейм
+	Group      string `json:"group"`      // Код группы пользователя (только при registration.open_registration)
+	Invitation string `json:"invitation"` // Код приглашения; группа пользователя берется из приглашения
+	Status     string `json:"-"`          // Статус создаваемого пользователя (задается сервисом)
 }
 
 // User представляет пользователя системы, соответствует таблице "Users" в БД
 type User struct {
-	ID           int    `db:"id" json:"id"`             // Уникальный идентификатор пользователя
-	Username     string `db:"username" json:"username"` // Имя пользователя
-	TgNick       string `db:"tg_nick" json:"tg_nick"`   // Telegram никнейм
-	GroupID      int    `db:"group_id" json:"group_id"` // ID группы пользователя
-	PasswordHash string `db:"password_hash" json:"-"`   // Хеш пароля (не возвращается в JSON)
-	IsAdmin      bool   `db:"is_admin" json:"is_admin"` // Флаг администратора
+	ID           int           `db:"id" json:"id"`               // Уникальный идентификатор пользователя
+	Username     string        `db:"username" json:"username"`   // Имя пользователя
+	TgNick       string        `db:"tg_nick" json:"tg_nick"`     // Telegram никнейм
+	GroupIDs     pq.Int64Array `db:"group_ids" json:"group_ids"` // ID групп пользователя (nil при обновлении - группы не меняются)
+	PasswordHash string        `db:"password_hash" json:"-"`     // Хеш пароля (не возвращается в JSON)
+	IsAdmin      bool          `db:"is_admin" json:"is_admin"`   // Флаг администратора
+	Status       string        `db:"status" json:"status"`       // Статус: pending - ждет подтверждения, active - подтвержден
+	TenantID     int           `db:"tenant_id" json:"tenant_id"` // Арендатор пользователя
+}
+
+// InGroup сообщает, состоит ли пользователь в группе
+func (u User) InGroup(groupID int) bool {
+	for _, id := range u.GroupIDs {
+		if int(id) == groupID {
+			return true
+		}
+	}
+	return false
+}
+
+// Роли пользователя в группе
+const (
+	GroupRoleMember    = "member"    // Участник группы
+	GroupRoleModerator = "moderator" // Модератор: выдает приглашения и подтверждает регистрации
+)
+
+// GroupMember представляет участника группы вместе с его ролью в ней
+type GroupMember struct {
+	User
+	Role     string    `db:"role" json:"role"`           // Роль в группе: member или moderator
+	JoinedAt time.Time `db:"joined_at" json:"joined_at"` // Время вступления в группу
+}
+
+// GroupTransferRequest представляет запрос на перевод участника в другую группу
+type GroupTransferRequest struct {
+	ToGroupID int `json:"to_group_id" binding:"required"` // Группа, в которую переводится участник
+}
+
+// GroupMembershipChange описывает итог удаления или перевода участника группы
+type GroupMembershipChange struct {
+	UserID     int   `json:"user_id"`     // Участник группы
+	LeftQueues []int `json:"left_queues"` // Очереди, из которых участник выведен, так как больше не допущен в них
 }
 
+// Статусы пользователей
+const (
+	UserStatusPending = "pending" // Зарегистрировался сам и ждет подтверждения модератором группы
+	UserStatusActive  = "active"  // Подтвержден и имеет доступ к API
+)
+
+// Режимы работы очереди
+const (
+	QueueModeFIFO  = "fifo"  // Живая очередь: участники обслуживаются в порядке присоединения
+	QueueModeSlots = "slots" // Запись на временные слоты фиксированной длины
+)
+
 // Queue представляет очередь на консультацию, соответствует таблице "Queues" в БД
 type Queue struct {
-	ID        int       `db:"id" json:"id"`                          // Уникальный идентификатор очереди
-	Title     string    `db:"title" json:"title" binding:"required"` // Название очереди
-	TimeStart time.Time `db:"time_start" json:"time_start"`          // Время начала приема
-	TimeEnd   time.Time `db:"time_end" json:"time_end"`              // Время окончания приема
+	ID           int       `db:"id" json:"id"`                          // Уникальный идентификатор очереди
+	Title        string    `db:"title" json:"title" binding:"required"` // Название очереди
+	TimeStart    time.Time `db:"time_start" json:"time_start"`          // Время начала приема
+	TimeEnd      time.Time `db:"time_end" json:"time_end"`              // Время окончания приема
+	Mode         string    `db:"mode" json:"mode"`                      // Режим работы очереди (fifo или slots)
+	SlotDuration int       `db:"slot_duration" json:"slot_duration"`    // Длина слота в минутах (для режима slots)
+
+	Capacity      int           `db:"capacity" json:"capacity"`                   // Максимальное число активных участников (0 - без ограничения)
+	Desks         int           `db:"desks" json:"desks"`                         // Количество столов приема
+	AllowedGroups pq.Int64Array `db:"allowed_groups" json:"allowed_groups"`       // ID допущенных групп (пустой список - очередь открыта для всех)
+	AllowedUnits  pq.Int64Array `db:"allowed_org_units" json:"allowed_org_units"` // ID допущенных организационных единиц вместе с их поддеревом
+	ClosedAt      *time.Time    `db:"closed_at" json:"closed_at,omitempty"`       // Время закрытия очереди для новых участников
+	Sequence      int           `db:"sequence" json:"-"`                          // Номер ревизии очереди, увеличивается при каждом изменении
+	UpdatedAt     time.Time     `db:"updated_at" json:"updated_at"`               // Время последнего изменения очереди
+}
+
+// DeletedQueue содержит удаленную очередь, которая остается в лентах календаря отмененным событием
+type DeletedQueue struct {
+	Queue
+	UserIDs pq.Int64Array `db:"user_ids"` // Участники и записавшиеся на момент удаления
+}
+
+// SlotCount возвращает число слотов в интервале приема очереди; неполный хвост интервала отбрасывается
+func (q Queue) SlotCount() int {
+	duration := time.Duration(q.SlotDuration) * time.Minute
+	if duration <= 0 {
+		return 0
+	}
+	return int(q.TimeEnd.Sub(q.TimeStart) / duration)
+}
+
+// KeepsSlots сообщает, что после изменения очереди на updated слоты с 1 по lastSlot остаются на прежнем времени
+func (q Queue) KeepsSlots(updated Queue, lastSlot int) bool {
+	return updated.Mode == q.Mode && updated.TimeStart.Equal(q.TimeStart) &&
+		updated.SlotDuration == q.SlotDuration && updated.SlotCount() >= lastSlot
 }
 
 // AuthUser представляет данные для аутентификации пользователя
@@ -77,11 +231,26 @@ type QueueParticipant struct {
 	IsActive bool      `db:"is_active" json:"is_active"` // Флаг активности участника
 }
 
+// JoinStats содержит сведения об участии пользователя в очередях для проверки правил присоединения
+type JoinStats struct {
+	ActiveQueues int          `db:"active_queues"` // Число очередей, в которых пользователь сейчас состоит
+	JoinsSince   int          `db:"joins_since"`   // Число присоединений начиная с заданного момента
+	LastLeftAt   sql.NullTime `db:"last_left_at"`  // Время последнего выхода из проверяемой очереди
+}
+
 // CreateQueueRequest представляет запрос на создание новой очереди
 type CreateQueueRequest struct {
 	Title     string    `json:"title" binding:"required"`      // Название очереди (обязательное поле)
 	TimeStart time.Time `json:"time_start" binding:"required"` // Время начала приема (обязательное поле)
 	TimeEnd   time.Time `json:"time_end" binding:"required"`   // Время окончания приема (обязательное поле)
+
+	Mode         string `json:"mode"`          // Режим работы очереди (по умолчанию fifo)
+	SlotDuration int    `json:"slot_duration"` // Длина слота в минутах (обязательна для режима slots)
+
+	Capacity      int     `json:"capacity"`          // Максимальное число активных участников (0 - без ограничения)
+	Desks         int     `json:"desks"`             // Количество столов приема (по умолчанию 1)
+	AllowedGroups []int64 `json:"allowed_groups"`    // ID допущенных групп (пустой список - очередь открыта для всех)
+	AllowedUnits  []int64 `json:"allowed_org_units"` // ID допущенных факультетов и кафедр вместе с их поддеревом
 }
 
 // JoinQueueRequest представляет запрос на присоединение к очереди
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Типы организационных единиц
+const (
+	OrgUnitFaculty    = "faculty"    // Факультет
+	OrgUnitDepartment = "department" // Кафедра
+)
+
+// OrgUnit представляет организационную единицу (факультет или кафедру), соответствует таблице "OrgUnits" в БД
+type OrgUnit struct {
+	ID        int       `db:"id" json:"id"`                 // Уникальный идентификатор единицы
+	ParentID  *int      `db:"parent_id" json:"parent_id"`   // Родительская единица (nil - корень дерева)
+	Kind      string    `db:"kind" json:"kind"`             // Тип единицы: faculty или department
+	Code      string    `db:"code" json:"code"`             // Код единицы (например, "ИУ7")
+	Title     string    `db:"title" json:"title"`           // Полное название
+	CreatedAt time.Time `db:"created_at" json:"created_at"` // Время создания
+}
+
+// OrgUnitRequest представляет запрос на создание или изменение организационной единицы
+type OrgUnitRequest struct {
+	ParentID *int   `json:"parent_id"`               // Родительская единица (не задана - корень дерева)
+	Kind     string `json:"kind" binding:"required"` // Тип единицы: faculty или department
+	Code     string `json:"code" binding:"required"` // Код единицы
+	Title    string `json:"title"`                   // Полное название
+}
+
+// OrgUnitDetails представляет организационную единицу вместе с прямыми потомками
+type OrgUnitDetails struct {
+	OrgUnit
+	Children []OrgUnit `json:"children"` // Дочерние единицы
+	Groups   []Group   `json:"groups"`   // Группы, входящие непосредственно в единицу
+}
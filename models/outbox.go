@@ -0,0 +1,60 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Типы событий, публикуемых через outbox
+const (
+	OutboxQueueCreated        = "queue.created"        // Создана очередь
+	OutboxQueueCancelled      = "queue.cancelled"      // Очередь отменена (удалена); по сообщению на каждого затронутого пользователя
+	OutboxQueueClosed         = "queue.closed"         // Очередь закрыта для новых участников
+	OutboxParticipantJoined   = "participant.joined"   // Участник присоединился к очереди
+	OutboxParticipantLeft     = "participant.left"     // Участник вышел из очереди
+	OutboxParticipantServed   = "participant.served"   // Участник принят
+	OutboxParticipantNoShow   = "participant.no_show"  // Участник не явился
+	OutboxParticipantCalled   = "participant.called"   // Участник вызван (стал первым после сдвига очереди)
+	OutboxParticipantUpcoming = "participant.upcoming" // Участник стал третьим в очереди
+)
+
+// Состояния доставки сообщения outbox
+const (
+	OutboxStatusPending   = "pending"   // Ожидает доставки
+	OutboxStatusDelivered = "delivered" // Доставлено всем получателям
+	OutboxStatusDead      = "dead"      // Исчерпаны попытки доставки
+)
+
+// OutboxPayload содержит данные события; заполняются только поля, относящиеся к типу события
+type OutboxPayload struct {
+	QueueID     int       `json:"queue_id"`               // ID очереди
+	QueueTitle  string    `json:"queue_title"`            // Название очереди
+	TimeStart   time.Time `json:"time_start,omitempty"`   // Время начала приема
+	UserID      int       `json:"user_id,omitempty"`      // ID участника (для queue.cancelled - затронутого пользователя)
+	Position    int       `json:"position,omitempty"`     // Позиция участника
+	WaitSeconds int       `json:"wait_seconds,omitempty"` // Время ожидания в секундах (для served и no_show)
+}
+
+// OutboxMessage представляет сообщение outbox, соответствует таблице "OutboxMessages" в БД
+type OutboxMessage struct {
+	ID             int64           `db:"id" json:"id"`                           // Уникальный идентификатор сообщения
+	EventType      string          `db:"event_type" json:"event_type"`           // Тип события
+	Payload        json.RawMessage `db:"payload" json:"payload"`                 // Данные события в JSON
+	Status         string          `db:"status" json:"status"`                   // Состояние доставки
+	DeliveredSinks pq.StringArray  `db:"delivered_sinks" json:"delivered_sinks"` // Получатели, которым сообщение уже доставлено
+	Attempts       int             `db:"attempts" json:"attempts"`               // Число неудачных попыток
+	NextAttemptAt  time.Time       `db:"next_attempt_at" json:"next_attempt_at"` // Время следующей попытки
+	LastError      sql.NullString  `db:"last_error" json:"last_error"`           // Последняя ошибка доставки
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`           // Время создания
+	TenantID       int             `db:"tenant_id" json:"tenant_id"`             // Арендатор, в очереди которого произошло событие
+}
+
+// DecodePayload разбирает данные события
+func (m OutboxMessage) DecodePayload() (OutboxPayload, error) {
+	var payload OutboxPayload
+	err := json.Unmarshal(m.Payload, &payload)
+	return payload, err
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ChangePasswordRequest представляет запрос на смену пароля текущим пользователем
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"` // Текущий пароль
+	NewPassword string `json:"new_password" binding:"required"` // Новый пароль
+}
+
+// PasswordResetToken представляет одноразовый токен для сброса пароля.
+// Клиент передает его в POST /auth/password-reset вместе с новым паролем
+type PasswordResetToken struct {
+	Token     string    `json:"token"`      // Одноразовый токен сброса
+	ExpiresAt time.Time `json:"expires_at"` // Время истечения токена
+}
+
+// ResetPasswordRequest представляет запрос на установку нового пароля по токену сброса
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`        // Одноразовый токен сброса
+	NewPassword string `json:"new_password" binding:"required"` // Новый пароль
+}
+
+// ForgotPasswordRequest представляет запрос на отправку кода сброса пароля в Telegram
+type ForgotPasswordRequest struct {
+	TgNick string `json:"tg_nick" binding:"required"` // Telegram никнейм пользователя
+}
+
+// ResetPasswordByCodeRequest представляет запрос на установку нового пароля по коду из Telegram
+type ResetPasswordByCodeRequest struct {
+	TgNick      string `json:"tg_nick" binding:"required"`      // Telegram никнейм пользователя
+	Code        string `json:"code" binding:"required"`         // Код из сообщения бота
+	NewPassword string `json:"new_password" binding:"required"` // Новый пароль
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Session представляет сессию пользователя, соответствует таблице "Sessions" в БД
+type Session struct {
+	ID         int        `db:"id" json:"id"`                     // Уникальный идентификатор сессии
+	UserID     int        `db:"user_id" json:"user_id"`           // Пользователь, вошедший в систему
+	UserAgent  string     `db:"user_agent" json:"user_agent"`     // User-Agent клиента при входе
+	IP         string     `db:"ip" json:"ip"`                     // IP-адрес клиента при входе
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`     // Время входа
+	LastSeenAt time.Time  `db:"last_seen_at" json:"last_seen_at"` // Время последнего запроса
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`     // Время истечения токена
+	RevokedAt  *time.Time `db:"revoked_at" json:"-"`              // Время отзыва
+	Current    bool       `db:"-" json:"current"`                 // Является ли сессия текущей для запроса
+	UserStatus string     `db:"user_status" json:"-"`             // Статус пользователя (заполняется при проверке токена)
+}
+
+// ClientInfo представляет сведения о клиенте, выполняющем вход
+type ClientInfo struct {
+	UserAgent string // Значение заголовка User-Agent
+	IP        string // IP-адрес клиента
+}
+
+// TokenIdentity представляет данные, извлеченные из JWT токена доступа
+type TokenIdentity struct {
+	UserID    int  // ID пользователя
+	IsAdmin   bool // Флаг администратора
+	SessionID int  // ID сессии, которой выдан токен
+	TenantID  int  // Арендатор пользователя
+}
+
+// SignOutResult представляет итог принудительного выхода пользователя со всех устройств
+type SignOutResult struct {
+	Sessions         int  `json:"sessions"`          // Количество отозванных сессий
+	APIKeys          int  `json:"api_keys"`          // Количество отозванных API-ключей
+	TelegramUnlinked bool `json:"telegram_unlinked"` // Был ли отвязан чат Telegram бота
+}
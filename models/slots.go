@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSlotBookingsExist возвращается при изменении расписания слотов очереди, на которые уже записаны пользователи
+var ErrSlotBookingsExist = errors.New("queue has slot bookings: cancel them before changing the slot schedule")
+
+// QueueSlot представляет временной слот очереди в режиме записи
+type QueueSlot struct {
+	Number    int       `json:"number"`             // Номер слота (начиная с 1)
+	TimeStart time.Time `json:"time_start"`         // Время начала слота
+	TimeEnd   time.Time `json:"time_end"`           // Время окончания слота
+	IsFree    bool      `json:"is_free"`            // Флаг, что слот свободен
+	IsMine    bool      `json:"is_mine"`            // Флаг, что слот занят текущим пользователем
+	UserID    int       `json:"user_id,omitempty"`  // ID записавшегося пользователя (только в расписании для ведущего)
+	Username  string    `json:"username,omitempty"` // Имя записавшегося пользователя (только в расписании для ведущего)
+	TgNick    string    `json:"tg_nick,omitempty"`  // Telegram никнейм записавшегося пользователя (только в расписании для ведущего)
+}
+
+// SlotBooking представляет запись пользователя на слот, соответствует таблице "QueueSlotBookings" в БД
+type SlotBooking struct {
+	ID         int       `db:"id" json:"id"`                   // Уникальный идентификатор записи
+	QueueID    int       `db:"queue_id" json:"queue_id"`       // ID очереди
+	UserID     int       `db:"user_id" json:"user_id"`         // ID пользователя
+	SlotNumber int       `db:"slot_number" json:"slot_number"` // Номер слота
+	BookedAt   time.Time `db:"booked_at" json:"booked_at"`     // Время записи или последнего переноса
+	Username   string    `db:"username" json:"username"`       // Имя пользователя
+	TgNick     string    `db:"tg_nick" json:"tg_nick"`         // Telegram никнейм пользователя
+}
+
+// BookSlotRequest представляет запрос на запись или перенос записи на слот
+type BookSlotRequest struct {
+	Slot int `json:"slot" binding:"required"` // Номер слота (обязательное поле)
+}
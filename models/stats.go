@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Типы событий участников очередей
+const (
+	EventJoined      = "joined"      // Присоединение к живой очереди
+	EventLeft        = "left"        // Выход из живой очереди
+	EventServed      = "served"      // Участник принят
+	EventNoShow      = "no_show"     // Участник не явился, когда подошла очередь
+	EventBooked      = "booked"      // Запись на слот
+	EventRescheduled = "rescheduled" // Перенос записи на другой слот
+	EventCancelled   = "cancelled"   // Отмена записи на слот
+)
+
+// StatsFilter задает выборку событий для статистики
+type StatsFilter struct {
+	QueueID   int       // ID очереди (0 - все очереди)
+	GroupID   int       // ID группы участников (0 - все группы)
+	OrgUnitID int       // ID факультета или кафедры участников вместе с поддеревом (0 - все)
+	From      time.Time // Начало периода (нулевое значение - без ограничения)
+	To        time.Time // Конец периода (нулевое значение - без ограничения)
+}
+
+// QueueStats содержит статистику участия в очереди или в наборе очередей
+type QueueStats struct {
+	QueueID        int     `db:"queue_id" json:"queue_id,omitempty"`       // ID очереди (пусто для сводной статистики)
+	Title          string  `db:"title" json:"title,omitempty"`             // Название очереди
+	Joined         int     `db:"joined" json:"joined"`                     // Число присоединений и записей на слоты
+	Left           int     `db:"left_count" json:"left"`                   // Число выходов из очереди и отмен записей
+	Served         int     `db:"served" json:"served"`                     // Число принятых участников
+	NoShow         int     `db:"no_show" json:"no_show"`                   // Число неявок
+	NoShowRate     float64 `db:"-" json:"no_show_rate"`                    // Доля неявок среди вызванных участников
+	AvgWaitSeconds float64 `db:"avg_wait_seconds" json:"avg_wait_seconds"` // Среднее время ожидания принятых участников
+}
+
+// GroupAttendance содержит статистику посещения очередей группой
+type GroupAttendance struct {
+	GroupID int    `db:"group_id" json:"group_id"` // ID группы
+	Code    string `db:"code" json:"code"`         // Код группы
+	Users   int    `db:"users" json:"users"`       // Число разных пользователей группы, пришедших в очереди
+	Served  int    `db:"served" json:"served"`     // Число принятых участников группы
+	NoShow  int    `db:"no_show" json:"no_show"`   // Число неявок участников группы
+}
+
+// StatsReport содержит сводную статистику, разбивку по очередям и посещаемость групп
+type StatsReport struct {
+	Total  QueueStats        `json:"total"`  // Сводная статистика
+	Queues []QueueStats      `json:"queues"` // Статистика по очередям
+	Groups []GroupAttendance `json:"groups"` // Посещаемость по группам (по убыванию числа принятых)
+}
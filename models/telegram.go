@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrTelegramChatLinkedElsewhere возвращается при привязке чата, уже привязанного к пользователю другого арендатора
+var ErrTelegramChatLinkedElsewhere = errors.New("telegram chat is linked to a user of another organization: unlink it there first")
+
+// TelegramLink представляет ссылку для привязки чата Telegram к пользователю
+type TelegramLink struct {
+	Code      string    `json:"code"`       // Одноразовый код привязки
+	URL       string    `json:"url"`        // Ссылка t.me/<бот>?start=<код>
+	ExpiresAt time.Time `json:"expires_at"` // Время истечения кода
+}
+
+// TelegramLoginRequest представляет данные Telegram Login Widget
+type TelegramLoginRequest struct {
+	ID         int64  `json:"id" binding:"required"`        // ID пользователя Telegram
+	FirstName  string `json:"first_name"`                   // Имя
+	LastName   string `json:"last_name"`                    // Фамилия
+	Username   string `json:"username"`                     // Имя пользователя Telegram без @
+	PhotoURL   string `json:"photo_url"`                    // Ссылка на аватар
+	AuthDate   int64  `json:"auth_date" binding:"required"` // Время авторизации (Unix)
+	Hash       string `json:"hash" binding:"required"`      // Подпись данных
+	Group      string `json:"group"`                        // Код группы (при первом входе, только при registration.open_registration)
+	Invitation string `json:"invitation"`                   // Код приглашения (нужен только при первом входе нового пользователя)
+}
+
+// CheckFields возвращает подписанные поля виджета для формирования строки проверки
+func (r TelegramLoginRequest) CheckFields() map[string]string {
+	return map[string]string{
+		"id":         strconv.FormatInt(r.ID, 10),
+		"first_name": r.FirstName,
+		"last_name":  r.LastName,
+		"username":   r.Username,
+		"photo_url":  r.PhotoURL,
+		"auth_date":  strconv.FormatInt(r.AuthDate, 10),
+	}
+}
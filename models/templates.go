@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// QueueTemplate представляет шаблон очереди, соответствует таблице "QueueTemplates" в БД
+type QueueTemplate struct {
+	ID            int           `db:"id" json:"id"`                               // Уникальный идентификатор шаблона
+	Title         string        `db:"title" json:"title"`                         // Шаблон названия очереди (поддерживает {date} и {n})
+	Duration      int           `db:"duration" json:"duration"`                   // Длительность приема в минутах
+	Mode          string        `db:"mode" json:"mode"`                           // Режим работы очереди
+	SlotDuration  int           `db:"slot_duration" json:"slot_duration"`         // Длина слота в минутах (для режима slots)
+	Capacity      int           `db:"capacity" json:"capacity"`                   // Максимальное число активных участников
+	Desks         int           `db:"desks" json:"desks"`                         // Количество столов приема
+	AllowedGroups pq.Int64Array `db:"allowed_groups" json:"allowed_groups"`       // ID допущенных групп
+	AllowedUnits  pq.Int64Array `db:"allowed_org_units" json:"allowed_org_units"` // ID допущенных организационных единиц вместе с их поддеревом
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`               // Время создания шаблона
+}
+
+// CreateQueueTemplateRequest представляет запрос на создание шаблона очереди
+type CreateQueueTemplateRequest struct {
+	Title         string  `json:"title" binding:"required"`    // Шаблон названия очереди (обязательное поле)
+	Duration      int     `json:"duration" binding:"required"` // Длительность приема в минутах (обязательное поле)
+	Mode          string  `json:"mode"`                        // Режим работы очереди (по умолчанию fifo)
+	SlotDuration  int     `json:"slot_duration"`               // Длина слота в минутах (обязательна для режима slots)
+	Capacity      int     `json:"capacity"`                    // Максимальное число активных участников
+	Desks         int     `json:"desks"`                       // Количество столов приема (по умолчанию 1)
+	AllowedGroups []int64 `json:"allowed_groups"`              // ID допущенных групп
+	AllowedUnits  []int64 `json:"allowed_org_units"`           // ID допущенных факультетов и кафедр вместе с их поддеревом
+}
+
+// ScheduleRequest описывает время одной или серии создаваемых очередей
+type ScheduleRequest struct {
+	TimeStart  time.Time `json:"time_start" binding:"required"` // Время начала первой очереди (обязательное поле)
+	TimeEnd    time.Time `json:"time_end"`                      // Время окончания первой очереди (по умолчанию - длительность исходной очереди)
+	Title      string    `json:"title"`                         // Шаблон названия (по умолчанию - название исходной очереди или шаблона)
+	RepeatDays int       `json:"repeat_days"`                   // Период повторения в днях (0 - одна очередь)
+	Until      time.Time `json:"until"`                         // Время, после которого очереди серии не создаются
+}
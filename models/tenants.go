@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// DefaultTenantCode - код основного арендатора; он выбирается, если заголовок X-Tenant не передан,
+// а его администраторы управляют остальными арендаторами
+const DefaultTenantCode = "default"
+
+// Tenant представляет арендатора (университет или факультет), соответствует таблице "Tenants" в БД
+type Tenant struct {
+	ID        int       `db:"id" json:"id"`                 // Уникальный идентификатор арендатора
+	Code      string    `db:"code" json:"code"`             // Код арендатора для заголовка X-Tenant
+	Title     string    `db:"title" json:"title"`           // Название арендатора
+	CreatedAt time.Time `db:"created_at" json:"created_at"` // Время создания
+}
+
+// CreateTenantRequest представляет запрос на создание арендатора вместе с его первым администратором
+type CreateTenantRequest struct {
+	Code  string       `json:"code" binding:"required"`  // Код арендатора (обязательное поле)
+	Title string       `json:"title" binding:"required"` // Название арендатора (обязательное поле)
+	Admin RegisterUser `json:"admin"`                    // Первый администратор арендатора
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// TwoFactorConfig содержит параметры двухфакторной аутентификации
+type TwoFactorConfig struct {
+	Issuer            string        // Название сервиса в приложении-аутентификаторе
+	RequiredForAdmins bool          // Права администратора выдаются только после подключения 2FA
+	PreAuthTTL        time.Duration // Время жизни токена первого шага входа
+}
+
+// TwoFactorState представляет настройки TOTP пользователя
+type TwoFactorState struct {
+	Secret   sql.NullString `db:"totp_secret"`    // Секрет TOTP в base32
+	Enabled  bool           `db:"totp_enabled"`   // Подтверждено ли подключение
+	LastStep sql.NullInt64  `db:"totp_last_step"` // Последний использованный временной шаг
+}
+
+// TwoFactorSetup представляет данные для подключения приложения-аутентификатора
+type TwoFactorSetup struct {
+	Secret string `json:"secret"` // Секрет в base32 для ручного ввода
+	URI    string `json:"uri"`    // Ссылка otpauth:// для QR-кода
+}
+
+// TwoFactorCodeRequest представляет запрос с кодом из приложения или резервным кодом
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"` // Код TOTP или резервный код
+}
+
+// TwoFactorSignInRequest представляет второй шаг входа
+type TwoFactorSignInRequest struct {
+	PreAuthToken string `json:"pre_auth_token" binding:"required"` // Токен, выданный на первом шаге
+	Code         string `json:"code" binding:"required"`           // Код TOTP или резервный код
+}
+
+// SignInResult представляет результат входа: JWT токен либо требование второго шага
+type SignInResult struct {
+	Token                  string `json:"token,omitempty"`                     // JWT токен
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`       // Нужно ввести код на втором шаге
+	PreAuthToken           string `json:"pre_auth_token,omitempty"`            // Короткоживущий токен для второго шага
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"` // Права администратора появятся после подключения 2FA
+}
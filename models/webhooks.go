@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WebhookEventTypes перечисляет типы событий, на которые можно подписать webhook
+var WebhookEventTypes = []string{
+	OutboxQueueCreated,
+	OutboxParticipantJoined,
+	OutboxParticipantServed,
+	OutboxQueueClosed,
+}
+
+// Webhook представляет подписку внешней системы на события, соответствует таблице "Webhooks" в БД
+type Webhook struct {
+	ID         int            `db:"id" json:"id"`                   // Уникальный идентификатор подписки
+	URL        string         `db:"url" json:"url"`                 // Адрес получателя
+	Secret     string         `db:"secret" json:"-"`                // Секрет для подписи (возвращается только при создании)
+	EventTypes pq.StringArray `db:"event_types" json:"event_types"` // Типы событий подписки
+	IsActive   bool           `db:"is_active" json:"is_active"`     // Флаг активности подписки
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`   // Время создания
+}
+
+// CreateWebhookRequest представляет запрос на создание webhook-подписки
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`         // Адрес получателя (обязательное поле)
+	Secret     string   `json:"secret"`                         // Секрет для подписи (генерируется, если не задан)
+	EventTypes []string `json:"event_types" binding:"required"` // Типы событий (обязательное поле)
+}
+
+// WebhookDelivery представляет попытку доставки события, соответствует таблице "WebhookDeliveries" в БД
+type WebhookDelivery struct {
+	ID              int64          `db:"id" json:"id"`                               // Уникальный идентификатор попытки
+	WebhookID       int            `db:"webhook_id" json:"webhook_id"`               // ID подписки
+	OutboxMessageID int64          `db:"outbox_message_id" json:"outbox_message_id"` // ID доставляемого сообщения outbox
+	EventType       string         `db:"event_type" json:"event_type"`               // Тип события
+	StatusCode      sql.NullInt32  `db:"status_code" json:"status_code"`             // HTTP статус ответа
+	Success         bool           `db:"success" json:"success"`                     // Флаг успешной доставки
+	Error           sql.NullString `db:"error" json:"error"`                         // Ошибка доставки
+	DurationMs      int            `db:"duration_ms" json:"duration_ms"`             // Длительность запроса в миллисекундах
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`               // Время попытки
+}
+
+// WebhookPayload представляет тело запроса, отправляемого на webhook
+type WebhookPayload struct {
+	ID        int64           `json:"id"`         // ID события (совпадает при повторной доставке)
+	Event     string          `json:"event"`      // Тип события
+	CreatedAt time.Time       `json:"created_at"` // Время события
+	Data      json.RawMessage `json:"data"`       // Данные события
+}
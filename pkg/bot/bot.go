@@ -0,0 +1,138 @@
+// Package bot содержит обработку входящих сообщений Telegram бота
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sso/models"
+	"sso/pkg/services"
+	"sso/pkg/telegram"
+	"strings"
+	"time"
+)
+
+// Параметры опроса Bot API
+const (
+	defaultPollTimeout = 30 * time.Second // Время ожидания обновлений, если оно не задано
+	retryDelay         = 5 * time.Second  // Пауза перед повторным опросом после ошибки
+)
+
+// Bot получает обновления методом long polling и отвечает на команды пользователей
+type Bot struct {
+	client      *telegram.Client       // Клиент Bot API
+	service     services.Authorization // Сервис с бизнес-логикой
+	pollTimeout time.Duration          // Время ожидания обновлений
+	location    *time.Location         // Часовой пояс для времени очередей в ответах
+}
+
+// NewBot создает бота поверх клиента Bot API и сервиса приложения
+func NewBot(client *telegram.Client, service services.Authorization, pollTimeout time.Duration, location *time.Location) *Bot {
+	if pollTimeout <= 0 {
+		pollTimeout = defaultPollTimeout
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &Bot{
+		client:      client,
+		service:     service,
+		pollTimeout: pollTimeout,
+		location:    location,
+	}
+}
+
+// Run опрашивает Bot API до отмены контекста
+func (b *Bot) Run(ctx context.Context) {
+	offset := 0
+	for {
+		updates, err := b.client.GetUpdates(ctx, offset, b.pollTimeout)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("telegram bot: %s", err.Error())
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message != nil {
+				b.handleMessage(update.Message)
+			}
+		}
+	}
+}
+
+// handleMessage разбирает команду из сообщения и отправляет ответ
+func (b *Bot) handleMessage(message *telegram.Message) {
+	command, args := parseCommand(message.Text)
+	if command == "" {
+		return
+	}
+
+	// Чат привязывается к одному пользователю, поэтому в группе его командами могли бы пользоваться все участники
+	var reply string
+	if message.Chat.Type != telegram.ChatTypePrivate {
+		reply = "Бот работает только в личном чате: откройте ссылку привязки из профиля в приложении"
+	} else if command == "/start" {
+		reply = b.start(message.Chat.ID, args)
+	} else {
+		reply = b.execute(message.Chat.ID, command, args)
+	}
+
+	if err := b.client.SendMessage(message.Chat.ID, reply); err != nil {
+		log.Printf("telegram bot: %s", err.Error())
+	}
+}
+
+// start привязывает чат к пользователю по коду из ссылки t.me/<бот>?start=<код>
+func (b *Bot) start(chatID int64, args []string) string {
+	if len(args) == 0 {
+		return "Чтобы получать уведомления об очередях, откройте ссылку привязки из профиля в приложении"
+	}
+
+	user, err := b.service.LinkTelegramChat(args[0], chatID)
+	if errors.Is(err, models.ErrTelegramChatLinkedElsewhere) {
+		return "Этот чат уже привязан к аккаунту в другой организации: сначала отвяжите его в профиле того аккаунта"
+	}
+	if err != nil {
+		return "Ссылка недействительна или устарела, получите новую в профиле"
+	}
+	return fmt.Sprintf("Чат привязан к пользователю %s, уведомления об очередях будут приходить сюда\n\n%s", user.Username, helpText)
+}
+
+// execute выполняет команду от имени пользователя, привязавшего чат
+func (b *Bot) execute(chatID int64, command string, args []string) string {
+	handler, ok := commands[command]
+	if !ok {
+		return "Неизвестная команда\n\n" + helpText
+	}
+
+	user, err := b.service.GetUserByTelegramChat(chatID)
+	if err != nil {
+		return "Чат не привязан к пользователю: откройте ссылку привязки из профиля в приложении"
+	}
+
+	// Команда выполняется в данных арендатора, к пользователю которого привязан чат
+	tenantBot := *b
+	tenantBot.service = b.service.ForTenant(user.TenantID)
+	return handler(&tenantBot, user, args)
+}
+
+// parseCommand выделяет команду без имени бота и ее аргументы
+func parseCommand(text string) (string, []string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil
+	}
+
+	command, _, _ := strings.Cut(fields[0], "@")
+	return strings.ToLower(command), fields[1:]
+}
@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"fmt"
+	"sso/models"
+	"strconv"
+	"strings"
+)
+
+// helpText перечисляет команды бота
+const helpText = `Команды:
+/queues - доступные очереди
+/join <id> - встать в очередь
+/leave <id> - выйти из очереди
+/position <id> - моя позиция в очереди
+/next <id> - вызвать следующего (для ведущих)`
+
+// commandHandler выполняет команду от имени привязанного пользователя и возвращает ответ
+type commandHandler func(b *Bot, user models.User, args []string) string
+
+// commands сопоставляет команды бота и их обработчики
+var commands = map[string]commandHandler{
+	"/help":     (*Bot).help,
+	"/queues":   (*Bot).queues,
+	"/join":     (*Bot).join,
+	"/leave":    (*Bot).leave,
+	"/position": (*Bot).position,
+	"/next":     (*Bot).next,
+}
+
+// help возвращает список команд
+func (b *Bot) help(user models.User, args []string) string {
+	return helpText
+}
+
+// queues возвращает незавершенные очереди, доступные группе пользователя
+func (b *Bot) queues(user models.User, args []string) string {
+	queues, err := b.service.GetAvailableQueues(user.ID)
+	if err != nil {
+		return "Не удалось получить список очередей"
+	}
+	if len(queues) == 0 {
+		return "Доступных очередей нет"
+	}
+
+	var reply strings.Builder
+	reply.WriteString("Доступные очереди:")
+	for i := len(queues) - 1; i >= 0; i-- {
+		queue := queues[i]
+		fmt.Fprintf(&reply, "\n%d. %s, %s–%s", queue.ID, queue.Title,
+			queue.TimeStart.In(b.location).Format("02.01 15:04"), queue.TimeEnd.In(b.location).Format("15:04"))
+		if queue.Mode == models.QueueModeSlots {
+			reply.WriteString(" (запись на слоты в приложении)")
+		}
+	}
+	return reply.String()
+}
+
+// join ставит пользователя в очередь
+func (b *Bot) join(user models.User, args []string) string {
+	queueID, err := queueArg(args)
+	if err != nil {
+		return err.Error()
+	}
+
+	if _, err := b.service.JoinQueue(queueID, user.ID); err != nil {
+		return "Не удалось встать в очередь: " + err.Error()
+	}
+	position, err := b.service.GetQueuePosition(queueID, user.ID)
+	if err != nil {
+		return "Вы в очереди"
+	}
+	return fmt.Sprintf("Вы в очереди, ваша позиция: %d", position)
+}
+
+// leave убирает пользователя из очереди
+func (b *Bot) leave(user models.User, args []string) string {
+	queueID, err := queueArg(args)
+	if err != nil {
+		return err.Error()
+	}
+
+	if err := b.service.LeaveQueue(queueID, user.ID); err != nil {
+		return "Не удалось выйти из очереди: " + err.Error()
+	}
+	return "Вы вышли из очереди"
+}
+
+// position сообщает позицию пользователя в очереди
+func (b *Bot) position(user models.User, args []string) string {
+	queueID, err := queueArg(args)
+	if err != nil {
+		return err.Error()
+	}
+
+	position, err := b.service.GetQueuePosition(queueID, user.ID)
+	if err != nil {
+		return "Вы не состоите в этой очереди"
+	}
+	return fmt.Sprintf("Ваша позиция: %d", position)
+}
+
+// next вызывает следующего участника очереди (только для ведущих)
+func (b *Bot) next(user models.User, args []string) string {
+	if !user.IsAdmin {
+		return "Команда доступна только ведущим"
+	}
+	queueID, err := queueArg(args)
+	if err != nil {
+		return err.Error()
+	}
+
+	if err := b.service.ShiftQueue(queueID, models.EventServed); err != nil {
+		return "Не удалось сдвинуть очередь: " + err.Error()
+	}
+
+	participants, err := b.service.GetQueueParticipants(queueID)
+	if err != nil || len(participants) == 0 {
+		return "Очередь сдвинута, больше никого нет"
+	}
+	return fmt.Sprintf("Очередь сдвинута, осталось участников: %d", len(participants))
+}
+
+// queueArg разбирает ID очереди из аргументов команды
+func queueArg(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("Укажите номер очереди, например /join 42")
+	}
+	queueID, err := strconv.Atoi(args[0])
+	if err != nil || queueID <= 0 {
+		return 0, fmt.Errorf("Номер очереди должен быть положительным числом")
+	}
+	return queueID, nil
+}
@@ -4,6 +4,7 @@ package config
 import (
 	"log"
 	"sso/models"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -26,6 +27,62 @@ func LoadConfig() models.Config {
 			DBName:   viper.GetString("db.dbname"),   // Имя базы данных
 			SSLMode:  viper.GetString("db.sslmode"),  // Режим SSL подключения
 		},
+		Fairness: models.FairnessConfig{
+			MaxActiveQueues: viper.GetInt("fairness.max_active_queues"),    // Лимит одновременных очередей
+			RejoinCooldown:  viper.GetDuration("fairness.rejoin_cooldown"), // Пауза перед повторным присоединением
+			DailyJoinLimit:  viper.GetInt("fairness.daily_join_limit"),     // Лимит присоединений за сутки
+		},
+		Calendar: models.CalendarConfig{
+			PublicURL: viper.GetString("calendar.public_url"), // Внешний адрес API
+			TimeZone:  viper.GetString("calendar.time_zone"),  // Часовой пояс событий
+			Lookback:  viper.GetDuration("calendar.lookback"), // Срок показа прошедших очередей
+		},
+		Telegram: models.TelegramConfig{
+			BotToken:    viper.GetString("telegram.bot_token"),       // Токен бота
+			BotName:     viper.GetString("telegram.bot_name"),        // Имя бота
+			APIURL:      viper.GetString("telegram.api_url"),         // Адрес Bot API
+			PollTimeout: viper.GetDuration("telegram.poll_timeout"),  // Время ожидания обновлений
+			LinkCodeTTL: viper.GetDuration("telegram.link_code_ttl"), // Время жизни кода привязки
+			LoginMaxAge: viper.GetDuration("telegram.login_max_age"), // Давность данных входа через Telegram
+		},
+		Outbox: models.OutboxConfig{
+			PollInterval: viper.GetDuration("outbox.poll_interval"), // Интервал опроса outbox
+			BatchSize:    viper.GetInt("outbox.batch_size"),         // Размер пачки сообщений
+			MaxAttempts:  viper.GetInt("outbox.max_attempts"),       // Попыток до dead-letter
+			BaseBackoff:  viper.GetDuration("outbox.base_backoff"),  // Начальная задержка повтора
+			MaxBackoff:   viper.GetDuration("outbox.max_backoff"),   // Максимальная задержка повтора
+			Retention:    viper.GetDuration("outbox.retention"),     // Срок хранения доставленных сообщений
+		},
+		Webhooks: models.WebhookConfig{
+			AllowPrivateNetworks: viper.GetBool("webhooks.allow_private_networks"), // Доставка на внутренние адреса
+		},
+		Password: models.PasswordConfig{
+			MinLength:         viper.GetInt("password.min_length"),               // Минимальная длина пароля
+			ResetTokenTTL:     viper.GetDuration("password.reset_token_ttl"),     // Время жизни токена сброса
+			ResetCodeTTL:      viper.GetDuration("password.reset_code_ttl"),      // Время жизни кода сброса в Telegram
+			ResetCodeAttempts: viper.GetInt("password.reset_code_attempts"),      // Попыток ввода кода сброса
+			ResetCodeInterval: viper.GetDuration("password.reset_code_interval"), // Интервал между отправками кода
+			ActivationTTL:     viper.GetDuration("password.activation_ttl"),      // Время жизни токена активации
+		},
+		SignIn: models.SignInConfig{
+			IPPerMinute:      viper.GetInt("sign_in.ip_per_minute"),         // Попыток входа в минуту с IP
+			IPBurst:          viper.GetInt("sign_in.ip_burst"),              // Попыток входа подряд с IP
+			AccountPerMinute: viper.GetInt("sign_in.account_per_minute"),    // Попыток входа в минуту в аккаунт
+			AccountBurst:     viper.GetInt("sign_in.account_burst"),         // Попыток входа подряд в аккаунт
+			MaxFailures:      viper.GetInt("sign_in.max_failures"),          // Неудачных попыток до блокировки
+			LockoutDuration:  viper.GetDuration("sign_in.lockout_duration"), // Длительность блокировки
+			RateLimitStore:   viper.GetString("sign_in.rate_limit_store"),   // Хранилище ограничений
+		},
+		TwoFactor: models.TwoFactorConfig{
+			Issuer:            viper.GetString("two_factor.issuer"),            // Название сервиса в приложении-аутентификаторе
+			RequiredForAdmins: viper.GetBool("two_factor.required_for_admins"), // Обязательная 2FA для администраторов
+			PreAuthTTL:        viper.GetDuration("two_factor.pre_auth_ttl"),    // Время жизни токена первого шага
+		},
+		Registration: models.RegistrationConfig{
+			OpenRegistration: viper.GetBool("registration.open_registration"),  // Регистрация без приглашения
+			InvitationTTL:    viper.GetDuration("registration.invitation_ttl"), // Время жизни приглашения по умолчанию
+			RequireApproval:  viper.GetBool("registration.require_approval"),   // Подтверждение регистрации модератором группы
+		},
 	}
 
 	log.Println("Config loaded")
@@ -38,6 +95,11 @@ func initConfig() error {
 	viper.AddConfigPath("configs")
 	// Устанавливаем имя конфигурационного файла (без расширения)
 	viper.SetConfigName("config")
+	// Любой параметр можно переопределить переменной окружения: registration.open_registration -
+	// SSO_REGISTRATION_OPEN_REGISTRATION
+	viper.SetEnvPrefix("sso")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
 	// Читаем конфигурационный файл
 	return viper.ReadInConfig()
 }
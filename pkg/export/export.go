@@ -0,0 +1,109 @@
+// Package export содержит выгрузку участников очереди в CSV и XLSX
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"sso/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Форматы выгрузки и соответствующие им MIME-типы
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+
+	ContentTypeCSV  = "text/csv; charset=utf-8"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// timeLayout задает формат времени в выгрузке
+const timeLayout = "2006-01-02 15:04:05"
+
+// formulaPrefixes содержит символы, с которых табличные редакторы начинают формулу
+const formulaPrefixes = "=+-@\t\r"
+
+// header содержит заголовки столбцов выгрузки
+var header = []string{"username", "tg_nick", "group", "position", "status", "joined_at", "served_at"}
+
+// WriteCSV записывает участников очереди в формате CSV
+func WriteCSV(w io.Writer, rows []models.ParticipantExportRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(record(row)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteXLSX записывает участников очереди в формате XLSX
+func WriteXLSX(w io.Writer, rows []models.ParticipantExportRow) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := file.GetSheetName(0)
+	if err := file.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+
+		// Позицию записываем числом, чтобы по ней можно было сортировать в таблице
+		values := []interface{}{row.Username, row.TgNick, row.GroupCode, row.Position, row.Status,
+			formatTime(row.JoinedAt), ""}
+		if row.ServedAt.Valid {
+			values[6] = formatTime(row.ServedAt.Time)
+		}
+		if err := file.SetSheetRow(sheet, cell, &values); err != nil {
+			return err
+		}
+	}
+
+	return file.Write(w)
+}
+
+// record преобразует строку выгрузки в поля CSV
+func record(row models.ParticipantExportRow) []string {
+	servedAt := ""
+	if row.ServedAt.Valid {
+		servedAt = formatTime(row.ServedAt.Time)
+	}
+
+	return []string{
+		escapeCell(row.Username),
+		escapeCell(row.TgNick),
+		escapeCell(row.GroupCode),
+		strconv.Itoa(row.Position),
+		row.Status,
+		formatTime(row.JoinedAt),
+		servedAt,
+	}
+}
+
+// escapeCell экранирует ячейку CSV, которую табличный редактор принял бы за формулу; имена и ники задают
+// сами пользователи, поэтому без экранирования они могли бы выполнить формулу в таблице преподавателя
+func escapeCell(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// formatTime форматирует время в локальной зоне сервера
+func formatTime(t time.Time) string {
+	return t.Local().Format(timeLayout)
+}
@@ -0,0 +1,151 @@
+// Package handler содержит HTTP обработчики персональных API-ключей
+package handler
+
+import (
+	"net/http"
+	"sso/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyIdentity проверяет API-ключ и сохраняет владельца и его права в контексте
+func (h *Handler) apiKeyIdentity(c *gin.Context, key string) {
+	identity, err := h.service.AuthenticateAPIKey(key)
+	if err != nil {
+		if abortPendingApproval(c, err) {
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Ключ без области write допускает только чтение
+	if !identity.HasScope(models.APIKeyScopeWrite) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api key does not have write scope"})
+			return
+		}
+	}
+
+	c.Set(userCtx, identity.UserID)
+	c.Set(userIsAdmin, identity.IsAdmin)
+	c.Set(tenantCtx, identity.TenantID)
+	c.Set(apiKeyCtx, true)
+	c.Next()
+}
+
+// sessionOnly middleware запрещает действия с учетными данными при входе по API-ключу
+func (h *Handler) sessionOnly(c *gin.Context) {
+	if _, ok := c.Get(apiKeyCtx); ok {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "this action is not allowed with an api key"})
+		return
+	}
+	c.Next()
+}
+
+// createAPIKey создает API-ключ текущего пользователя; ключ возвращается только в этом ответе
+func (h *Handler) createAPIKey(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+	isAdmin, _ := c.Get(userIsAdmin)
+	admin, _ := isAdmin.(bool)
+
+	var input models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := h.tenantService(c).CreateAPIKey(userId.(int), admin, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_key": key})
+}
+
+// getAPIKeys возвращает API-ключи текущего пользователя
+func (h *Handler) getAPIKeys(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	keys, err := h.tenantService(c).GetAPIKeys(userId.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// revokeAPIKey отзывает API-ключ текущего пользователя
+func (h *Handler) revokeAPIKey(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid api key id"})
+		return
+	}
+
+	if err := h.tenantService(c).RevokeAPIKey(userId.(int), id, false); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "api key revoked successfully"})
+}
+
+// getAllAPIKeys возвращает API-ключи всех пользователей (только для админов)
+func (h *Handler) getAllAPIKeys(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	keys, err := h.tenantService(c).GetAllAPIKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// revokeAnyAPIKey отзывает API-ключ любого пользователя (только для админов)
+func (h *Handler) revokeAnyAPIKey(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+	userId, _ := c.Get(userCtx)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid api key id"})
+		return
+	}
+
+	if err := h.tenantService(c).RevokeAPIKey(userId.(int), id, true); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "api key revoked successfully"})
+}
@@ -0,0 +1,86 @@
+// Package handler содержит HTTP обработчики для лент iCalendar
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sso/pkg/services"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contentTypeCalendar задает MIME-тип ответа в формате iCalendar
+const contentTypeCalendar = "text/calendar; charset=utf-8"
+
+// getCalendarURL выдает ссылку на персональную ленту календаря пользователя; ссылка показывается только один раз
+func (h *Handler) getCalendarURL(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	feedURL, err := h.tenantService(c).GetCalendarURL(userId.(int))
+	if err != nil {
+		if errors.Is(err, services.ErrCalendarLinkIssued) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": feedURL})
+}
+
+// resetCalendarURL выдает новую ссылку на ленту календаря, отзывая прежнюю
+func (h *Handler) resetCalendarURL(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	feedURL, err := h.tenantService(c).ResetCalendarURL(userId.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": feedURL})
+}
+
+// getCalendarFeed отдает персональную ленту календаря по токену из ссылки (без JWT, для подписки из календарей)
+func (h *Handler) getCalendarFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	calendar, err := h.service.GetUserCalendar(token)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "calendar not found"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, contentTypeCalendar, []byte(calendar))
+}
+
+// getQueueCalendar отдает файл iCalendar с одной очередью
+func (h *Handler) getQueueCalendar(c *gin.Context) {
+	queueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
+		return
+	}
+
+	calendar, err := h.tenantService(c).GetQueueCalendar(queueID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "queue not found"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("queue_%d.ics", queueID)))
+	c.Data(http.StatusOK, contentTypeCalendar, []byte(calendar))
+}
@@ -0,0 +1,118 @@
+// Package handler содержит HTTP обработчики участников групп
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sso/models"
+	"sso/pkg/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getGroupMembers возвращает участников группы с их ролями
+func (h *Handler) getGroupMembers(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+
+	members, err := h.tenantService(c).GetGroupMembers(userId, isAdmin, groupID)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupModeratorRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// addGroupMember добавляет пользователя в группу
+func (h *Handler) addGroupMember(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	groupID, memberID, ok := groupAndUserIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(c).AddGroupMember(userId, isAdmin, groupID, memberID); err != nil {
+		if errors.Is(err, services.ErrGroupModeratorRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member added successfully"})
+}
+
+// removeGroupMember исключает пользователя из группы
+func (h *Handler) removeGroupMember(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	groupID, memberID, ok := groupAndUserIDs(c)
+	if !ok {
+		return
+	}
+
+	change, err := h.tenantService(c).RemoveGroupMember(userId, isAdmin, groupID, memberID)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupModeratorRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed successfully", "change": change})
+}
+
+// transferGroupMember переводит пользователя в другую группу
+func (h *Handler) transferGroupMember(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	groupID, memberID, ok := groupAndUserIDs(c)
+	if !ok {
+		return
+	}
+
+	var input models.GroupTransferRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	change, err := h.tenantService(c).TransferGroupMember(userId, isAdmin, groupID, memberID, input)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupModeratorRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member transferred successfully", "change": change})
+}
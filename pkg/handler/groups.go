@@ -20,7 +20,7 @@ func (h *Handler) createGroup(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	id, err := h.service.CreateGroup(input.Code, input.Comment)
+	id, err := h.tenantService(c).CreateGroup(input.Code, input.Comment)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -30,7 +30,7 @@ func (h *Handler) createGroup(c *gin.Context) {
 
 // getAllGroups возвращает все группы
 func (h *Handler) getAllGroups(c *gin.Context) {
-	groups, err := h.service.GetAllGroups()
+	groups, err := h.tenantService(c).GetAllGroups()
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -45,7 +45,7 @@ func (h *Handler) getGroupByID(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
 		return
 	}
-	group, err := h.service.GetGroupByID(id)
+	group, err := h.tenantService(c).GetGroupByID(id)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -69,7 +69,7 @@ func (h *Handler) updateGroup(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	if err := h.service.UpdateGroup(id, input); err != nil {
+	if err := h.tenantService(c).UpdateGroup(id, input); err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -87,7 +87,7 @@ func (h *Handler) deleteGroup(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	if err := h.service.DeleteGroup(id); err != nil {
+	if err := h.tenantService(c).DeleteGroup(id); err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -2,10 +2,17 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
 	"sso/models"
+	"sso/pkg/ratelimit"
 	"sso/pkg/services"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,16 +22,23 @@ const (
 	authorizationHeader = "Authorization" // Название заголовка авторизации
 	userCtx             = "userId"        // Ключ для хранения ID пользователя в контексте
 	userIsAdmin         = "isAdmin"       // Ключ для хранения флага администратора в контексте
+	apiKeyHeader        = "X-API-Key"     // Альтернативный заголовок для передачи API-ключа
+	apiKeyCtx           = "apiKey"        // Ключ для хранения признака запроса по API-ключу в контексте
+	sessionCtx          = "sessionId"     // Ключ для хранения ID сессии в контексте
+	tenantHeader        = "X-Tenant"      // Заголовок с кодом арендатора для запросов без токена
+	tenantCtx           = "tenantId"      // Ключ для хранения ID арендатора в контексте
 )
 
 // Handler содержит сервисы для обработки HTTP запросов
 type Handler struct {
-	service services.Authorization // Сервис авторизации и работы с пользователями
+	service        services.Authorization // Сервис авторизации и работы с пользователями
+	ipLimiter      *ratelimit.Limiter     // Ограничение частоты входа с одного IP-адреса (nil - отключено)
+	accountLimiter *ratelimit.Limiter     // Ограничение частоты входа в один аккаунт (nil - отключено)
 }
 
-// NewHandler создает новый экземпляр обработчика с указанным сервисом авторизации
-func NewHandler(authService services.Authorization) *Handler {
-	return &Handler{service: authService}
+// NewHandler создает новый экземпляр обработчика с указанным сервисом авторизации и ограничениями частоты входа
+func NewHandler(authService services.Authorization, ipLimiter, accountLimiter *ratelimit.Limiter) *Handler {
+	return &Handler{service: authService, ipLimiter: ipLimiter, accountLimiter: accountLimiter}
 }
 
 // InitRoutes инициализирует и настраивает все маршруты API
@@ -37,25 +51,64 @@ func (h *Handler) InitRoutes() *gin.Engine {
 	})
 
 	// Группа маршрутов для аутентификации (не требует авторизации)
-	auth := router.Group("/auth")
+	auth := router.Group("/auth", h.tenantIdentity)
 	{
-		auth.POST("/sign-up", h.signUp) // Регистрация нового пользователя
-		auth.POST("/sign-in", h.signIn) // Вход в систему
+		auth.POST("/sign-up", h.signUp)                                        // Регистрация нового пользователя
+		auth.POST("/sign-in", h.signIn)                                        // Вход в систему
+		auth.POST("/2fa", h.signInTwoFactor)                                   // Второй шаг входа по коду двухфакторной аутентификации
+		auth.POST("/telegram", h.signInTelegram)                               // Вход через Telegram Login Widget
+		auth.POST("/password-reset", h.resetPassword)                          // Установка пароля по токену сброса
+		auth.POST("/password-reset/telegram", h.forgotPassword)                // Отправка кода сброса пароля в Telegram
+		auth.POST("/password-reset/telegram/confirm", h.resetPasswordWithCode) // Установка пароля по коду из Telegram
 	}
 
-	// Группа защищенных маршрутов (требует JWT токен)
+	// Персональная лента календаря доступна по секретному токену в ссылке
+	router.GET("/calendar/:token", h.getCalendarFeed)
+
+	// Группа защищенных маршрутов (требует JWT токен или API-ключ)
 	api := router.Group("/api", h.userIdentity)
 	{
 		// Маршруты для работы с пользователями
-		api.GET("/admin", h.isAdmin)          // Проверка статуса администратора
-		api.GET("/profile", h.getUserProfile) // Получение профиля пользователя
-		api.PUT("/profile", h.updateUser)     // Обновление профиля пользователя
+		api.GET("/admin", h.isAdmin)                                                      // Проверка статуса администратора
+		api.GET("/profile", h.getUserProfile)                                             // Получение профиля пользователя
+		api.PUT("/profile", h.updateUser)                                                 // Обновление профиля пользователя
+		api.PUT("/profile/password", h.sessionOnly, h.changePassword)                     // Смена пароля
+		api.POST("/profile/2fa/setup", h.sessionOnly, h.setupTwoFactor)                   // Выдача секрета TOTP
+		api.POST("/profile/2fa/confirm", h.sessionOnly, h.confirmTwoFactor)               // Подтверждение TOTP и выдача резервных кодов
+		api.POST("/profile/2fa/recovery-codes", h.sessionOnly, h.regenerateRecoveryCodes) // Новые резервные коды
+		api.DELETE("/profile/2fa", h.sessionOnly, h.disableTwoFactor)                     // Отключение TOTP
+		api.POST("/profile/api-keys", h.sessionOnly, h.createAPIKey)                      // Создание API-ключа
+		api.GET("/profile/api-keys", h.getAPIKeys)                                        // Список своих API-ключей
+		api.DELETE("/profile/api-keys/:id", h.sessionOnly, h.revokeAPIKey)                // Отзыв своего API-ключа
+		api.GET("/profile/sessions", h.getSessions)                                       // Активные сессии пользователя
+		api.DELETE("/profile/sessions/:id", h.sessionOnly, h.revokeSession)               // Выход из сессии
+		api.GET("/profile/calendar", h.getCalendarURL)                                    // Ссылка на ленту календаря
+		api.POST("/profile/calendar/reset", h.resetCalendarURL)                           // Выдача новой ссылки на ленту календаря
+		api.POST("/profile/telegram", h.createTelegramLink)                               // Ссылка для привязки чата Telegram
+		api.DELETE("/profile/telegram", h.unlinkTelegramChat)                             // Отвязка чата Telegram
+		api.POST("/profile/telegram/login", h.sessionOnly, h.linkTelegramLogin)           // Подключение входа через Telegram
 
 		// Маршруты только для администраторов
 		admin := api.Group("/admin")
 		{
-			admin.GET("/users", h.getUsers)          // Получение списка всех пользователей
-			admin.DELETE("/users/:id", h.deleteUser) // Удаление пользователя
+			admin.GET("/users", h.getUsers)                                          // Получение списка всех пользователей
+			admin.DELETE("/users/:id", h.deleteUser)                                 // Удаление пользователя
+			admin.POST("/users/import", h.importUsers)                               // Импорт студентов из CSV
+			admin.POST("/users/:id/password-reset", h.createPasswordReset)           // Ссылка для сброса пароля пользователя
+			admin.GET("/users/:id/sessions", h.getUserSessions)                      // Активные сессии пользователя
+			admin.DELETE("/users/:id/sessions", h.sessionOnly, h.revokeUserSessions) // Выход пользователя из всех сессий
+			admin.POST("/users/:id/unlock", h.unlockUser)                            // Снятие блокировки входа пользователя
+			admin.GET("/stats", h.getStats)                                          // Сводная статистика по очередям
+			admin.GET("/outbox", h.getOutboxMessages)                                // Сообщения outbox (по умолчанию dead-letter)
+			admin.POST("/outbox/:id/retry", h.retryOutboxMessage)                    // Повторная доставка сообщения из dead-letter
+			admin.POST("/webhooks", h.createWebhook)                                 // Создание webhook-подписки
+			admin.GET("/webhooks", h.getAllWebhooks)                                 // Получение всех webhook-подписок
+			admin.DELETE("/webhooks/:id", h.deleteWebhook)                           // Удаление webhook-подписки
+			admin.GET("/webhooks/:id/deliveries", h.getWebhookDeliveries)            // Журнал доставки webhook-подписки
+			admin.GET("/api-keys", h.getAllAPIKeys)                                  // Получение API-ключей всех пользователей
+			admin.DELETE("/api-keys/:id", h.sessionOnly, h.revokeAnyAPIKey)          // Отзыв любого API-ключа
+			admin.GET("/tenants", h.getTenants)                                      // Получение всех арендаторов (админ основного арендатора)
+			admin.POST("/tenants", h.createTenant)                                   // Создание арендатора (админ основного арендатора)
 		}
 
 		// Маршруты для работы с очередями
@@ -66,20 +119,67 @@ func (h *Handler) InitRoutes() *gin.Engine {
 			queues.GET("/:id", h.getQueue)                          // Получение очереди по ID
 			queues.PUT("/:id", h.updateQueue)                       // Обновление очереди (только админ)
 			queues.DELETE("/:id", h.deleteQueue)                    // Удаление очереди (только админ)
+			queues.POST("/:id/close", h.closeQueue)                 // Закрытие очереди для новых участников (только админ)
 			queues.POST("/:id/join", h.joinQueue)                   // Присоединение к очереди
 			queues.DELETE("/:id/leave", h.leaveQueue)               // Покидание очереди
 			queues.GET("/:id/participants", h.getQueueParticipants) // Получение участников очереди
 			queues.POST("/:id/shift", h.shiftQueue)                 // Сдвиг очереди (только админ)
+			queues.GET("/:id/slots", h.getQueueSlots)               // Получение слотов очереди
+			queues.GET("/:id/timetable", h.getQueueTimetable)       // Расписание слотов с записавшимися (только админ)
+			queues.POST("/:id/booking", h.bookSlot)                 // Запись на слот
+			queues.PUT("/:id/booking", h.rescheduleSlot)            // Перенос записи на другой слот
+			queues.DELETE("/:id/booking", h.cancelSlot)             // Отмена записи на слот
+			queues.POST("/:id/clone", h.cloneQueue)                 // Клонирование очереди на новое время (только админ)
+			queues.GET("/:id/stats", h.getQueueStats)               // Статистика участия в очереди (только админ)
+			queues.GET("/:id/calendar", h.getQueueCalendar)         // Файл iCalendar с очередью
+		}
+
+		// Маршруты для работы с шаблонами очередей (только админ)
+		templates := api.Group("/queue-templates")
+		{
+			templates.POST("/", h.createQueueTemplate)                // Создание шаблона
+			templates.GET("/", h.getAllQueueTemplates)                // Получение всех шаблонов
+			templates.GET("/:id", h.getQueueTemplate)                 // Получение шаблона по ID
+			templates.DELETE("/:id", h.deleteQueueTemplate)           // Удаление шаблона
+			templates.POST("/:id/queues", h.createQueuesFromTemplate) // Создание очередей по шаблону
 		}
 
 		// Маршруты для работы с группами
 		groups := api.Group("/groups")
 		{
-			groups.POST("/", h.createGroup)      // Создание группы (только админ)
-			groups.GET("/", h.getAllGroups)      // Получение всех групп
-			groups.GET("/:id", h.getGroupByID)   // Получение группы по ID
-			groups.PUT("/:id", h.updateGroup)    // Обновление группы (только админ)
-			groups.DELETE("/:id", h.deleteGroup) // Удаление группы (только админ)
+			groups.POST("/", h.createGroup)                                     // Создание группы (только админ)
+			groups.GET("/", h.getAllGroups)                                     // Получение всех групп
+			groups.GET("/:id", h.getGroupByID)                                  // Получение группы по ID
+			groups.PUT("/:id", h.updateGroup)                                   // Обновление группы (только админ)
+			groups.DELETE("/:id", h.deleteGroup)                                // Удаление группы (только админ)
+			groups.GET("/:id/moderators", h.getGroupModerators)                 // Модераторы группы
+			groups.PUT("/:id/moderators/:userId", h.addGroupModerator)          // Назначение модератора группы (только админ)
+			groups.DELETE("/:id/moderators/:userId", h.removeGroupModerator)    // Снятие модератора группы (только админ)
+			groups.GET("/:id/members", h.getGroupMembers)                       // Участники группы (модератор или админ)
+			groups.PUT("/:id/members/:userId", h.addGroupMember)                // Добавление участника группы (модератор или админ)
+			groups.DELETE("/:id/members/:userId", h.removeGroupMember)          // Исключение участника из группы (модератор или админ)
+			groups.POST("/:id/members/:userId/transfer", h.transferGroupMember) // Перевод участника в другую группу (модератор обеих групп или админ)
+			groups.POST("/:id/invitations", h.createInvitation)                 // Выдача приглашения в группу (модератор или админ)
+			groups.GET("/:id/invitations", h.getInvitations)                    // Приглашения группы (модератор или админ)
+			groups.DELETE("/:id/invitations/:invitationId", h.revokeInvitation) // Отзыв приглашения (модератор или админ)
+			groups.GET("/:id/pending-users", h.getPendingUsers)                 // Очередь неподтвержденных регистраций (модератор или админ)
+			groups.POST("/:id/pending-users/:userId/approve", h.approveUser)    // Подтверждение регистрации (модератор или админ)
+			groups.DELETE("/:id/pending-users/:userId", h.rejectUser)           // Отклонение регистрации (модератор или админ)
+		}
+
+		// Маршруты для работы с организационной структурой (факультеты, кафедры и их группы)
+		orgUnits := api.Group("/org-units")
+		{
+			orgUnits.POST("/", h.createOrgUnit)                           // Создание единицы (админ или администратор родительской единицы)
+			orgUnits.GET("/", h.getOrgUnits)                              // Получение всех единиц
+			orgUnits.GET("/:id", h.getOrgUnit)                            // Единица с дочерними единицами и группами
+			orgUnits.PUT("/:id", h.updateOrgUnit)                         // Изменение единицы (админ или администратор единицы)
+			orgUnits.DELETE("/:id", h.deleteOrgUnit)                      // Удаление пустой единицы (админ или администратор родительской единицы)
+			orgUnits.PUT("/:id/groups/:groupId", h.addOrgUnitGroup)       // Включение группы в единицу
+			orgUnits.DELETE("/:id/groups/:groupId", h.removeOrgUnitGroup) // Вывод группы из единицы
+			orgUnits.GET("/:id/admins", h.getOrgUnitAdmins)               // Администраторы единицы
+			orgUnits.PUT("/:id/admins/:userId", h.addOrgUnitAdmin)        // Назначение администратора единицы
+			orgUnits.DELETE("/:id/admins/:userId", h.removeOrgUnitAdmin)  // Снятие администратора единицы
 		}
 	}
 
@@ -106,16 +206,23 @@ func (h *Handler) signUp(c *gin.Context) {
 		return
 	}
 
-	// Проверяем, что все обязательные поля заполнены
-	if input.Username == "" || input.Password == "" || input.TgNick == "" || input.Group == "" {
+	// Проверяем, что все обязательные поля заполнены; группа задается приглашением или кодом группы
+	if input.Username == "" || input.Password == "" || input.TgNick == "" || (input.Invitation == "" && input.Group == "") {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "all fields are required"})
 		return
 	}
 
 	// Создаем пользователя через сервис
-	id, err := h.service.CreateUser(input)
+	id, err := h.tenantService(c).CreateUser(input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, services.ErrInvitationRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidInvitation):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -130,18 +237,65 @@ func (h *Handler) signIn(c *gin.Context) {
 		return
 	}
 
-	// Генерируем JWT токен для пользователя
-	token, err := h.service.NewToken(input)
+	// Ограничиваем частоту попыток входа с одного адреса и в один аккаунт; ники уникальны в пределах арендатора
+	accountKey := fmt.Sprintf("account:%d:%s", c.GetInt(tenantCtx), strings.ToLower(input.TgNick))
+	if !h.allowSignIn(c, "ip:"+c.ClientIP(), h.ipLimiter) || !h.allowSignIn(c, accountKey, h.accountLimiter) {
+		return
+	}
+
+	// Генерируем JWT токен для пользователя или токен второго шага входа
+	result, err := h.tenantService(c).SignIn(input, clientInfo(c))
 	if err != nil {
+		if abortPendingApproval(c, err) {
+			return
+		}
+		var policyErr *services.PolicyError
+		if errors.As(err, &policyErr) {
+			c.Header("Retry-After", retryAfterSeconds(policyErr.RetryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": policyErr.Message, "code": policyErr.Code})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, result)
+}
+
+// allowSignIn учитывает попытку входа по ключу; при превышении лимита отвечает 429 с заголовком Retry-After
+func (h *Handler) allowSignIn(c *gin.Context, key string, limiter *ratelimit.Limiter) bool {
+	allowed, retryAfter, err := limiter.Allow(key)
+	if err != nil {
+		// Недоступность хранилища ограничений не должна блокировать вход
+		log.Printf("sign-in rate limit: %v", err)
+		return true
+	}
+	if !allowed {
+		c.Header("Retry-After", retryAfterSeconds(retryAfter))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many sign-in attempts", "code": "rate_limited"})
+		return false
+	}
+	return true
+}
+
+// clientInfo возвращает сведения о клиенте для сохранения в сессии
+func clientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
 }
 
-// userIdentity middleware для проверки JWT токена и извлечения информации о пользователе
+// retryAfterSeconds форматирует задержку для заголовка Retry-After в целых секундах с округлением вверх
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// userIdentity middleware для проверки JWT токена или API-ключа и извлечения информации о пользователе
 func (h *Handler) userIdentity(c *gin.Context) {
+	// API-ключ можно передать отдельным заголовком
+	if key := c.GetHeader(apiKeyHeader); key != "" {
+		h.apiKeyIdentity(c, key)
+		return
+	}
+
 	// Получаем заголовок авторизации
 	header := c.GetHeader(authorizationHeader)
 	if header == "" {
@@ -162,15 +316,49 @@ func (h *Handler) userIdentity(c *gin.Context) {
 		return
 	}
 
+	// API-ключ отличается от JWT токена префиксом
+	if strings.HasPrefix(headerParts[1], services.APIKeyPrefix) {
+		h.apiKeyIdentity(c, headerParts[1])
+		return
+	}
+
 	// Парсим токен и извлекаем информацию о пользователе
-	userId, isAdmin, err := h.service.ParseToken(headerParts[1])
+	identity, err := h.service.ParseToken(headerParts[1])
 	if err != nil {
+		if abortPendingApproval(c, err) {
+			return
+		}
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Сохраняем ID пользователя и статус администратора в контексте для дальнейшего использования
-	c.Set(userCtx, userId)
-	c.Set(userIsAdmin, isAdmin)
+	// Сохраняем ID пользователя, статус администратора, ID сессии и арендатора в контексте для дальнейшего использования
+	c.Set(userCtx, identity.UserID)
+	c.Set(userIsAdmin, identity.IsAdmin)
+	c.Set(sessionCtx, identity.SessionID)
+	c.Set(tenantCtx, identity.TenantID)
+	c.Next()
+}
+
+// tenantIdentity middleware определяет арендатора по заголовку X-Tenant; без заголовка выбирается основной арендатор
+func (h *Handler) tenantIdentity(c *gin.Context) {
+	code := c.GetHeader(tenantHeader)
+	if code == "" {
+		code = models.DefaultTenantCode
+	}
+
+	tenant, err := h.service.GetTenantByCode(code)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Set(tenantCtx, tenant.ID)
 	c.Next()
 }
+
+// tenantService возвращает сервис, ограниченный арендатором запроса; у защищенных маршрутов арендатор берется
+// из токена или API-ключа, а не из заголовка
+func (h *Handler) tenantService(c *gin.Context) services.Authorization {
+	return h.service.ForTenant(c.GetInt(tenantCtx))
+}
@@ -0,0 +1,186 @@
+// Package handler содержит HTTP обработчики модераторов групп и приглашений
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sso/models"
+	"sso/pkg/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getGroupModerators возвращает модераторов группы
+func (h *Handler) getGroupModerators(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+
+	moderators, err := h.tenantService(c).GetGroupModerators(groupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"moderators": moderators})
+}
+
+// addGroupModerator назначает пользователя модератором группы (только для админов)
+func (h *Handler) addGroupModerator(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	groupID, userID, ok := groupAndUserIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(c).AddGroupModerator(groupID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "moderator added successfully"})
+}
+
+// removeGroupModerator снимает пользователя с роли модератора группы (только для админов)
+func (h *Handler) removeGroupModerator(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	groupID, userID, ok := groupAndUserIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(c).RemoveGroupModerator(groupID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "moderator removed successfully"})
+}
+
+// createInvitation выдает приглашение в группу; код возвращается только в этом ответе
+func (h *Handler) createInvitation(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+
+	var input models.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.tenantService(c).CreateInvitation(userId, isAdmin, groupID, input)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupModeratorRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitation": invitation})
+}
+
+// getInvitations возвращает приглашения группы
+func (h *Handler) getInvitations(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+
+	invitations, err := h.tenantService(c).GetInvitations(userId, isAdmin, groupID)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupModeratorRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// revokeInvitation отзывает приглашение группы
+func (h *Handler) revokeInvitation(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+	invitationID, err := strconv.Atoi(c.Param("invitationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation id"})
+		return
+	}
+
+	if err := h.tenantService(c).RevokeInvitation(userId, isAdmin, groupID, invitationID); err != nil {
+		if errors.Is(err, services.ErrGroupModeratorRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation revoked successfully"})
+}
+
+// currentUser возвращает ID и статус администратора текущего пользователя из контекста
+func currentUser(c *gin.Context) (int, bool, bool) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return 0, false, false
+	}
+	isAdmin, _ := c.Get(userIsAdmin)
+	admin, _ := isAdmin.(bool)
+	return userId.(int), admin, true
+}
+
+// groupAndUserIDs разбирает ID группы и пользователя из пути запроса
+func groupAndUserIDs(c *gin.Context) (int, int, bool) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return 0, 0, false
+	}
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return 0, 0, false
+	}
+	return groupID, userID, true
+}
@@ -0,0 +1,234 @@
+// Package handler содержит HTTP обработчики организационной структуры
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sso/models"
+	"sso/pkg/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createOrgUnit создает факультет или кафедру (админ или администратор родительской единицы)
+func (h *Handler) createOrgUnit(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input models.OrgUnitRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := h.tenantService(c).CreateOrgUnit(userId, isAdmin, input)
+	if err != nil {
+		orgUnitError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "message": "org unit created successfully"})
+}
+
+// getOrgUnits возвращает все организационные единицы
+func (h *Handler) getOrgUnits(c *gin.Context) {
+	units, err := h.tenantService(c).GetOrgUnits()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"org_units": units})
+}
+
+// getOrgUnit возвращает организационную единицу с дочерними единицами и группами
+func (h *Handler) getOrgUnit(c *gin.Context) {
+	unitID, ok := orgUnitID(c)
+	if !ok {
+		return
+	}
+
+	unit, err := h.tenantService(c).GetOrgUnit(unitID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"org_unit": unit})
+}
+
+// updateOrgUnit изменяет организационную единицу (админ или администратор единицы)
+func (h *Handler) updateOrgUnit(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	unitID, ok := orgUnitID(c)
+	if !ok {
+		return
+	}
+
+	var input models.OrgUnitRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tenantService(c).UpdateOrgUnit(userId, isAdmin, unitID, input); err != nil {
+		orgUnitError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "org unit updated successfully"})
+}
+
+// deleteOrgUnit удаляет пустую организационную единицу (админ или администратор родительской единицы)
+func (h *Handler) deleteOrgUnit(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	unitID, ok := orgUnitID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(c).DeleteOrgUnit(userId, isAdmin, unitID); err != nil {
+		orgUnitError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "org unit deleted successfully"})
+}
+
+// addOrgUnitGroup включает группу в организационную единицу
+func (h *Handler) addOrgUnitGroup(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	unitID, groupID, ok := orgUnitAndParamIDs(c, "groupId", "invalid group id")
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(c).SetGroupOrgUnit(userId, isAdmin, unitID, groupID); err != nil {
+		orgUnitError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "group added to org unit successfully"})
+}
+
+// removeOrgUnitGroup выводит группу из организационной единицы
+func (h *Handler) removeOrgUnitGroup(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	unitID, groupID, ok := orgUnitAndParamIDs(c, "groupId", "invalid group id")
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(c).RemoveGroupOrgUnit(userId, isAdmin, unitID, groupID); err != nil {
+		orgUnitError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "group removed from org unit successfully"})
+}
+
+// getOrgUnitAdmins возвращает администраторов организационной единицы
+func (h *Handler) getOrgUnitAdmins(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	unitID, ok := orgUnitID(c)
+	if !ok {
+		return
+	}
+
+	admins, err := h.tenantService(c).GetOrgUnitAdmins(userId, isAdmin, unitID)
+	if err != nil {
+		orgUnitError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"admins": admins})
+}
+
+// addOrgUnitAdmin назначает администратора организационной единицы
+func (h *Handler) addOrgUnitAdmin(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	unitID, adminID, ok := orgUnitAndParamIDs(c, "userId", "invalid user id")
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(c).AddOrgUnitAdmin(userId, isAdmin, unitID, adminID); err != nil {
+		orgUnitError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "org unit admin added successfully"})
+}
+
+// removeOrgUnitAdmin снимает администратора организационной единицы
+func (h *Handler) removeOrgUnitAdmin(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	unitID, adminID, ok := orgUnitAndParamIDs(c, "userId", "invalid user id")
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(c).RemoveOrgUnitAdmin(userId, isAdmin, unitID, adminID); err != nil {
+		orgUnitError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "org unit admin removed successfully"})
+}
+
+// orgUnitError отвечает 403 при отсутствии прав на единицу и заданным статусом при прочих ошибках
+func orgUnitError(c *gin.Context, err error, status int) {
+	if errors.Is(err, services.ErrOrgUnitAdminRequired) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// orgUnitID разбирает ID организационной единицы из пути запроса
+func orgUnitID(c *gin.Context) (int, bool) {
+	unitID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid org unit id"})
+		return 0, false
+	}
+	return unitID, true
+}
+
+// orgUnitAndParamIDs разбирает ID организационной единицы и второй ID из пути запроса
+func orgUnitAndParamIDs(c *gin.Context, param, message string) (int, int, bool) {
+	unitID, ok := orgUnitID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, 0, false
+	}
+	return unitID, id, true
+}
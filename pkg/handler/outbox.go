@@ -0,0 +1,48 @@
+// Package handler содержит HTTP обработчики для просмотра outbox
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getOutboxMessages возвращает сообщения outbox с фильтром status (только для админов)
+func (h *Handler) getOutboxMessages(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	messages, err := h.tenantService(c).GetOutboxMessages(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"messages": messages})
+}
+
+// retryOutboxMessage возвращает сообщение из dead-letter в очередь доставки (только для админов)
+func (h *Handler) retryOutboxMessage(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid message id"})
+		return
+	}
+
+	if err := h.tenantService(c).RequeueOutboxMessage(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "outbox message requeued successfully"})
+}
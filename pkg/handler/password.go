@@ -0,0 +1,123 @@
+// Package handler содержит HTTP обработчики для смены и сброса пароля
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sso/models"
+	"sso/pkg/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// changePassword меняет пароль текущего пользователя
+func (h *Handler) changePassword(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	// Маршрут доступен только по токену сессии, поэтому ID текущей сессии есть в контексте
+	sessionId, _ := c.Get(sessionCtx)
+
+	var input models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tenantService(c).ChangePassword(userId.(int), sessionId.(int), input); err != nil {
+		if errors.Is(err, services.ErrInvalidOldPassword) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password changed successfully"})
+}
+
+// createPasswordReset выдает одноразовый токен для сброса пароля пользователя (только для админов)
+func (h *Handler) createPasswordReset(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	adminId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	link, err := h.tenantService(c).CreatePasswordReset(userID, adminId.(int))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reset": link})
+}
+
+// resetPassword устанавливает новый пароль по одноразовому токену сброса
+func (h *Handler) resetPassword(c *gin.Context) {
+	var input models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tenantService(c).ResetPassword(input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password reset successfully"})
+}
+
+// forgotPassword отправляет одноразовый код сброса пароля в привязанный чат Telegram
+func (h *Handler) forgotPassword(c *gin.Context) {
+	var input models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tenantService(c).RequestPasswordResetCode(input); err != nil {
+		if errors.Is(err, services.ErrMessengerNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Ответ не зависит от того, существует ли аккаунт и привязан ли к нему чат
+	c.JSON(http.StatusOK, gin.H{"message": "if the account has a linked telegram chat, a reset code has been sent"})
+}
+
+// resetPasswordWithCode устанавливает новый пароль по коду, полученному в Telegram
+func (h *Handler) resetPasswordWithCode(c *gin.Context) {
+	var input models.ResetPasswordByCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tenantService(c).ResetPasswordWithCode(input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password reset successfully"})
+}
@@ -2,21 +2,21 @@
 package handler
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"sso/models"
+	"sso/pkg/export"
+	"sso/pkg/services"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
-// createQueue создает новую очередь (только для админов)
+// createQueue создает новую очередь (админ или администратор единиц, которыми ограничен допуск)
 func (h *Handler) createQueue(c *gin.Context) {
-	isAdmin, ok := c.Get(userIsAdmin)
-	if !ok || !isAdmin.(bool) {
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
-		return
-	}
-
 	var input models.CreateQueueRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -24,12 +24,21 @@ func (h *Handler) createQueue(c *gin.Context) {
 	}
 
 	queue := models.Queue{
-		Title:     input.Title,
-		TimeStart: input.TimeStart,
-		TimeEnd:   input.TimeEnd,
+		Title:         input.Title,
+		TimeStart:     input.TimeStart,
+		TimeEnd:       input.TimeEnd,
+		Mode:          input.Mode,
+		SlotDuration:  input.SlotDuration,
+		Capacity:      input.Capacity,
+		Desks:         input.Desks,
+		AllowedGroups: input.AllowedGroups,
+		AllowedUnits:  input.AllowedUnits,
+	}
+	if !h.requireQueueRestrictions(c, queue) {
+		return
 	}
 
-	id, err := h.service.CreateQueue(queue)
+	id, err := h.tenantService(c).CreateQueue(queue)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -47,7 +56,7 @@ func (h *Handler) getQueue(c *gin.Context) {
 		return
 	}
 
-	queue, err := h.service.GetQueueByID(queueID)
+	queue, err := h.tenantService(c).GetQueueByID(queueID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "queue not found"})
 		return
@@ -58,7 +67,7 @@ func (h *Handler) getQueue(c *gin.Context) {
 
 // getAllQueues возвращает все очереди
 func (h *Handler) getAllQueues(c *gin.Context) {
-	queues, err := h.service.GetAllQueues()
+	queues, err := h.tenantService(c).GetAllQueues()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -66,20 +75,17 @@ func (h *Handler) getAllQueues(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"queues": queues})
 }
 
-// updateQueue обновляет очередь (только для админов)
+// updateQueue обновляет очередь (админ или администратор единицы очереди)
 func (h *Handler) updateQueue(c *gin.Context) {
-	isAdmin, ok := c.Get(userIsAdmin)
-	if !ok || !isAdmin.(bool) {
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
-		return
-	}
-
 	id := c.Param("id")
 	queueID, err := strconv.Atoi(id)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
 		return
 	}
+	if !h.requireQueueManager(c, queueID) {
+		return
+	}
 
 	var input models.Queue
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -87,9 +93,18 @@ func (h *Handler) updateQueue(c *gin.Context) {
 		return
 	}
 
+	// Новые ограничения допуска тоже должны оставаться в поддереве администратора единицы
+	if input.Mode != "" && !h.requireQueueRestrictions(c, input) {
+		return
+	}
+
 	input.ID = queueID
-	err = h.service.UpdateQueue(input)
+	err = h.tenantService(c).UpdateQueue(input)
 	if err != nil {
+		if errors.Is(err, models.ErrSlotBookingsExist) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -97,22 +112,19 @@ func (h *Handler) updateQueue(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "queue updated successfully"})
 }
 
-// deleteQueue удаляет очередь (только для админов)
+// deleteQueue удаляет очередь (админ или администратор единицы очереди)
 func (h *Handler) deleteQueue(c *gin.Context) {
-	isAdmin, ok := c.Get(userIsAdmin)
-	if !ok || !isAdmin.(bool) {
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
-		return
-	}
-
 	idStr := c.Param("id")
 	queueID, err := strconv.Atoi(idStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
 		return
 	}
+	if !h.requireQueueManager(c, queueID) {
+		return
+	}
 
-	err = h.service.DeleteQueue(queueID)
+	err = h.tenantService(c).DeleteQueue(queueID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -121,6 +133,25 @@ func (h *Handler) deleteQueue(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "queue deleted successfully"})
 }
 
+// closeQueue закрывает очередь для новых участников (админ или администратор единицы очереди)
+func (h *Handler) closeQueue(c *gin.Context) {
+	queueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
+		return
+	}
+	if !h.requireQueueManager(c, queueID) {
+		return
+	}
+
+	if err := h.tenantService(c).CloseQueue(queueID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "queue closed successfully"})
+}
+
 // joinQueue добавляет пользователя в очередь
 func (h *Handler) joinQueue(c *gin.Context) {
 	userId, ok := c.Get(userCtx)
@@ -135,8 +166,17 @@ func (h *Handler) joinQueue(c *gin.Context) {
 		return
 	}
 
-	participantID, err := h.service.JoinQueue(input.QueueID, userId.(int))
+	participantID, err := h.tenantService(c).JoinQueue(input.QueueID, userId.(int))
 	if err != nil {
+		// Нарушение правил присоединения возвращаем со структурированным кодом
+		var policyErr *services.PolicyError
+		if errors.As(err, &policyErr) {
+			if policyErr.RetryAfter > 0 {
+				c.Header("Retry-After", retryAfterSeconds(policyErr.RetryAfter))
+			}
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": policyErr.Message, "code": policyErr.Code})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -159,7 +199,7 @@ func (h *Handler) leaveQueue(c *gin.Context) {
 		return
 	}
 
-	err = h.service.LeaveQueue(queueID, userId.(int))
+	err = h.tenantService(c).LeaveQueue(queueID, userId.(int))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -177,7 +217,22 @@ func (h *Handler) getQueueParticipants(c *gin.Context) {
 		return
 	}
 
-	participants, err := h.service.GetQueueParticipants(queueID)
+	// Выгрузка в CSV или XLSX запрашивается параметром format или заголовком Accept
+	format := c.Query("format")
+	if format == "" {
+		switch c.NegotiateFormat(gin.MIMEJSON, "text/csv", export.ContentTypeXLSX) {
+		case "text/csv":
+			format = export.FormatCSV
+		case export.ContentTypeXLSX:
+			format = export.FormatXLSX
+		}
+	}
+	if format != "" && format != "json" {
+		h.exportQueueParticipants(c, queueID, format)
+		return
+	}
+
+	participants, err := h.tenantService(c).GetQueueParticipants(queueID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -186,22 +241,62 @@ func (h *Handler) getQueueParticipants(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"participants": participants})
 }
 
-// shiftQueue сдвигает очередь (удаляет первого пользователя) - только для админов
-func (h *Handler) shiftQueue(c *gin.Context) {
-	isAdmin, ok := c.Get(userIsAdmin)
-	if !ok || !isAdmin.(bool) {
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+// exportQueueParticipants выгружает всех участников очереди в CSV или XLSX (админ или администратор единицы очереди)
+func (h *Handler) exportQueueParticipants(c *gin.Context, queueID int, format string) {
+	if !h.requireQueueManager(c, queueID) {
 		return
 	}
 
+	var contentType string
+	var write func(io.Writer, []models.ParticipantExportRow) error
+	switch format {
+	case export.FormatCSV:
+		contentType, write = export.ContentTypeCSV, export.WriteCSV
+	case export.FormatXLSX:
+		contentType, write = export.ContentTypeXLSX, export.WriteXLSX
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported export format"})
+		return
+	}
+
+	rows, err := h.tenantService(c).ExportQueueParticipants(queueID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "queue not found"})
+		return
+	}
+
+	// Формируем файл целиком, чтобы при ошибке вернуть корректный ответ
+	var buf bytes.Buffer
+	if err := write(&buf, rows); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("queue-%d-participants.%s", queueID, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// shiftQueue сдвигает очередь (удаляет первого пользователя) - админ или администратор единицы очереди
+func (h *Handler) shiftQueue(c *gin.Context) {
 	queueIDStr := c.Param("id")
 	queueID, err := strconv.Atoi(queueIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
 		return
 	}
+	if !h.requireQueueManager(c, queueID) {
+		return
+	}
+
+	// Итог приема первого участника: served (по умолчанию) или no_show
+	outcome := c.DefaultQuery("outcome", models.EventServed)
+	if outcome != models.EventServed && outcome != models.EventNoShow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid outcome"})
+		return
+	}
 
-	err = h.service.ShiftQueue(queueID)
+	err = h.tenantService(c).ShiftQueue(queueID, outcome)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -209,3 +304,29 @@ func (h *Handler) shiftQueue(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "queue shifted successfully"})
 }
+
+// requireQueueManager проверяет право текущего пользователя управлять очередью; при отказе ответ уже отправлен
+func (h *Handler) requireQueueManager(c *gin.Context, queueID int) bool {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return false
+	}
+	if err := h.tenantService(c).CheckQueueManager(userId, isAdmin, queueID); err != nil {
+		orgUnitError(c, err, http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// requireQueueRestrictions проверяет право текущего пользователя задать ограничения допуска очереди; при отказе ответ уже отправлен
+func (h *Handler) requireQueueRestrictions(c *gin.Context, queue models.Queue) bool {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return false
+	}
+	if err := h.tenantService(c).CheckQueueRestrictions(userId, isAdmin, queue); err != nil {
+		orgUnitError(c, err, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
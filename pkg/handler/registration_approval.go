@@ -0,0 +1,97 @@
+// Package handler содержит HTTP обработчики подтверждения регистрации
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sso/pkg/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pendingApprovalCode - машиночитаемый код ответа для неподтвержденного аккаунта
+const pendingApprovalCode = "pending_approval"
+
+// getPendingUsers возвращает очередь неподтвержденных регистраций группы
+func (h *Handler) getPendingUsers(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+
+	users, err := h.tenantService(c).GetPendingUsers(userId, isAdmin, groupID)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupModeratorRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// approveUser подтверждает регистрацию пользователя группы
+func (h *Handler) approveUser(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	groupID, pendingUserID, ok := groupAndUserIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(c).ApproveUser(userId, isAdmin, groupID, pendingUserID); err != nil {
+		if errors.Is(err, services.ErrGroupModeratorRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user approved successfully"})
+}
+
+// rejectUser отклоняет регистрацию и удаляет неподтвержденного пользователя группы
+func (h *Handler) rejectUser(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	groupID, pendingUserID, ok := groupAndUserIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(c).RejectUser(userId, isAdmin, groupID, pendingUserID); err != nil {
+		if errors.Is(err, services.ErrGroupModeratorRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user rejected successfully"})
+}
+
+// abortPendingApproval отвечает 403 с кодом pending_approval, если аккаунт еще не подтвержден
+func abortPendingApproval(c *gin.Context, err error) bool {
+	if !errors.Is(err, services.ErrAccountPendingApproval) {
+		return false
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": pendingApprovalCode})
+	return true
+}
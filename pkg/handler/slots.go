@@ -0,0 +1,132 @@
+// Package handler содержит HTTP обработчики для записи на слоты очереди
+package handler
+
+import (
+	"net/http"
+	"sso/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getQueueSlots возвращает слоты очереди с отметкой занятости
+func (h *Handler) getQueueSlots(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	queueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
+		return
+	}
+
+	slots, err := h.tenantService(c).GetQueueSlots(queueID, userId.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"slots": slots})
+}
+
+// getQueueTimetable возвращает расписание слотов с записавшимися пользователями (админ или администратор единицы очереди)
+func (h *Handler) getQueueTimetable(c *gin.Context) {
+	queueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
+		return
+	}
+	if !h.requireQueueManager(c, queueID) {
+		return
+	}
+
+	slots, err := h.tenantService(c).GetQueueTimetable(queueID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timetable": slots})
+}
+
+// bookSlot записывает пользователя на слот
+func (h *Handler) bookSlot(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	queueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
+		return
+	}
+
+	var input models.BookSlotRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookingID, err := h.tenantService(c).BookSlot(queueID, userId.(int), input.Slot)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"booking_id": bookingID, "message": "slot booked successfully"})
+}
+
+// rescheduleSlot переносит запись пользователя на другой слот
+func (h *Handler) rescheduleSlot(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	queueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
+		return
+	}
+
+	var input models.BookSlotRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tenantService(c).RescheduleSlot(queueID, userId.(int), input.Slot); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "booking rescheduled successfully"})
+}
+
+// cancelSlot отменяет запись пользователя на слот
+func (h *Handler) cancelSlot(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	queueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
+		return
+	}
+
+	if err := h.tenantService(c).CancelSlot(queueID, userId.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "booking cancelled successfully"})
+}
@@ -0,0 +1,96 @@
+// Package handler содержит HTTP обработчики для статистики очередей
+package handler
+
+import (
+	"net/http"
+	"sso/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dateLayout задает формат даты в параметрах запроса статистики
+const dateLayout = "2006-01-02"
+
+// getQueueStats возвращает статистику участия в очереди (админ или администратор единицы очереди)
+func (h *Handler) getQueueStats(c *gin.Context) {
+	queueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
+		return
+	}
+	if !h.requireQueueManager(c, queueID) {
+		return
+	}
+
+	report, err := h.tenantService(c).GetQueueStats(queueID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "queue not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": report})
+}
+
+// getStats возвращает сводную статистику по очередям с фильтрами from, to, group_id и org_unit_id;
+// администратору единицы доступна только статистика с фильтром org_unit_id по его поддереву
+func (h *Handler) getStats(c *gin.Context) {
+	userId, isAdmin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var filter models.StatsFilter
+	var err error
+
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = parseDateParam(from); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date"})
+			return
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = parseDateParam(to); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date"})
+			return
+		}
+		// Дата без времени включает весь день
+		if len(to) == len(dateLayout) {
+			filter.To = filter.To.AddDate(0, 0, 1)
+		}
+	}
+	if groupID := c.Query("group_id"); groupID != "" {
+		if filter.GroupID, err = strconv.Atoi(groupID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+			return
+		}
+	}
+	if orgUnitID := c.Query("org_unit_id"); orgUnitID != "" {
+		if filter.OrgUnitID, err = strconv.Atoi(orgUnitID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid org unit id"})
+			return
+		}
+	}
+
+	if err := h.tenantService(c).CheckStatsAccess(userId, isAdmin, filter); err != nil {
+		orgUnitError(c, err, http.StatusNotFound)
+		return
+	}
+
+	report, err := h.tenantService(c).GetStats(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": report})
+}
+
+// parseDateParam разбирает дату в формате 2006-01-02 или RFC 3339
+func parseDateParam(value string) (time.Time, error) {
+	if len(value) == len(dateLayout) {
+		return time.ParseInLocation(dateLayout, value, time.Local)
+	}
+	return time.Parse(time.RFC3339, value)
+}
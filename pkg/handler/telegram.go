@@ -0,0 +1,101 @@
+// Package handler содержит HTTP обработчики для привязки Telegram и входа через Telegram
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sso/models"
+	"sso/pkg/services"
+	"sso/pkg/telegram"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createTelegramLink выдает ссылку на бота для привязки чата Telegram к текущему пользователю
+func (h *Handler) createTelegramLink(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	link, err := h.tenantService(c).CreateTelegramLink(userId.(int))
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"telegram": link})
+}
+
+// unlinkTelegramChat отвязывает чат Telegram от текущего пользователя
+func (h *Handler) unlinkTelegramChat(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	if err := h.tenantService(c).UnlinkTelegramChat(userId.(int)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "telegram chat unlinked successfully"})
+}
+
+// signInTelegram обрабатывает вход через Telegram Login Widget
+func (h *Handler) signInTelegram(c *gin.Context) {
+	var input models.TelegramLoginRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.tenantService(c).SignInWithTelegram(input, clientInfo(c))
+	if err != nil {
+		if abortPendingApproval(c, err) {
+			return
+		}
+		if errors.Is(err, telegram.ErrLoginSignature) || errors.Is(err, telegram.ErrLoginExpired) ||
+			errors.Is(err, telegram.ErrLoginFromFuture) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrTelegramLinkRequired) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "telegram_link_required"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// linkTelegramLogin подключает вход через Telegram к аккаунту текущего пользователя по данным Telegram Login Widget
+func (h *Handler) linkTelegramLogin(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	var input models.TelegramLoginRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tenantService(c).LinkTelegramLogin(userId.(int), input); err != nil {
+		if errors.Is(err, telegram.ErrLoginSignature) || errors.Is(err, telegram.ErrLoginExpired) ||
+			errors.Is(err, telegram.ErrLoginFromFuture) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "telegram login linked successfully"})
+}
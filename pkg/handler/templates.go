@@ -0,0 +1,161 @@
+// Package handler содержит HTTP обработчики для шаблонов и клонирования очередей
+package handler
+
+import (
+	"net/http"
+	"sso/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createQueueTemplate создает шаблон очереди (только для админов)
+func (h *Handler) createQueueTemplate(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	var input models.CreateQueueTemplateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template := models.QueueTemplate{
+		Title:         input.Title,
+		Duration:      input.Duration,
+		Mode:          input.Mode,
+		SlotDuration:  input.SlotDuration,
+		Capacity:      input.Capacity,
+		Desks:         input.Desks,
+		AllowedGroups: input.AllowedGroups,
+		AllowedUnits:  input.AllowedUnits,
+	}
+
+	id, err := h.tenantService(c).CreateQueueTemplate(template)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "message": "template created successfully"})
+}
+
+// getAllQueueTemplates возвращает все шаблоны очередей (только для админов)
+func (h *Handler) getAllQueueTemplates(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	templates, err := h.tenantService(c).GetAllQueueTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// getQueueTemplate возвращает шаблон очереди по ID (только для админов)
+func (h *Handler) getQueueTemplate(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template id"})
+		return
+	}
+
+	template, err := h.tenantService(c).GetQueueTemplateByID(templateID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+// deleteQueueTemplate удаляет шаблон очереди (только для админов)
+func (h *Handler) deleteQueueTemplate(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template id"})
+		return
+	}
+
+	if err := h.tenantService(c).DeleteQueueTemplate(templateID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "template deleted successfully"})
+}
+
+// createQueuesFromTemplate создает одну или серию очередей по шаблону (только для админов)
+func (h *Handler) createQueuesFromTemplate(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template id"})
+		return
+	}
+
+	var input models.ScheduleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids, err := h.tenantService(c).CreateQueuesFromTemplate(templateID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ids": ids, "message": "queues created successfully"})
+}
+
+// cloneQueue копирует настройки очереди на новое время или серию дат (админ или администратор единицы очереди)
+func (h *Handler) cloneQueue(c *gin.Context) {
+	queueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid queue id"})
+		return
+	}
+	if !h.requireQueueManager(c, queueID) {
+		return
+	}
+
+	var input models.ScheduleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids, err := h.tenantService(c).CloneQueue(queueID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ids": ids, "message": "queue cloned successfully"})
+}
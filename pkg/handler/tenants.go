@@ -0,0 +1,60 @@
+// Package handler содержит HTTP обработчики управления арендаторами
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sso/models"
+	"sso/pkg/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getTenants возвращает всех арендаторов (только для админов основного арендатора)
+func (h *Handler) getTenants(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	tenants, err := h.tenantService(c).GetTenants()
+	if err != nil {
+		tenantError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tenants": tenants})
+}
+
+// createTenant создает арендатора с его первым администратором (только для админов основного арендатора)
+func (h *Handler) createTenant(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	var input models.CreateTenantRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := h.tenantService(c).CreateTenant(input)
+	if err != nil {
+		tenantError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+// tenantError отвечает 403, если арендаторами управляет не админ основного арендатора, и заданным статусом при прочих ошибках
+func tenantError(c *gin.Context, err error, status int) {
+	if errors.Is(err, services.ErrTenantAdminRequired) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,128 @@
+// Package handler содержит HTTP обработчики двухфакторной аутентификации
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sso/models"
+	"sso/pkg/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signInTwoFactor завершает вход по токену первого шага и коду TOTP или резервному коду
+func (h *Handler) signInTwoFactor(c *gin.Context) {
+	var input models.TwoFactorSignInRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Подбор кода ограничивается так же, как подбор пароля
+	if !h.allowSignIn(c, "ip:"+c.ClientIP(), h.ipLimiter) ||
+		!h.allowSignIn(c, "2fa:"+input.PreAuthToken, h.accountLimiter) {
+		return
+	}
+
+	token, err := h.tenantService(c).CompleteTwoFactorSignIn(input, clientInfo(c))
+	if err != nil {
+		if abortPendingApproval(c, err) {
+			return
+		}
+		var policyErr *services.PolicyError
+		if errors.As(err, &policyErr) {
+			c.Header("Retry-After", retryAfterSeconds(policyErr.RetryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": policyErr.Message, "code": policyErr.Code})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// setupTwoFactor выдает секрет TOTP и ссылку otpauth:// для приложения-аутентификатора
+func (h *Handler) setupTwoFactor(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	setup, err := h.tenantService(c).SetupTwoFactor(userId.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"two_factor": setup})
+}
+
+// confirmTwoFactor включает двухфакторную аутентификацию по коду из приложения
+func (h *Handler) confirmTwoFactor(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	var input models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.tenantService(c).ConfirmTwoFactor(userId.(int), input.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes, "message": "two-factor authentication enabled successfully"})
+}
+
+// regenerateRecoveryCodes выдает новые резервные коды взамен прежних
+func (h *Handler) regenerateRecoveryCodes(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	var input models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.tenantService(c).RegenerateRecoveryCodes(userId.(int), input.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// disableTwoFactor отключает двухфакторную аутентификацию после проверки кода
+func (h *Handler) disableTwoFactor(c *gin.Context) {
+	userId, ok := c.Get(userCtx)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	var input models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tenantService(c).DisableTwoFactor(userId.(int), input.Code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled successfully"})
+}
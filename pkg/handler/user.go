@@ -2,8 +2,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"sso/models"
+	"sso/pkg/services"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -17,7 +19,7 @@ func (h *Handler) getUserProfile(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.GetUserByID(userId.(int))
+	user, err := h.tenantService(c).GetUserByID(userId.(int))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -34,7 +36,7 @@ func (h *Handler) getUsers(c *gin.Context) {
 		return
 	}
 
-	users, err := h.service.GetAllUsers()
+	users, err := h.tenantService(c).GetAllUsers()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -65,15 +67,22 @@ func (h *Handler) updateUser(c *gin.Context) {
 		if input.ID != 0 {
 			targetUserID = input.ID
 		}
+	} else {
+		// Состав групп меняет только админ; студентов между группами переводят модераторы
+		input.GroupIDs = nil
 	}
 
-	err := h.service.UpdateUser(targetUserID, input)
+	change, err := h.tenantService(c).UpdateUser(targetUserID, input)
 	if err != nil {
+		if errors.Is(err, services.ErrGroupRequired) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "user updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "user updated successfully", "change": change})
 }
 
 // deleteUser удаляет пользователя (только для админов)
@@ -91,7 +100,7 @@ func (h *Handler) deleteUser(c *gin.Context) {
 		return
 	}
 
-	err = h.service.DeleteUser(userID)
+	err = h.tenantService(c).DeleteUser(userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -99,3 +108,25 @@ func (h *Handler) deleteUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
 }
+
+// unlockUser снимает временную блокировку входа пользователя (только для админов)
+func (h *Handler) unlockUser(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	if err := h.tenantService(c).UnlockUser(userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user unlocked successfully"})
+}
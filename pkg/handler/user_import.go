@@ -0,0 +1,62 @@
+// Package handler содержит HTTP обработчик массового импорта пользователей
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importMaxBytes ограничивает размер загружаемого файла импорта
+const importMaxBytes = 2 << 20
+
+// importUsers импортирует студентов из CSV со столбцами username, tg_nick и group (только для админов).
+// Файл передается в теле запроса или в поле file формы multipart; параметр dry_run=true только проверяет файл
+func (h *Handler) importUsers(c *gin.Context) {
+	isAdmin, ok := c.Get(userIsAdmin)
+	if !ok || !isAdmin.(bool) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+	adminId, _ := c.Get(userCtx)
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, importMaxBytes)
+	var data io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+		data = f
+	}
+
+	result, err := h.tenantService(c).ImportUsers(adminId.(int), data, dryRun)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// При ошибках в строках ничего не создается, в ответе - ошибки по каждой строке
+	if !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file contains invalid rows", "import": result})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"import": result})
+}
@@ -0,0 +1,118 @@
+// Package ical содержит формирование календарей в формате iCalendar (RFC 5545)
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Параметры формируемого календаря
+const (
+	prodID        = "-//best_queue//SSO Queues//RU" // Идентификатор продукта, сформировавшего календарь
+	maxLineOctets = 75                              // Максимальная длина строки без переноса (RFC 5545, 3.1)
+	utcLayout     = "20060102T150405Z"              // Формат даты и времени в UTC
+)
+
+// StatusCancelled отмечает отмененное событие; календари подписчиков убирают или зачеркивают его
+const StatusCancelled = "CANCELLED"
+
+// Event представляет событие календаря
+type Event struct {
+	UID         string    // Постоянный идентификатор события
+	Sequence    int       // Номер ревизии события; увеличивается при каждом изменении
+	Stamp       time.Time // Время последнего изменения события
+	Start       time.Time // Время начала
+	End         time.Time // Время окончания
+	Summary     string    // Заголовок
+	Description string    // Описание
+	URL         string    // Ссылка на событие
+	Status      string    // Статус события (STATUS); пустой для подтвержденного
+}
+
+// Calendar представляет календарь с набором событий
+type Calendar struct {
+	Name     string  // Отображаемое название календаря
+	TimeZone string  // Часовой пояс для отображения (X-WR-TIMEZONE); время событий хранится в UTC
+	Events   []Event // События календаря
+}
+
+// String формирует календарь в формате iCalendar
+func (c Calendar) String() string {
+	var b builder
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:" + prodID)
+	b.line("CALSCALE:GREGORIAN")
+	b.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		b.line("X-WR-CALNAME:" + escape(c.Name))
+	}
+	if c.TimeZone != "" {
+		b.line("X-WR-TIMEZONE:" + c.TimeZone)
+	}
+
+	for _, event := range c.Events {
+		b.line("BEGIN:VEVENT")
+		b.line("UID:" + escape(event.UID))
+		b.line("SEQUENCE:" + strconv.Itoa(event.Sequence))
+		b.line("DTSTAMP:" + formatTime(event.Stamp))
+		b.line("LAST-MODIFIED:" + formatTime(event.Stamp))
+		b.line("DTSTART:" + formatTime(event.Start))
+		b.line("DTEND:" + formatTime(event.End))
+		b.line("SUMMARY:" + escape(event.Summary))
+		if event.Status != "" {
+			b.line("STATUS:" + event.Status)
+		}
+		if event.Description != "" {
+			b.line("DESCRIPTION:" + escape(event.Description))
+		}
+		if event.URL != "" {
+			b.line("URL:" + event.URL)
+		}
+		b.line("END:VEVENT")
+	}
+
+	b.line("END:VCALENDAR")
+	return b.String()
+}
+
+// builder накапливает строки календаря с переносом длинных строк
+type builder struct {
+	strings.Builder
+}
+
+// line добавляет строку содержимого, перенося ее по 75 октетов без разрыва символов UTF-8
+func (b *builder) line(content string) {
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// Строка продолжения начинается с пробела, который тоже учитывается в длине
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+}
+
+// escape экранирует текстовое значение свойства (RFC 5545, 3.3.11)
+func escape(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// formatTime форматирует время в UTC
+func formatTime(t time.Time) string {
+	return t.UTC().Format(utcLayout)
+}
@@ -0,0 +1,115 @@
+// Package importer содержит чтение списков студентов из CSV для массового импорта
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sso/models"
+	"strings"
+)
+
+// MaxRows ограничивает число строк в одном файле импорта
+const MaxRows = 5000
+
+// columns содержит обязательные столбцы файла; порядок столбцов в файле может быть любым
+var columns = []string{"username", "tg_nick", "group"}
+
+// ReadUsersCSV читает файл со столбцами username, tg_nick и group; разделителем может быть запятая
+// или точка с запятой (так сохраняет CSV Excel с русской локалью). Пустые строки пропускаются
+func ReadUsersCSV(r io.Reader) ([]models.ImportUserRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	index, err := columnIndex(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.ImportUserRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isBlank(record) {
+			continue
+		}
+		if len(rows) == MaxRows {
+			return nil, fmt.Errorf("file contains more than %d rows", MaxRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, models.ImportUserRow{
+			Line:     line,
+			Username: field(record, index["username"]),
+			TgNick:   field(record, index["tg_nick"]),
+			Group:    field(record, index["group"]),
+		})
+	}
+	return rows, nil
+}
+
+// delimiter определяет разделитель по первой строке файла
+func delimiter(data []byte) rune {
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+// columnIndex находит позиции обязательных столбцов в заголовке
+func columnIndex(header []string) (map[string]int, error) {
+	index := make(map[string]int, len(columns))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range columns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	return index, nil
+}
+
+// field возвращает значение столбца без пробелов по краям или пустую строку для короткой записи
+func field(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// isBlank проверяет, что все поля записи пустые
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
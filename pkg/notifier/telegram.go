@@ -0,0 +1,70 @@
+// Package notifier содержит способы доставки уведомлений пользователям
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sso/models"
+	"sso/pkg/telegram"
+)
+
+// ChatStore предоставляет чаты Telegram, привязанные к пользователям
+type ChatStore interface {
+	GetTelegramChatID(userID int) (int64, error) // Получение чата Telegram пользователя (0 - не привязан)
+}
+
+// Telegram отправляет уведомления о событиях outbox в привязанные чаты Telegram
+type Telegram struct {
+	client *telegram.Client             // Клиент Bot API
+	chats  func(tenantID int) ChatStore // Хранилище привязанных чатов арендатора
+}
+
+// NewTelegram создает уведомитель через Telegram бота; chats возвращает хранилище чатов арендатора события
+func NewTelegram(client *telegram.Client, chats func(tenantID int) ChatStore) *Telegram {
+	return &Telegram{client: client, chats: chats}
+}
+
+// Name возвращает имя получателя outbox
+func (n *Telegram) Name() string {
+	return "telegram"
+}
+
+// Accepts сообщает, что уведомления отправляются о вызове, приближении очереди и отмене очереди
+func (n *Telegram) Accepts(eventType string) bool {
+	switch eventType {
+	case models.OutboxParticipantCalled, models.OutboxParticipantUpcoming, models.OutboxQueueCancelled:
+		return true
+	}
+	return false
+}
+
+// Deliver отправляет уведомление пользователю, затронутому событием; ошибка отправки приводит к повтору
+func (n *Telegram) Deliver(ctx context.Context, message models.OutboxMessage) error {
+	payload, err := message.DecodePayload()
+	if err != nil {
+		return err
+	}
+	chats := n.chats(message.TenantID)
+
+	switch message.EventType {
+	case models.OutboxParticipantCalled:
+		return n.notify(chats, payload.UserID, fmt.Sprintf("Вас вызывают в очереди «%s»", payload.QueueTitle))
+	case models.OutboxParticipantUpcoming:
+		return n.notify(chats, payload.UserID, fmt.Sprintf("Вы третий в очереди «%s», приготовьтесь", payload.QueueTitle))
+	case models.OutboxQueueCancelled:
+		return n.notify(chats, payload.UserID, fmt.Sprintf("Очередь «%s» отменена", payload.QueueTitle))
+	}
+	return nil
+}
+
+// notify отправляет сообщение пользователю; пользователи без привязанного чата пропускаются
+func (n *Telegram) notify(chats ChatStore, userID int, text string) error {
+	chatID, err := chats.GetTelegramChatID(userID)
+	if err != nil {
+		return err
+	}
+	if chatID == 0 {
+		return nil
+	}
+	return n.client.SendMessage(chatID, text)
+}
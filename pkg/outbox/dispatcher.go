@@ -0,0 +1,195 @@
+// Package outbox содержит фоновую доставку сообщений transactional outbox получателям
+package outbox
+
+import (
+	"context"
+	"log"
+	"sso/models"
+	"time"
+)
+
+// Параметры диспетчера по умолчанию
+const (
+	defaultPollInterval = 2 * time.Second
+	defaultBatchSize    = 50
+	defaultMaxAttempts  = 8
+	defaultBaseBackoff  = 5 * time.Second
+	defaultMaxBackoff   = time.Hour
+	defaultRetention    = 7 * 24 * time.Hour // Срок хранения доставленных сообщений
+	deliveryTimeout     = 30 * time.Second   // Время на доставку одного сообщения одному получателю
+	pruneInterval       = time.Hour          // Интервал удаления доставленных сообщений старше срока хранения
+)
+
+// Sink получает события из outbox; доставка выполняется по принципу "хотя бы один раз"
+type Sink interface {
+	Name() string                                                    // Уникальное имя получателя
+	Accepts(eventType string) bool                                   // Нужно ли получателю событие этого типа
+	Deliver(ctx context.Context, message models.OutboxMessage) error // Доставка сообщения
+}
+
+// Store хранит сообщения outbox и состояние их доставки
+type Store interface {
+	ClaimOutboxMessages(limit int, lease time.Duration) ([]models.OutboxMessage, error)
+	CompleteOutboxMessage(id int64, deliveredSinks []string) error
+	RetryOutboxMessage(id int64, deliveredSinks []string, nextAttemptAt time.Time, lastError string) error
+	DeadLetterOutboxMessage(id int64, deliveredSinks []string, lastError string) error
+	PruneOutboxMessages(deliveredBefore time.Time) (int, error)
+}
+
+// Dispatcher периодически выбирает сообщения outbox и доставляет их получателям
+// с повторными попытками, экспоненциальной задержкой и переводом в dead-letter
+type Dispatcher struct {
+	store Store
+	sinks []Sink
+	cfg   models.OutboxConfig
+}
+
+// NewDispatcher создает диспетчер; незаданные параметры конфигурации заменяются значениями по умолчанию
+func NewDispatcher(store Store, cfg models.OutboxConfig, sinks ...Sink) *Dispatcher {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = defaultBaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = defaultRetention
+	}
+	return &Dispatcher{store: store, sinks: sinks, cfg: cfg}
+}
+
+// Run доставляет сообщения до отмены контекста
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	var prunedAt time.Time
+
+	for {
+		if time.Since(prunedAt) >= pruneInterval {
+			d.Prune()
+			prunedAt = time.Now()
+		}
+
+		// Пока выбирается полная пачка, продолжаем без паузы
+		if d.DispatchBatch(ctx) == d.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchBatch доставляет до BatchSize готовых сообщений и возвращает их число. Сообщения выбираются
+// по одному непосредственно перед доставкой: срок выдачи рассчитан на одно сообщение, и при выборе всей пачки
+// сразу последние сообщения успели бы вернуться в очередь и были бы доставлены другим экземпляром повторно
+func (d *Dispatcher) DispatchBatch(ctx context.Context) int {
+	// Сообщение не выдается повторно, пока его доставляют всем получателям
+	lease := time.Duration(len(d.sinks)+1) * deliveryTimeout
+
+	dispatched := 0
+	for dispatched < d.cfg.BatchSize && ctx.Err() == nil {
+		messages, err := d.store.ClaimOutboxMessages(1, lease)
+		if err != nil {
+			log.Printf("outbox: %s", err.Error())
+			break
+		}
+		if len(messages) == 0 {
+			break
+		}
+		d.dispatch(ctx, messages[0])
+		dispatched++
+	}
+	return dispatched
+}
+
+// Prune удаляет доставленные сообщения старше срока хранения; сообщения в dead-letter остаются для разбора
+func (d *Dispatcher) Prune() {
+	pruned, err := d.store.PruneOutboxMessages(time.Now().Add(-d.cfg.Retention))
+	if err != nil {
+		log.Printf("outbox: %s", err.Error())
+		return
+	}
+	if pruned > 0 {
+		log.Printf("outbox: pruned %d delivered messages", pruned)
+	}
+}
+
+// dispatch доставляет сообщение получателям, которым оно еще не доставлено, и сохраняет результат
+func (d *Dispatcher) dispatch(ctx context.Context, message models.OutboxMessage) {
+	delivered := append([]string{}, message.DeliveredSinks...)
+	var lastErr error
+
+	for _, sink := range d.sinks {
+		if !sink.Accepts(message.EventType) || contains(delivered, sink.Name()) {
+			continue
+		}
+
+		sinkCtx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+		err := sink.Deliver(sinkCtx, message)
+		cancel()
+		if err != nil {
+			log.Printf("outbox: message %d to %s: %s", message.ID, sink.Name(), err.Error())
+			lastErr = err
+			continue
+		}
+		delivered = append(delivered, sink.Name())
+	}
+
+	if lastErr == nil {
+		err := d.store.CompleteOutboxMessage(message.ID, delivered)
+		if err != nil {
+			log.Printf("outbox: message %d: %s", message.ID, err.Error())
+		}
+		return
+	}
+
+	attempts := message.Attempts + 1
+	if attempts >= d.cfg.MaxAttempts {
+		log.Printf("outbox: message %d moved to dead-letter after %d attempts", message.ID, attempts)
+		err := d.store.DeadLetterOutboxMessage(message.ID, delivered, lastErr.Error())
+		if err != nil {
+			log.Printf("outbox: message %d: %s", message.ID, err.Error())
+		}
+		return
+	}
+
+	err := d.store.RetryOutboxMessage(message.ID, delivered, time.Now().Add(d.Backoff(attempts)), lastErr.Error())
+	if err != nil {
+		log.Printf("outbox: message %d: %s", message.ID, err.Error())
+	}
+}
+
+// Backoff возвращает задержку перед попыткой после attempts неудачных попыток: base * 2^(attempts-1), но не больше max
+func (d *Dispatcher) Backoff(attempts int) time.Duration {
+	delay := d.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.cfg.MaxBackoff {
+			return d.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// contains проверяет наличие имени в списке
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+// Package ratelimit содержит ограничение частоты запросов по алгоритму token bucket
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Параметры очистки хранилища в памяти
+const (
+	sweepInterval = time.Minute // Как часто удаляются полностью восстановившиеся корзины
+	sweepSize     = 1024        // Число корзин, начиная с которого выполняется очистка
+)
+
+// Store хранит корзины токенов; Take забирает один токен из корзины key, если он есть,
+// иначе возвращает время до появления следующего токена
+type Store interface {
+	Take(key string, rate float64, burst int) (bool, time.Duration, error)
+}
+
+// StoreFunc позволяет использовать функцию (например, метод репозитория) как хранилище корзин
+type StoreFunc func(key string, rate float64, burst int) (bool, time.Duration, error)
+
+// Take вызывает функцию хранилища
+func (f StoreFunc) Take(key string, rate float64, burst int) (bool, time.Duration, error) {
+	return f(key, rate, burst)
+}
+
+// Limiter ограничивает число запросов по ключу: burst запросов сразу и далее perMinute запросов в минуту
+type Limiter struct {
+	store Store
+	rate  float64 // Токенов в секунду
+	burst int     // Емкость корзины
+}
+
+// NewLimiter создает ограничитель; нулевой perMinute или burst отключает ограничение
+func NewLimiter(store Store, perMinute, burst int) *Limiter {
+	return &Limiter{store: store, rate: float64(perMinute) / 60, burst: burst}
+}
+
+// Allow проверяет и учитывает запрос по ключу; при отказе возвращает время до следующей разрешенной попытки
+func (l *Limiter) Allow(key string) (bool, time.Duration, error) {
+	if l == nil || l.rate <= 0 || l.burst <= 0 {
+		return true, 0, nil
+	}
+	return l.store.Take(key, l.rate, l.burst)
+}
+
+// Refill возвращает число токенов после восстановления за elapsed и забирает один токен, если он есть;
+// общая логика для хранилища в памяти и в базе данных
+func Refill(tokens float64, elapsed time.Duration, rate float64, burst int) (float64, bool, time.Duration) {
+	tokens = math.Min(float64(burst), tokens+elapsed.Seconds()*rate)
+	if tokens >= 1 {
+		return tokens - 1, true, 0
+	}
+	wait := time.Duration(math.Ceil((1 - tokens) / rate * float64(time.Second)))
+	return tokens, false, wait
+}
+
+// bucket представляет корзину токенов одного ключа
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryStore хранит корзины в памяти процесса; подходит для развертывания в одном экземпляре
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore создает хранилище корзин в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take забирает токен из корзины key
+func (m *MemoryStore) Take(key string, rate float64, burst int) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now, rate, burst)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updated: now}
+		m.buckets[key] = b
+	}
+
+	tokens, allowed, wait := Refill(b.tokens, now.Sub(b.updated), rate, burst)
+	b.tokens, b.updated = tokens, now
+	return allowed, wait, nil
+}
+
+// sweep удаляет корзины, которые успели полностью восстановиться и ничем не отличаются от новых
+func (m *MemoryStore) sweep(now time.Time, rate float64, burst int) {
+	if len(m.buckets) < sweepSize || now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, b := range m.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*rate >= float64(burst) {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sso/models"
+)
+
+// apiKeyColumns содержит список выбираемых полей API-ключа
+const apiKeyColumns = "k.id, k.user_id, k.name, k.prefix, k.key_hash, k.scopes, k.expires_at, k.last_used_at, k.revoked_at, k.created_at, u.is_admin AS owner_is_admin, u.status AS owner_status, u.tenant_id AS owner_tenant_id"
+
+// CreateAPIKey сохраняет API-ключ пользователя арендатора
+func (r *PostgresRepository) CreateAPIKey(key models.APIKey) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, name, prefix, key_hash, scopes, expires_at)
+		SELECT $1::integer, $2, $3, $4, $5, $6 WHERE %s RETURNING id`, APIKeysTable, r.refInTenant("$1::integer", UserTable))
+	err := r.db.QueryRow(query, key.UserID, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.ExpiresAt).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("user not found")
+	}
+	return id, err
+}
+
+// GetAPIKeyByID возвращает API-ключ по ID
+func (r *PostgresRepository) GetAPIKeyByID(id int) (models.APIKey, error) {
+	var key models.APIKey
+	query := fmt.Sprintf("SELECT %s FROM %s k JOIN %s u ON u.id = k.user_id WHERE k.id = $1 AND %s", apiKeyColumns, APIKeysTable, UserTable, r.inTenant("u"))
+	err := r.db.Get(&key, query, id)
+	return key, err
+}
+
+// GetAPIKeyByPrefix возвращает API-ключ по открытой части вместе со статусом владельца. Ключ передается без заголовка
+// арендатора, поэтому поиск идет среди всех арендаторов: открытая часть уникальна, а арендатор владельца возвращается в OwnerTenant
+func (r *PostgresRepository) GetAPIKeyByPrefix(prefix string) (models.APIKey, error) {
+	var key models.APIKey
+	query := fmt.Sprintf("SELECT %s FROM %s k JOIN %s u ON u.id = k.user_id WHERE k.prefix = $1", apiKeyColumns, APIKeysTable, UserTable)
+	err := r.db.Get(&key, query, prefix)
+	return key, err
+}
+
+// GetAPIKeys возвращает API-ключи пользователя; userID = 0 возвращает ключи всех пользователей
+func (r *PostgresRepository) GetAPIKeys(userID int) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	query := fmt.Sprintf("SELECT %s FROM %s k JOIN %s u ON u.id = k.user_id WHERE ($1 = 0 OR k.user_id = $1) AND %s ORDER BY k.id",
+		apiKeyColumns, APIKeysTable, UserTable, r.inTenant("u"))
+	err := r.db.Select(&keys, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает действующий API-ключ
+func (r *PostgresRepository) RevokeAPIKey(id int) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL AND %s", APIKeysTable, r.refInTenant("user_id", UserTable))
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("api key not found or already revoked")
+	}
+	return nil
+}
+
+// TouchAPIKey обновляет время последнего использования API-ключа
+func (r *PostgresRepository) TouchAPIKey(id int) error {
+	query := fmt.Sprintf("UPDATE %s SET last_used_at = NOW() WHERE id = $1 AND %s", APIKeysTable, r.refInTenant("user_id", UserTable))
+	_, err := r.db.Exec(query, id)
+	return err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"fmt"
+	"sso/models"
+	"time"
+)
+
+// HasCalendarToken проверяет, выдан ли пользователю токен ленты календаря
+func (r *PostgresRepository) HasCalendarToken(userID int) (bool, error) {
+	var issued bool
+	query := fmt.Sprintf("SELECT calendar_token_hash IS NOT NULL FROM %s WHERE id = $1 AND %s", UserTable, r.inTenant(UserTable))
+	err := r.db.Get(&issued, query, userID)
+	return issued, err
+}
+
+// SetCalendarToken сохраняет хеш нового токена ленты календаря пользователя
+func (r *PostgresRepository) SetCalendarToken(userID int, tokenHash string) error {
+	query := fmt.Sprintf("UPDATE %s SET calendar_token_hash = $1 WHERE id = $2 AND %s", UserTable, r.inTenant(UserTable))
+	result, err := r.db.Exec(query, tokenHash, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// GetUserByCalendarToken возвращает владельца токена ленты календаря по его хешу. Лента запрашивается без заголовка
+// арендатора, поэтому поиск идет среди всех арендаторов: токен уникален, а арендатор владельца возвращается в TenantID
+func (r *PostgresRepository) GetUserByCalendarToken(tokenHash string) (models.User, error) {
+	var user models.User
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE calendar_token_hash = $1", userColumns(UserTable), UserTable)
+	err := r.db.Get(&user, query, tokenHash)
+	return user, err
+}
+
+// GetDeletedQueues возвращает удаленные очереди арендатора, прием в которых заканчивался не раньше since
+func (r *PostgresRepository) GetDeletedQueues(since time.Time) ([]models.DeletedQueue, error) {
+	var queues []models.DeletedQueue
+	query := fmt.Sprintf(`SELECT id, title, time_start, time_end, sequence, allowed_groups, allowed_org_units, user_ids,
+		deleted_at AS updated_at FROM %s WHERE time_end >= $1 AND %s`, DeletedQueuesTable, r.inTenant(DeletedQueuesTable))
+	err := r.db.Select(&queues, query, since)
+	if err != nil {
+		return nil, err
+	}
+	return queues, nil
+}
+
+// GetUserQueueIDs возвращает ID очередей, в которых пользователь состоит или записан на слот
+func (r *PostgresRepository) GetUserQueueIDs(userID int) ([]int, error) {
+	var ids []int
+	query := fmt.Sprintf(`SELECT queue_id FROM %s WHERE user_id = $1 AND is_active = true AND %s
+		UNION SELECT queue_id FROM %s WHERE user_id = $1 AND %s`,
+		QueueParticipantsTable, r.inTenant(QueueParticipantsTable), SlotBookingsTable, r.inTenant(SlotBookingsTable))
+	err := r.db.Select(&ids, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetUserSlotBookings возвращает все записи пользователя на слоты
+func (r *PostgresRepository) GetUserSlotBookings(userID int) ([]models.SlotBooking, error) {
+	var bookings []models.SlotBooking
+	query := fmt.Sprintf(`SELECT b.id, b.queue_id, b.user_id, b.slot_number, b.booked_at, u.username, u.tg_nick
+		FROM %s b JOIN %s u ON u.id = b.user_id WHERE b.user_id = $1 AND %s`, SlotBookingsTable, UserTable, r.inTenant("b"))
+	err := r.db.Select(&bookings, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
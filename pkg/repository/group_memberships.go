@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"fmt"
+	"sso/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// userColumns возвращает список выбираемых полей пользователя вместе с ID его групп; table - имя или псевдоним таблицы пользователей
+func userColumns(table string) string {
+	return fmt.Sprintf("%[1]s.id, %[1]s.tenant_id, %[1]s.username, %[1]s.tg_nick, %[1]s.password_hash, %[1]s.is_admin, %[1]s.status, "+
+		"ARRAY(SELECT m.group_id FROM %[2]s m WHERE m.user_id = %[1]s.id ORDER BY m.group_id) AS group_ids", table, GroupMembershipsTable)
+}
+
+// addGroupMember добавляет пользователя в группу с заданной ролью; роль уже состоящего в группе пользователя не меняется.
+// Группа и пользователь должны принадлежать арендатору репозитория
+func (r *PostgresRepository) addGroupMember(db sqlx.Ext, groupID, userID int, role string) error {
+	if err := r.checkGroupAndUser(db, groupID, userID); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("INSERT INTO %s (group_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT (group_id, user_id) DO NOTHING", GroupMembershipsTable)
+	_, err := db.Exec(query, groupID, userID, role)
+	return err
+}
+
+// checkGroupAndUser проверяет, что группа и пользователь принадлежат арендатору репозитория
+func (r *PostgresRepository) checkGroupAndUser(db sqlx.Queryer, groupID, userID int) error {
+	var found bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s g JOIN %s u ON u.tenant_id = g.tenant_id
+		WHERE g.id = $1 AND u.id = $2 AND %s)`, GroupTable, UserTable, r.inTenant("g"))
+	if err := sqlx.Get(db, &found, query, groupID, userID); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("group or user not found")
+	}
+	return nil
+}
+
+// GetGroupMembers возвращает участников группы с их ролями
+func (r *PostgresRepository) GetGroupMembers(groupID int) ([]models.GroupMember, error) {
+	var members []models.GroupMember
+	query := fmt.Sprintf(`SELECT %s, gm.role, gm.created_at AS joined_at
+		FROM %s u JOIN %s gm ON gm.user_id = u.id
+		WHERE gm.group_id = $1 AND %s ORDER BY u.username`, userColumns("u"), UserTable, GroupMembershipsTable, r.inTenant("u"))
+	err := r.db.Select(&members, query, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddGroupMember добавляет пользователя в группу участником; роль уже состоящего в группе пользователя не меняется
+func (r *PostgresRepository) AddGroupMember(groupID, userID int) error {
+	return r.addGroupMember(r.db, groupID, userID, models.GroupRoleMember)
+}
+
+// RemoveGroupMember исключает пользователя из группы вместе с его ролью в ней
+func (r *PostgresRepository) RemoveGroupMember(groupID, userID int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE group_id = $1 AND user_id = $2 AND %s", GroupMembershipsTable, r.refInTenant("group_id", GroupTable))
+	result, err := r.db.Exec(query, groupID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("member not found")
+	}
+	return nil
+}
+
+// TransferGroupMember в одной транзакции переводит пользователя из одной группы в другую;
+// в новой группе он становится участником, роль модератора в прежней группе не переносится
+func (r *PostgresRepository) TransferGroupMember(userID, fromGroupID, toGroupID int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE group_id = $1 AND user_id = $2 AND %s", GroupMembershipsTable, r.refInTenant("group_id", GroupTable))
+	result, err := tx.Exec(query, fromGroupID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("member not found")
+	}
+
+	if err := r.addGroupMember(tx, toGroupID, userID, models.GroupRoleMember); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
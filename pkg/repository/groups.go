@@ -7,8 +7,8 @@ import (
 
 func (r *PostgresRepository) CreateGroup(code, comment string) (int, error) {
 	var id int
-	query := fmt.Sprintf("INSERT INTO %s (code, comment) VALUES ($1, $2) RETURNING id", GroupTable)
-	err := r.db.QueryRow(query, code, comment).Scan(&id)
+	query := fmt.Sprintf("INSERT INTO %s (tenant_id, code, comment) VALUES ($1, $2, $3) RETURNING id", GroupTable)
+	err := r.db.QueryRow(query, r.tenantID, code, comment).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -17,7 +17,7 @@ func (r *PostgresRepository) CreateGroup(code, comment string) (int, error) {
 
 func (r *PostgresRepository) GetGroupByID(id int) (models.Group, error) {
 	var group models.Group
-	query := fmt.Sprintf("SELECT id, code, comment FROM %s WHERE id = $1", GroupTable)
+	query := fmt.Sprintf("SELECT id, code, comment, org_unit_id FROM %s WHERE id = $1 AND %s", GroupTable, r.inTenant(GroupTable))
 	err := r.db.Get(&group, query, id)
 	if err != nil {
 		return group, err
@@ -27,7 +27,7 @@ func (r *PostgresRepository) GetGroupByID(id int) (models.Group, error) {
 
 func (r *PostgresRepository) GetGroupByCode(code string) (models.Group, error) {
 	var group models.Group
-	query := fmt.Sprintf("SELECT id, code, comment FROM %s WHERE code = $1", GroupTable)
+	query := fmt.Sprintf("SELECT id, code, comment, org_unit_id FROM %s WHERE code = $1 AND %s", GroupTable, r.inTenant(GroupTable))
 	err := r.db.Get(&group, query, code)
 	if err != nil {
 		return group, err
@@ -37,7 +37,7 @@ func (r *PostgresRepository) GetGroupByCode(code string) (models.Group, error) {
 
 func (r *PostgresRepository) GetAllGroups() ([]models.Group, error) {
 	var groups []models.Group
-	query := fmt.Sprintf("SELECT id, code, comment FROM %s ORDER BY code", GroupTable)
+	query := fmt.Sprintf("SELECT id, code, comment, org_unit_id FROM %s WHERE %s ORDER BY code", GroupTable, r.inTenant(GroupTable))
 	err := r.db.Select(&groups, query)
 	if err != nil {
 		return nil, err
@@ -46,13 +46,13 @@ func (r *PostgresRepository) GetAllGroups() ([]models.Group, error) {
 }
 
 func (r *PostgresRepository) UpdateGroup(id int, group models.Group) error {
-	query := fmt.Sprintf("UPDATE %s SET code = $1, comment = $2 WHERE id = $3", GroupTable)
+	query := fmt.Sprintf("UPDATE %s SET code = $1, comment = $2 WHERE id = $3 AND %s", GroupTable, r.inTenant(GroupTable))
 	_, err := r.db.Exec(query, group.Code, group.Comment, id)
 	return err
 }
 
 func (r *PostgresRepository) DeleteGroup(id int) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", GroupTable)
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND %s", GroupTable, r.inTenant(GroupTable))
 	_, err := r.db.Exec(query, id)
 	return err
 }
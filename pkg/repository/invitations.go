@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sso/models"
+)
+
+// AddGroupModerator назначает пользователя модератором группы; не состоявший в группе пользователь становится ее участником
+func (r *PostgresRepository) AddGroupModerator(groupID, userID int) error {
+	if err := r.checkGroupAndUser(r.db, groupID, userID); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (group_id, user_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (group_id, user_id) DO UPDATE SET role = EXCLUDED.role`, GroupMembershipsTable)
+	_, err := r.db.Exec(query, groupID, userID, models.GroupRoleModerator)
+	return err
+}
+
+// RemoveGroupModerator снимает пользователя с роли модератора группы; участником группы он остается
+func (r *PostgresRepository) RemoveGroupModerator(groupID, userID int) error {
+	query := fmt.Sprintf("UPDATE %s SET role = $1 WHERE group_id = $2 AND user_id = $3 AND role = $4 AND %s",
+		GroupMembershipsTable, r.refInTenant("group_id", GroupTable))
+	result, err := r.db.Exec(query, models.GroupRoleMember, groupID, userID, models.GroupRoleModerator)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("moderator not found")
+	}
+	return nil
+}
+
+// GetGroupModerators возвращает модераторов группы
+func (r *PostgresRepository) GetGroupModerators(groupID int) ([]models.User, error) {
+	var users []models.User
+	query := fmt.Sprintf(`SELECT %s
+		FROM %s u JOIN %s gm ON gm.user_id = u.id
+		WHERE gm.group_id = $1 AND gm.role = $2 AND %s ORDER BY u.username`, userColumns("u"), UserTable, GroupMembershipsTable, r.inTenant("u"))
+	err := r.db.Select(&users, query, groupID, models.GroupRoleModerator)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// IsGroupModerator проверяет, является ли пользователь модератором группы
+func (r *PostgresRepository) IsGroupModerator(userID, groupID int) (bool, error) {
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE group_id = $1 AND user_id = $2 AND role = $3 AND %s)",
+		GroupMembershipsTable, r.refInTenant("group_id", GroupTable))
+	err := r.db.Get(&exists, query, groupID, userID, models.GroupRoleModerator)
+	return exists, err
+}
+
+// CreateInvitation сохраняет приглашение с хешем кода в группу арендатора
+func (r *PostgresRepository) CreateInvitation(invitation models.Invitation, codeHash string) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (group_id, code_hash, created_by, max_uses, expires_at)
+		SELECT $1::integer, $2, $3::integer, $4::integer, $5 WHERE %s RETURNING id`, InvitationsTable, r.refInTenant("$1::integer", GroupTable))
+	err := r.db.QueryRow(query, invitation.GroupID, codeHash, invitation.CreatedBy, invitation.MaxUses, invitation.ExpiresAt).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("group not found")
+	}
+	return id, err
+}
+
+// GetInvitations возвращает приглашения группы, начиная с последних
+func (r *PostgresRepository) GetInvitations(groupID int) ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	query := fmt.Sprintf(`SELECT id, group_id, created_by, max_uses, uses, expires_at, revoked_at, created_at
+		FROM %s WHERE group_id = $1 AND %s ORDER BY id DESC`, InvitationsTable, r.refInTenant("group_id", GroupTable))
+	err := r.db.Select(&invitations, query, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation отзывает действующее приглашение группы
+func (r *PostgresRepository) RevokeInvitation(id, groupID int) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = NOW() WHERE id = $1 AND group_id = $2 AND revoked_at IS NULL AND %s",
+		InvitationsTable, r.refInTenant("group_id", GroupTable))
+	result, err := r.db.Exec(query, id, groupID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("invitation not found or already revoked")
+	}
+	return nil
+}
+
+// CreateUserByInvitation в одной транзакции погашает приглашение в группу арендатора и создает пользователя в этой группе;
+// возвращает sql.ErrNoRows, если приглашение не найдено, отозвано, истекло или исчерпано
+func (r *PostgresRepository) CreateUserByInvitation(user models.RegisterUser, codeHash string, tgUserID sql.NullInt64) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var groupID int
+	redeemQuery := fmt.Sprintf(`UPDATE %s SET uses = uses + 1
+		WHERE code_hash = $1 AND revoked_at IS NULL AND expires_at > NOW() AND uses < max_uses AND %s
+		RETURNING group_id`, InvitationsTable, r.refInTenant("group_id", GroupTable))
+	if err := tx.QueryRow(redeemQuery, codeHash).Scan(&groupID); err != nil {
+		return 0, err
+	}
+
+	id, err := r.insertUser(tx, user, groupID, tgUserID)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
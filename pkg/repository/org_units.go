@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sso/models"
+
+	"github.com/lib/pq"
+)
+
+// orgUnitColumns содержит список выбираемых полей организационной единицы
+const orgUnitColumns = "id, parent_id, kind, code, title, created_at"
+
+// orgUnitSubtree возвращает подзапрос, выбирающий ID единицы арендатора с заданным параметром и всех ее потомков;
+// родитель единицы всегда принадлежит тому же арендатору, поэтому обход не выходит за его пределы
+func (r *PostgresRepository) orgUnitSubtree(placeholder string) string {
+	return fmt.Sprintf(`WITH RECURSIVE subtree AS (
+			SELECT id FROM %s WHERE id = %s AND %s
+			UNION ALL
+			SELECT u.id FROM %s u JOIN subtree s ON u.parent_id = s.id
+		) SELECT id FROM subtree`, OrgUnitsTable, placeholder, r.inTenant(OrgUnitsTable), OrgUnitsTable)
+}
+
+// orgUnitPath возвращает подзапрос, выбирающий ID единицы арендатора с заданным параметром и всех ее предков
+func (r *PostgresRepository) orgUnitPath(placeholder string) string {
+	return fmt.Sprintf(`WITH RECURSIVE path AS (
+			SELECT id, parent_id FROM %s WHERE id = %s AND %s
+			UNION ALL
+			SELECT u.id, u.parent_id FROM %s u JOIN path p ON u.id = p.parent_id
+		) SELECT id FROM path`, OrgUnitsTable, placeholder, r.inTenant(OrgUnitsTable), OrgUnitsTable)
+}
+
+// parentInTenant возвращает условие, что родительская единица с заданным параметром не указана или принадлежит арендатору
+func (r *PostgresRepository) parentInTenant(placeholder string) string {
+	return fmt.Sprintf("(%[1]s IS NULL OR %[2]s)", placeholder, r.refInTenant(placeholder, OrgUnitsTable))
+}
+
+// CreateOrgUnit сохраняет организационную единицу арендатора и возвращает ее ID
+func (r *PostgresRepository) CreateOrgUnit(unit models.OrgUnit) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (tenant_id, parent_id, kind, code, title)
+		SELECT $1::integer, $2::integer, $3, $4, $5 WHERE %s RETURNING id`, OrgUnitsTable, r.parentInTenant("$2::integer"))
+	err := r.db.QueryRow(query, r.tenantID, unit.ParentID, unit.Kind, unit.Code, unit.Title).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("parent org unit not found")
+	}
+	return id, err
+}
+
+// GetOrgUnitByID возвращает организационную единицу по ID
+func (r *PostgresRepository) GetOrgUnitByID(id int) (models.OrgUnit, error) {
+	var unit models.OrgUnit
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 AND %s", orgUnitColumns, OrgUnitsTable, r.inTenant(OrgUnitsTable))
+	err := r.db.Get(&unit, query, id)
+	return unit, err
+}
+
+// GetOrgUnits возвращает все организационные единицы
+func (r *PostgresRepository) GetOrgUnits() ([]models.OrgUnit, error) {
+	var units []models.OrgUnit
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY code", orgUnitColumns, OrgUnitsTable, r.inTenant(OrgUnitsTable))
+	err := r.db.Select(&units, query)
+	if err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// GetOrgUnitChildren возвращает дочерние единицы
+func (r *PostgresRepository) GetOrgUnitChildren(id int) ([]models.OrgUnit, error) {
+	units := []models.OrgUnit{}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE parent_id = $1 AND %s ORDER BY code", orgUnitColumns, OrgUnitsTable, r.inTenant(OrgUnitsTable))
+	err := r.db.Select(&units, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// GetOrgUnitGroups возвращает группы, входящие непосредственно в единицу
+func (r *PostgresRepository) GetOrgUnitGroups(id int) ([]models.Group, error) {
+	groups := []models.Group{}
+	query := fmt.Sprintf("SELECT id, code, comment, org_unit_id FROM %s WHERE org_unit_id = $1 AND %s ORDER BY code", GroupTable, r.inTenant(GroupTable))
+	err := r.db.Select(&groups, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// UpdateOrgUnit обновляет организационную единицу
+func (r *PostgresRepository) UpdateOrgUnit(unit models.OrgUnit) error {
+	query := fmt.Sprintf("UPDATE %s SET parent_id = $1, kind = $2, code = $3, title = $4 WHERE id = $5 AND %s AND %s",
+		OrgUnitsTable, r.inTenant(OrgUnitsTable), r.parentInTenant("$1::integer"))
+	result, err := r.db.Exec(query, unit.ParentID, unit.Kind, unit.Code, unit.Title, unit.ID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("org unit not found")
+	}
+	return nil
+}
+
+// DeleteOrgUnit удаляет организационную единицу; единицу с дочерними единицами или группами удалить нельзя
+func (r *PostgresRepository) DeleteOrgUnit(id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND %s", OrgUnitsTable, r.inTenant(OrgUnitsTable))
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("org unit not found")
+	}
+	return nil
+}
+
+// GetOrgUnitPath возвращает ID единицы и всех ее предков
+func (r *PostgresRepository) GetOrgUnitPath(id int) ([]int64, error) {
+	var ids []int64
+	err := r.db.Select(&ids, r.orgUnitPath("$1"), id)
+	return ids, err
+}
+
+// GetGroupOrgUnits возвращает ID единицы группы и всех ее предков; у группы вне дерева список пуст
+func (r *PostgresRepository) GetGroupOrgUnits(groupID int) ([]int64, error) {
+	var ids []int64
+	query := r.orgUnitPath(fmt.Sprintf("(SELECT org_unit_id FROM %s WHERE id = $1)", GroupTable))
+	err := r.db.Select(&ids, query, groupID)
+	return ids, err
+}
+
+// SetGroupOrgUnit переносит группу в организационную единицу арендатора; nil выводит группу из дерева
+func (r *PostgresRepository) SetGroupOrgUnit(groupID int, unitID *int) error {
+	query := fmt.Sprintf("UPDATE %s SET org_unit_id = $1 WHERE id = $2 AND %s AND %s",
+		GroupTable, r.inTenant(GroupTable), r.parentInTenant("$1::integer"))
+	result, err := r.db.Exec(query, unitID, groupID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("group not found")
+	}
+	return nil
+}
+
+// AddOrgUnitAdmin назначает пользователя администратором организационной единицы; единица и пользователь
+// должны принадлежать арендатору
+func (r *PostgresRepository) AddOrgUnitAdmin(unitID, userID int) error {
+	var found bool
+	checkQuery := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s o JOIN %s u ON u.tenant_id = o.tenant_id
+		WHERE o.id = $1 AND u.id = $2 AND %s)`, OrgUnitsTable, UserTable, r.inTenant("o"))
+	if err := r.db.Get(&found, checkQuery, unitID, userID); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("org unit or user not found")
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (org_unit_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", OrgUnitAdminsTable)
+	_, err := r.db.Exec(query, unitID, userID)
+	return err
+}
+
+// RemoveOrgUnitAdmin снимает пользователя с роли администратора организационной единицы
+func (r *PostgresRepository) RemoveOrgUnitAdmin(unitID, userID int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE org_unit_id = $1 AND user_id = $2 AND %s", OrgUnitAdminsTable, r.refInTenant("org_unit_id", OrgUnitsTable))
+	result, err := r.db.Exec(query, unitID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("org unit admin not found")
+	}
+	return nil
+}
+
+// GetOrgUnitAdmins возвращает администраторов организационной единицы
+func (r *PostgresRepository) GetOrgUnitAdmins(unitID int) ([]models.User, error) {
+	users := []models.User{}
+	query := fmt.Sprintf(`SELECT %s
+		FROM %s a JOIN %s u ON u.id = a.user_id
+		WHERE a.org_unit_id = $1 AND %s ORDER BY u.username`, userColumns("u"), OrgUnitAdminsTable, UserTable, r.inTenant("u"))
+	err := r.db.Select(&users, query, unitID)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// IsOrgUnitAdmin проверяет, является ли пользователь администратором хотя бы одной из единиц
+func (r *PostgresRepository) IsOrgUnitAdmin(userID int, unitIDs []int64) (bool, error) {
+	if len(unitIDs) == 0 {
+		return false, nil
+	}
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE user_id = $1 AND org_unit_id = ANY($2) AND %s)",
+		OrgUnitAdminsTable, r.refInTenant("org_unit_id", OrgUnitsTable))
+	err := r.db.Get(&exists, query, userID, pq.Array(unitIDs))
+	return exists, err
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"sso/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// outboxColumns содержит список выбираемых полей сообщения outbox
+const outboxColumns = "id, tenant_id, event_type, payload, status, delivered_sinks, attempts, next_attempt_at, last_error, created_at"
+
+// addOutboxMessage записывает событие арендатора в outbox в рамках транзакции изменения очереди
+func (r *PostgresRepository) addOutboxMessage(tx *sqlx.Tx, eventType string, payload models.OutboxPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (tenant_id, event_type, payload) VALUES ($1, $2, $3)", OutboxMessagesTable)
+	_, err = tx.Exec(query, r.tenantID, eventType, data)
+	return err
+}
+
+// queuePayload возвращает данные события с названием и временем очереди арендатора
+func (r *PostgresRepository) queuePayload(tx *sqlx.Tx, queueID int) (models.OutboxPayload, error) {
+	payload := models.OutboxPayload{QueueID: queueID}
+	query := fmt.Sprintf("SELECT COALESCE(title, ''), time_start FROM %s WHERE id = $1 AND %s", QueuesTable, r.inTenant(QueuesTable))
+	err := tx.QueryRow(query, queueID).Scan(&payload.QueueTitle, &payload.TimeStart)
+	return payload, err
+}
+
+// addParticipantMessage записывает в outbox событие участника очереди
+func (r *PostgresRepository) addParticipantMessage(tx *sqlx.Tx, eventType string, queueID, userID, position, waitSeconds int) error {
+	payload, err := r.queuePayload(tx, queueID)
+	if err != nil {
+		return err
+	}
+	payload.UserID = userID
+	payload.Position = position
+	payload.WaitSeconds = waitSeconds
+	return r.addOutboxMessage(tx, eventType, payload)
+}
+
+// addQueueProgressMessages записывает события для участников, продвинувшихся в очереди после ухода участника
+// с позиции movedAfter: первый по порядку вызывается (если called), третий по порядку предупреждается.
+// Позиции после выхода из очереди не уплотняются, поэтому место определяется порядком активных участников
+func (r *PostgresRepository) addQueueProgressMessages(tx *sqlx.Tx, queueID int, called bool, movedAfter int) error {
+	var participants []models.QueueParticipant
+	query := fmt.Sprintf("SELECT id, queue_id, user_id, position, joined_at, is_active FROM %s WHERE queue_id = $1 AND is_active = true AND %s ORDER BY position LIMIT 3",
+		QueueParticipantsTable, r.inTenant(QueueParticipantsTable))
+	if err := tx.Select(&participants, query, queueID); err != nil {
+		return err
+	}
+
+	if called && len(participants) >= 1 {
+		first := participants[0]
+		if err := r.addParticipantMessage(tx, models.OutboxParticipantCalled, queueID, first.UserID, 1, 0); err != nil {
+			return err
+		}
+	}
+	if len(participants) == 3 && participants[2].Position > movedAfter {
+		third := participants[2]
+		if err := r.addParticipantMessage(tx, models.OutboxParticipantUpcoming, queueID, third.UserID, 3, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ClaimOutboxMessages выбирает сообщения, готовые к доставке, и откладывает их повторную выдачу на lease,
+// чтобы параллельные диспетчеры не доставляли одно сообщение одновременно. Диспетчер общий для всех арендаторов,
+// поэтому методы доставки не ограничены арендатором; арендатор сообщения передается получателям в TenantID
+func (r *PostgresRepository) ClaimOutboxMessages(limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	query := fmt.Sprintf(`UPDATE %[1]s SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM %[1]s WHERE status = '%[2]s' AND next_attempt_at <= NOW()
+			ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED
+		) RETURNING %[3]s`, OutboxMessagesTable, models.OutboxStatusPending, outboxColumns)
+	err := r.db.Select(&messages, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// CompleteOutboxMessage отмечает сообщение доставленным всем получателям
+func (r *PostgresRepository) CompleteOutboxMessage(id int64, deliveredSinks []string) error {
+	query := fmt.Sprintf("UPDATE %s SET status = $1, delivered_sinks = $2, delivered_at = NOW(), last_error = NULL WHERE id = $3", OutboxMessagesTable)
+	_, err := r.db.Exec(query, models.OutboxStatusDelivered, pq.StringArray(deliveredSinks), id)
+	return err
+}
+
+// RetryOutboxMessage сохраняет неудачную попытку доставки и время следующей попытки
+func (r *PostgresRepository) RetryOutboxMessage(id int64, deliveredSinks []string, nextAttemptAt time.Time, lastError string) error {
+	query := fmt.Sprintf("UPDATE %s SET attempts = attempts + 1, delivered_sinks = $1, next_attempt_at = $2, last_error = $3 WHERE id = $4", OutboxMessagesTable)
+	_, err := r.db.Exec(query, pq.StringArray(deliveredSinks), nextAttemptAt, lastError, id)
+	return err
+}
+
+// DeadLetterOutboxMessage прекращает попытки доставки сообщения
+func (r *PostgresRepository) DeadLetterOutboxMessage(id int64, deliveredSinks []string, lastError string) error {
+	query := fmt.Sprintf("UPDATE %s SET status = $1, attempts = attempts + 1, delivered_sinks = $2, last_error = $3 WHERE id = $4", OutboxMessagesTable)
+	_, err := r.db.Exec(query, models.OutboxStatusDead, pq.StringArray(deliveredSinks), lastError, id)
+	return err
+}
+
+// PruneOutboxMessages удаляет сообщения, доставленные раньше deliveredBefore, и возвращает их число
+func (r *PostgresRepository) PruneOutboxMessages(deliveredBefore time.Time) (int, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE status = $1 AND delivered_at < $2", OutboxMessagesTable)
+	result, err := r.db.Exec(query, models.OutboxStatusDelivered, deliveredBefore)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// GetOutboxMessages возвращает последние сообщения outbox в указанном состоянии
+func (r *PostgresRepository) GetOutboxMessages(status string, limit int) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE status = $1 AND %s ORDER BY id DESC LIMIT $2", outboxColumns, OutboxMessagesTable, r.inTenant(OutboxMessagesTable))
+	err := r.db.Select(&messages, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// RequeueOutboxMessage возвращает сообщение из dead-letter в очередь доставки с обнулением попыток
+func (r *PostgresRepository) RequeueOutboxMessage(id int64) error {
+	query := fmt.Sprintf("UPDATE %s SET status = $1, attempts = 0, next_attempt_at = NOW() WHERE id = $2 AND status = $3 AND %s", OutboxMessagesTable, r.inTenant(OutboxMessagesTable))
+	result, err := r.db.Exec(query, models.OutboxStatusPending, id, models.OutboxStatusDead)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("dead-lettered message not found")
+	}
+	return nil
+}
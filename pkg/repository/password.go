@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// UpdatePasswordHash сохраняет новый хеш пароля пользователя
+func (r *PostgresRepository) UpdatePasswordHash(userID int, passwordHash string) error {
+	query := fmt.Sprintf("UPDATE %s SET password_hash = $1 WHERE id = $2 AND %s", UserTable, r.inTenant(UserTable))
+	result, err := r.db.Exec(query, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// CreatePasswordResetToken сохраняет хеш токена сброса пароля пользователя арендатора, отзывая ранее выданные токены пользователя
+func (r *PostgresRepository) CreatePasswordResetToken(userID, createdBy int, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 OR expires_at < NOW()", PasswordResetTokensTable)
+	if _, err := tx.Exec(deleteQuery, userID); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf(`INSERT INTO %s (token_hash, user_id, created_by, expires_at)
+		SELECT $1, $2::integer, $3::integer, $4 WHERE %s`, PasswordResetTokensTable, r.refInTenant("$2::integer", UserTable))
+	result, err := tx.Exec(insertQuery, tokenHash, userID, createdBy, expiresAt)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return tx.Commit()
+}
+
+// ResetPasswordByToken погашает токен сброса пользователя арендатора и устанавливает новый хеш пароля; возвращает ID пользователя
+func (r *PostgresRepository) ResetPasswordByToken(tokenHash, passwordHash string) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	tokenQuery := fmt.Sprintf("DELETE FROM %s WHERE token_hash = $1 AND expires_at > NOW() AND %s RETURNING user_id", PasswordResetTokensTable, r.refInTenant("user_id", UserTable))
+	err = tx.QueryRow(tokenQuery, tokenHash).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("reset token is invalid or expired")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	updateQuery := fmt.Sprintf("UPDATE %s SET password_hash = $1 WHERE id = $2", UserTable)
+	if _, err := tx.Exec(updateQuery, passwordHash, userID); err != nil {
+		return 0, err
+	}
+
+	return userID, tx.Commit()
+}
+
+// CreatePasswordResetCode сохраняет хеш кода сброса, заменяя прежний код пользователя арендатора;
+// возвращает false, если прежний код отправлен раньше чем interval назад
+func (r *PostgresRepository) CreatePasswordResetCode(userID int, codeHash string, expiresAt time.Time, interval time.Duration) (bool, error) {
+	query := fmt.Sprintf(`INSERT INTO %[1]s (user_id, code_hash, attempts, expires_at, created_at)
+		SELECT $1::integer, $2, 0, $3, NOW() WHERE %[2]s
+		ON CONFLICT (user_id) DO UPDATE SET code_hash = EXCLUDED.code_hash, attempts = 0, expires_at = EXCLUDED.expires_at, created_at = NOW()
+		WHERE %[1]s.created_at <= NOW() - $4 * INTERVAL '1 second'`, PasswordResetCodesTable, r.refInTenant("$1::integer", UserTable))
+	result, err := r.db.Exec(query, userID, codeHash, expiresAt, interval.Seconds())
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// UsePasswordResetCodeAttempt засчитывает попытку ввода кода сброса и возвращает хеш действующего кода
+func (r *PostgresRepository) UsePasswordResetCodeAttempt(userID, maxAttempts int) (string, error) {
+	var codeHash string
+	query := fmt.Sprintf(`UPDATE %s SET attempts = attempts + 1
+		WHERE user_id = $1 AND expires_at > NOW() AND attempts < $2 AND %s RETURNING code_hash`, PasswordResetCodesTable, r.refInTenant("user_id", UserTable))
+	err := r.db.QueryRow(query, userID, maxAttempts).Scan(&codeHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("reset code is invalid or expired")
+	}
+	return codeHash, err
+}
+
+// ResetPasswordByCode погашает код сброса и устанавливает новый хеш пароля
+func (r *PostgresRepository) ResetPasswordByCode(userID int, codeHash, passwordHash string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	codeQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND code_hash = $2 AND expires_at > NOW() AND %s", PasswordResetCodesTable, r.refInTenant("user_id", UserTable))
+	result, err := tx.Exec(codeQuery, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("reset code is invalid or expired")
+	}
+
+	updateQuery := fmt.Sprintf("UPDATE %s SET password_hash = $1 WHERE id = $2", UserTable)
+	if _, err := tx.Exec(updateQuery, passwordHash, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -1,23 +1,69 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"sso/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// queueColumns содержит список выбираемых полей очереди вместе с допущенными группами
+var queueColumns = fmt.Sprintf("id, title, time_start, time_end, mode, slot_duration, capacity, desks, closed_at, sequence, updated_at, "+
+	"ARRAY(SELECT group_id FROM %s g WHERE g.queue_id = %s.id ORDER BY group_id) AS allowed_groups, "+
+	"ARRAY(SELECT org_unit_id FROM %s u WHERE u.queue_id = %s.id ORDER BY org_unit_id) AS allowed_org_units",
+	QueueGroupsTable, QueuesTable, QueueOrgUnitsTable, QueuesTable)
+
 func (r *PostgresRepository) CreateQueue(queue models.Queue) (int, error) {
-	var id int
-	query := fmt.Sprintf("INSERT INTO %s (title, time_start, time_end) VALUES ($1, $2, $3) RETURNING id", QueuesTable)
-	err := r.db.QueryRow(query, queue.Title, queue.TimeStart, queue.TimeEnd).Scan(&id)
+	ids, err := r.CreateQueues([]models.Queue{queue})
 	if err != nil {
 		return 0, err
 	}
-	return id, nil
+	return ids[0], nil
+}
+
+// CreateQueues создает несколько очередей арендатора в одной транзакции
+func (r *PostgresRepository) CreateQueues(queues []models.Queue) ([]int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int, 0, len(queues))
+	for _, queue := range queues {
+		var id int
+		query := fmt.Sprintf("INSERT INTO %s (tenant_id, title, time_start, time_end, mode, slot_duration, capacity, desks) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id", QueuesTable)
+		err := tx.QueryRow(query, r.tenantID, queue.Title, queue.TimeStart, queue.TimeEnd, queue.Mode, queue.SlotDuration, queue.Capacity, queue.Desks).Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.setQueueGroups(tx, id, queue.AllowedGroups); err != nil {
+			return nil, err
+		}
+		if err := r.setQueueOrgUnits(tx, id, queue.AllowedUnits); err != nil {
+			return nil, err
+		}
+
+		payload := models.OutboxPayload{QueueID: id, QueueTitle: queue.Title, TimeStart: queue.TimeStart}
+		if err := r.addOutboxMessage(tx, models.OutboxQueueCreated, payload); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
 func (r *PostgresRepository) GetQueueByID(id int) (models.Queue, error) {
 	var queue models.Queue
-	query := fmt.Sprintf("SELECT id, title, time_start, time_end FROM %s WHERE id = $1", QueuesTable)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 AND %s", queueColumns, QueuesTable, r.inTenant(QueuesTable))
 	err := r.db.Get(&queue, query, id)
 	if err != nil {
 		return queue, err
@@ -27,7 +73,7 @@ func (r *PostgresRepository) GetQueueByID(id int) (models.Queue, error) {
 
 func (r *PostgresRepository) GetAllQueues() ([]models.Queue, error) {
 	var queues []models.Queue
-	query := fmt.Sprintf("SELECT id, title, time_start, time_end FROM %s ORDER BY time_start DESC", QueuesTable)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY time_start DESC", queueColumns, QueuesTable, r.inTenant(QueuesTable))
 	err := r.db.Select(&queues, query)
 	if err != nil {
 		return nil, err
@@ -36,13 +82,167 @@ func (r *PostgresRepository) GetAllQueues() ([]models.Queue, error) {
 }
 
 func (r *PostgresRepository) UpdateQueue(queue models.Queue) error {
-	query := fmt.Sprintf("UPDATE %s SET title = $1, time_start = $2, time_end = $3 WHERE id = $4", QueuesTable)
-	_, err := r.db.Exec(query, queue.Title, queue.TimeStart, queue.TimeEnd, queue.ID)
-	return err
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Очередь блокируется до конца транзакции, чтобы новые записи на слоты не появились во время изменения расписания
+	current, err := r.lockSlotSchedule(tx, queue.ID, "FOR UPDATE")
+	if err != nil {
+		return err
+	}
+	var lastSlot int
+	lastSlotQuery := fmt.Sprintf("SELECT COALESCE(MAX(slot_number), 0) FROM %s WHERE queue_id = $1", SlotBookingsTable)
+	if err := tx.Get(&lastSlot, lastSlotQuery, queue.ID); err != nil {
+		return err
+	}
+	if lastSlot > 0 && !current.KeepsSlots(queue, lastSlot) {
+		return models.ErrSlotBookingsExist
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET title = $1, time_start = $2, time_end = $3, mode = $4, slot_duration = $5, capacity = $6, desks = $7, sequence = sequence + 1, updated_at = NOW() WHERE id = $8 AND %s", QueuesTable, r.inTenant(QueuesTable))
+	result, err := tx.Exec(query, queue.Title, queue.TimeStart, queue.TimeEnd, queue.Mode, queue.SlotDuration, queue.Capacity, queue.Desks, queue.ID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("queue not found")
+	}
+
+	if err := r.setQueueGroups(tx, queue.ID, queue.AllowedGroups); err != nil {
+		return err
+	}
+	if err := r.setQueueOrgUnits(tx, queue.ID, queue.AllowedUnits); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
+// DeleteQueue удаляет очередь и публикует событие отмены для каждого ее участника и записавшегося на слот;
+// отдельные сообщения повторяются независимо, поэтому сбой доставки одному пользователю не задевает остальных
 func (r *PostgresRepository) DeleteQueue(id int) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", QueuesTable)
-	_, err := r.db.Exec(query, id)
-	return err
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	payload, err := r.queuePayload(tx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("queue not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	var userIDs []int
+	usersQuery := fmt.Sprintf(`SELECT user_id FROM %s WHERE queue_id = $1 AND is_active = true
+		UNION SELECT user_id FROM %s WHERE queue_id = $1`, QueueParticipantsTable, SlotBookingsTable)
+	if err := tx.Select(&userIDs, usersQuery, id); err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		payload.UserID = userID
+		if err := r.addOutboxMessage(tx, models.OutboxQueueCancelled, payload); err != nil {
+			return err
+		}
+	}
+
+	// Очередь остается в лентах календаря отмененным событием для тех, кто ее видел
+	tombstoneQuery := fmt.Sprintf(`INSERT INTO %s (id, tenant_id, title, time_start, time_end, sequence, allowed_groups, allowed_org_units, user_ids)
+		SELECT id, tenant_id, COALESCE(title, ''), time_start, time_end, sequence + 1,
+			ARRAY(SELECT group_id FROM %s WHERE queue_id = $1), ARRAY(SELECT org_unit_id FROM %s WHERE queue_id = $1), $2
+		FROM %s WHERE id = $1 AND %s`,
+		DeletedQueuesTable, QueueGroupsTable, QueueOrgUnitsTable, QueuesTable, r.inTenant(QueuesTable))
+	if _, err := tx.Exec(tombstoneQuery, id, pq.Array(userIDs)); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND %s", QueuesTable, r.inTenant(QueuesTable))
+	if _, err := tx.Exec(query, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CloseQueue закрывает очередь для новых участников и публикует событие закрытия
+func (r *PostgresRepository) CloseQueue(id int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("UPDATE %s SET closed_at = NOW(), sequence = sequence + 1, updated_at = NOW() WHERE id = $1 AND closed_at IS NULL AND %s", QueuesTable, r.inTenant(QueuesTable))
+	result, err := tx.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("queue not found or already closed")
+	}
+
+	payload, err := r.queuePayload(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.addOutboxMessage(tx, models.OutboxQueueClosed, payload); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// setQueueGroups заменяет список групп, допущенных в очередь; все группы должны принадлежать арендатору
+func (r *PostgresRepository) setQueueGroups(tx *sqlx.Tx, queueID int, groupIDs []int64) error {
+	if err := r.checkInTenant(tx, GroupTable, "group", groupIDs); err != nil {
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE queue_id = $1", QueueGroupsTable)
+	if _, err := tx.Exec(deleteQuery, queueID); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (queue_id, group_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", QueueGroupsTable)
+	for _, groupID := range groupIDs {
+		if _, err := tx.Exec(insertQuery, queueID, groupID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setQueueOrgUnits заменяет список организационных единиц, допущенных в очередь; все единицы должны принадлежать арендатору
+func (r *PostgresRepository) setQueueOrgUnits(tx *sqlx.Tx, queueID int, unitIDs []int64) error {
+	if err := r.checkInTenant(tx, OrgUnitsTable, "org unit", unitIDs); err != nil {
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE queue_id = $1", QueueOrgUnitsTable)
+	if _, err := tx.Exec(deleteQuery, queueID); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (queue_id, org_unit_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", QueueOrgUnitsTable)
+	for _, unitID := range unitIDs {
+		if _, err := tx.Exec(insertQuery, queueID, unitID); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"fmt"
+	"sso/models"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// recordQueueEvent записывает переход участника очереди в историю в рамках транзакции изменения очереди
+func recordQueueEvent(tx *sqlx.Tx, queueID, userID int, eventType string, position int, waitSeconds *int) error {
+	// Группа сохраняется на момент события, чтобы статистика не менялась при переводе пользователя;
+	// из нескольких групп пользователя выбирается допущенная в очередь.
+	// Арендатор и название очереди копируются в событие, чтобы история пережила удаление очереди
+	query := fmt.Sprintf(`INSERT INTO %s (tenant_id, queue_id, queue_title, user_id, group_id, event_type, position, wait_seconds)
+		SELECT q.tenant_id, q.id, q.title, $2, (
+			SELECT m.group_id FROM %s m WHERE m.user_id = $2
+			ORDER BY m.group_id IN (SELECT group_id FROM %s WHERE queue_id = $1) DESC, m.role = '%s' DESC, m.group_id
+			LIMIT 1
+		), $3, $4, $5 FROM %s q WHERE q.id = $1`, QueueEventsTable, GroupMembershipsTable, QueueGroupsTable, models.GroupRoleMember, QueuesTable)
+	_, err := tx.Exec(query, queueID, userID, eventType, position, waitSeconds)
+	return err
+}
+
+// GetQueueStats возвращает статистику по каждой очереди, в которой были события, попадающие в фильтр
+func (r *PostgresRepository) GetQueueStats(filter models.StatsFilter) ([]models.QueueStats, error) {
+	where, args := r.statsConditions(filter)
+	// Удаленные очереди остаются в статистике под сохраненным в событиях названием
+	query := fmt.Sprintf(`SELECT e.queue_id, e.queue_title AS title,
+			COUNT(*) FILTER (WHERE e.event_type IN ('%s', '%s')) AS joined,
+			COUNT(*) FILTER (WHERE e.event_type IN ('%s', '%s')) AS left_count,
+			COUNT(*) FILTER (WHERE e.event_type = '%s') AS served,
+			COUNT(*) FILTER (WHERE e.event_type = '%s') AS no_show,
+			COALESCE(AVG(e.wait_seconds) FILTER (WHERE e.event_type = '%s'), 0) AS avg_wait_seconds
+		FROM %s e LEFT JOIN %s q ON q.id = e.queue_id
+		%s
+		GROUP BY e.queue_id, e.queue_title ORDER BY MAX(q.time_start) DESC NULLS LAST, MAX(e.created_at) DESC`,
+		models.EventJoined, models.EventBooked, models.EventLeft, models.EventCancelled,
+		models.EventServed, models.EventNoShow, models.EventServed,
+		QueueEventsTable, QueuesTable, where)
+
+	var stats []models.QueueStats
+	if err := r.db.Select(&stats, query, args...); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetGroupAttendance возвращает посещаемость очередей группами по событиям, попадающим в фильтр
+func (r *PostgresRepository) GetGroupAttendance(filter models.StatsFilter) ([]models.GroupAttendance, error) {
+	where, args := r.statsConditions(filter)
+	query := fmt.Sprintf(`SELECT e.group_id, g.code,
+			COUNT(DISTINCT e.user_id) FILTER (WHERE e.event_type = '%s') AS users,
+			COUNT(*) FILTER (WHERE e.event_type = '%s') AS served,
+			COUNT(*) FILTER (WHERE e.event_type = '%s') AS no_show
+		FROM %s e JOIN %s g ON g.id = e.group_id
+		%s
+		GROUP BY e.group_id, g.code ORDER BY served DESC, g.code`,
+		models.EventServed, models.EventServed, models.EventNoShow,
+		QueueEventsTable, GroupTable, where)
+
+	var groups []models.GroupAttendance
+	if err := r.db.Select(&groups, query, args...); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// statsConditions строит условие WHERE и аргументы запроса по фильтру статистики;
+// в статистику всегда попадают только события арендатора, в том числе удаленных очередей
+func (r *PostgresRepository) statsConditions(filter models.StatsFilter) (string, []interface{}) {
+	conditions := []string{r.inTenant("e")}
+	var args []interface{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.QueueID != 0 {
+		add("e.queue_id = $%d", filter.QueueID)
+	}
+	if filter.GroupID != 0 {
+		add("e.group_id = $%d", filter.GroupID)
+	}
+	if filter.OrgUnitID != 0 {
+		add("e.group_id IN (SELECT g.id FROM "+GroupTable+" g WHERE g.org_unit_id IN ("+r.orgUnitSubtree("$%d")+"))", filter.OrgUnitID)
+	}
+	if !filter.From.IsZero() {
+		add("e.created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("e.created_at < $%d", filter.To)
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"fmt"
+	"sso/models"
+)
+
+// userGroupCodes выбирает коды всех групп пользователя u через запятую
+var userGroupCodes = fmt.Sprintf(`COALESCE((SELECT string_agg(g.code, ', ' ORDER BY g.code)
+			FROM %s m JOIN %s g ON g.id = m.group_id WHERE m.user_id = u.id), '')`, GroupMembershipsTable, GroupTable)
+
+// GetParticipantsExport возвращает всех участников очереди арендатора для выгрузки: принятых, неявившихся, ожидающих
+// и записанных на слоты; принятые удаленные пользователи выгружаются без имени
+func (r *PostgresRepository) GetParticipantsExport(queueID int) ([]models.ParticipantExportRow, error) {
+	query := fmt.Sprintf(`SELECT * FROM (
+			SELECT COALESCE(u.username, '') AS username, COALESCE(u.tg_nick, '') AS tg_nick, COALESCE(g.code, '') AS group_code,
+				e.position, e.event_type AS status,
+				e.created_at - make_interval(secs => COALESCE(e.wait_seconds, 0)) AS joined_at, e.created_at AS served_at
+			FROM %[1]s e LEFT JOIN %[2]s u ON u.id = e.user_id LEFT JOIN %[3]s g ON g.id = e.group_id
+			WHERE e.queue_id = $1 AND e.event_type IN ('%[6]s', '%[7]s') AND %[11]s
+			UNION ALL
+			SELECT u.username, u.tg_nick, %[10]s, p.position, '%[8]s', p.joined_at::timestamptz, NULL::timestamptz
+			FROM %[4]s p JOIN %[2]s u ON u.id = p.user_id
+			WHERE p.queue_id = $1 AND p.is_active = true AND %[11]s
+			UNION ALL
+			SELECT u.username, u.tg_nick, %[10]s, b.slot_number, '%[9]s', b.booked_at::timestamptz, NULL::timestamptz
+			FROM %[5]s b JOIN %[2]s u ON u.id = b.user_id
+			WHERE b.queue_id = $1 AND %[11]s
+		) participants ORDER BY served_at NULLS LAST, position`,
+		QueueEventsTable, UserTable, GroupTable, QueueParticipantsTable, SlotBookingsTable,
+		models.EventServed, models.EventNoShow, models.ExportStatusWaiting, models.ExportStatusBooked, userGroupCodes,
+		r.refInTenant("$1::integer", QueuesTable))
+
+	var rows []models.ParticipantExportRow
+	if err := r.db.Select(&rows, query, queueID); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
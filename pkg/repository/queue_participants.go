@@ -1,61 +1,96 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"sso/models"
+	"time"
 )
 
-// JoinQueue добавляет пользователя в очередь
+// JoinQueue добавляет пользователя в очередь; очередь и пользователь должны принадлежать арендатору
 func (r *PostgresRepository) JoinQueue(queueID, userID int) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
 	// Проверяем, не находится ли пользователь уже в очереди
 	var existingID int
-	checkQuery := fmt.Sprintf("SELECT id FROM %s WHERE queue_id = $1 AND user_id = $2 AND is_active = true", QueueParticipantsTable)
-	err := r.db.QueryRow(checkQuery, queueID, userID).Scan(&existingID)
+	checkQuery := fmt.Sprintf("SELECT id FROM %s WHERE queue_id = $1 AND user_id = $2 AND is_active = true AND %s", QueueParticipantsTable, r.inTenant(QueueParticipantsTable))
+	err = tx.QueryRow(checkQuery, queueID, userID).Scan(&existingID)
 	if err == nil {
 		return 0, fmt.Errorf("user is already in queue")
 	}
 
 	// Получаем следующую позицию в очереди
-	position, err := r.GetNextQueuePosition(queueID)
-	if err != nil {
+	var position int
+	positionQuery := fmt.Sprintf("SELECT COALESCE(MAX(position), 0) + 1 FROM %s WHERE queue_id = $1 AND is_active = true AND %s", QueueParticipantsTable, r.inTenant(QueueParticipantsTable))
+	if err := tx.QueryRow(positionQuery, queueID).Scan(&position); err != nil {
 		return 0, err
 	}
 
-	// Добавляем пользователя в очередь
+	// Добавляем пользователя в очередь; после выхода из очереди повторно активируем прежнюю запись.
+	// Внешние ключи с арендатором не дают добавить пользователя в очередь другого арендатора
 	var id int
-	query := fmt.Sprintf("INSERT INTO %s (queue_id, user_id, position) VALUES ($1, $2, $3) RETURNING id", QueueParticipantsTable)
-	err = r.db.QueryRow(query, queueID, userID, position).Scan(&id)
+	query := fmt.Sprintf(`INSERT INTO %s (tenant_id, queue_id, user_id, position) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (queue_id, user_id) DO UPDATE SET position = EXCLUDED.position, joined_at = NOW(), is_active = true, left_at = NULL
+		RETURNING id`, QueueParticipantsTable)
+	err = tx.QueryRow(query, r.tenantID, queueID, userID, position).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
 
+	if err := recordQueueEvent(tx, queueID, userID, models.EventJoined, position, nil); err != nil {
+		return 0, err
+	}
+	if err := r.addParticipantMessage(tx, models.OutboxParticipantJoined, queueID, userID, position, 0); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
 	return id, nil
 }
 
 // LeaveQueue удаляет пользователя из очереди
 func (r *PostgresRepository) LeaveQueue(queueID, userID int) error {
-	query := fmt.Sprintf("UPDATE %s SET is_active = false WHERE queue_id = $1 AND user_id = $2", QueueParticipantsTable)
-	result, err := r.db.Exec(query, queueID, userID)
+	tx, err := r.db.Beginx()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	var position int
+	query := fmt.Sprintf("UPDATE %s SET is_active = false, left_at = NOW() WHERE queue_id = $1 AND user_id = $2 AND is_active = true AND %s RETURNING position",
+		QueueParticipantsTable, r.inTenant(QueueParticipantsTable))
+	err = tx.QueryRow(query, queueID, userID).Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user not found in queue")
+	}
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("user not found in queue")
+	if err := recordQueueEvent(tx, queueID, userID, models.EventLeft, position, nil); err != nil {
+		return err
+	}
+	if err := r.addParticipantMessage(tx, models.OutboxParticipantLeft, queueID, userID, position, 0); err != nil {
+		return err
+	}
+	if err := r.addQueueProgressMessages(tx, queueID, false, position); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 // GetQueueParticipants возвращает всех участников очереди
 func (r *PostgresRepository) GetQueueParticipants(queueID int) ([]models.QueueParticipant, error) {
 	var participants []models.QueueParticipant
-	query := fmt.Sprintf("SELECT id, queue_id, user_id, position, joined_at, is_active FROM %s WHERE queue_id = $1 AND is_active = true ORDER BY position", QueueParticipantsTable)
+	query := fmt.Sprintf("SELECT id, queue_id, user_id, position, joined_at, is_active FROM %s WHERE queue_id = $1 AND is_active = true AND %s ORDER BY position", QueueParticipantsTable, r.inTenant(QueueParticipantsTable))
 	err := r.db.Select(&participants, query, queueID)
 	if err != nil {
 		return nil, err
@@ -67,7 +102,7 @@ func (r *PostgresRepository) GetQueueParticipants(queueID int) ([]models.QueuePa
 // GetUserQueuePosition возвращает позицию пользователя в очереди
 func (r *PostgresRepository) GetUserQueuePosition(queueID, userID int) (int, error) {
 	var position int
-	query := fmt.Sprintf("SELECT position FROM %s WHERE queue_id = $1 AND user_id = $2 AND is_active = true", QueueParticipantsTable)
+	query := fmt.Sprintf("SELECT position FROM %s WHERE queue_id = $1 AND user_id = $2 AND is_active = true AND %s", QueueParticipantsTable, r.inTenant(QueueParticipantsTable))
 	err := r.db.QueryRow(query, queueID, userID).Scan(&position)
 	if err != nil {
 		return 0, err
@@ -76,35 +111,57 @@ func (r *PostgresRepository) GetUserQueuePosition(queueID, userID int) (int, err
 	return position, nil
 }
 
-// ShiftQueue удаляет первого пользователя из очереди и сдвигает остальных
-func (r *PostgresRepository) ShiftQueue(queueID int) error {
+// ShiftQueue удаляет первого пользователя из очереди с указанным итогом (served или no_show) и сдвигает остальных
+func (r *PostgresRepository) ShiftQueue(queueID int, outcome string) error {
 	tx, err := r.db.Beginx()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Удаляем первого пользователя из очереди
-	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE queue_id = $1 AND position = 1 AND is_active = true", QueueParticipantsTable)
-	_, err = tx.Exec(deleteQuery, queueID)
+	// Удаляем первого пользователя из очереди, запоминая время его ожидания
+	var userID, position, waitSeconds int
+	deleteQuery := fmt.Sprintf(`DELETE FROM %[1]s WHERE id = (
+			SELECT id FROM %[1]s WHERE queue_id = $1 AND is_active = true AND %[2]s ORDER BY position LIMIT 1 FOR UPDATE
+		) RETURNING user_id, position, EXTRACT(EPOCH FROM (LOCALTIMESTAMP - joined_at))::integer`, QueueParticipantsTable, r.inTenant(QueueParticipantsTable))
+	err = tx.QueryRow(deleteQuery, queueID).Scan(&userID, &position, &waitSeconds)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Очередь пуста, сдвигать нечего
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
 	// Сдвигаем позиции остальных пользователей
-	updateQuery := fmt.Sprintf("UPDATE %s SET position = position - 1 WHERE queue_id = $1 AND is_active = true", QueueParticipantsTable)
-	_, err = tx.Exec(updateQuery, queueID)
+	updateQuery := fmt.Sprintf("UPDATE %s SET position = position - 1 WHERE queue_id = $1 AND is_active = true AND position > $2", QueueParticipantsTable)
+	_, err = tx.Exec(updateQuery, queueID, position)
 	if err != nil {
 		return err
 	}
 
+	if err := recordQueueEvent(tx, queueID, userID, outcome, position, &waitSeconds); err != nil {
+		return err
+	}
+
+	eventType := models.OutboxParticipantServed
+	if outcome == models.EventNoShow {
+		eventType = models.OutboxParticipantNoShow
+	}
+	if err := r.addParticipantMessage(tx, eventType, queueID, userID, position, waitSeconds); err != nil {
+		return err
+	}
+	if err := r.addQueueProgressMessages(tx, queueID, true, position); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
 // GetNextQueuePosition возвращает следующую позицию в очереди
 func (r *PostgresRepository) GetNextQueuePosition(queueID int) (int, error) {
 	var position int
-	query := fmt.Sprintf("SELECT COALESCE(MAX(position), 0) + 1 FROM %s WHERE queue_id = $1 AND is_active = true", QueueParticipantsTable)
+	query := fmt.Sprintf("SELECT COALESCE(MAX(position), 0) + 1 FROM %s WHERE queue_id = $1 AND is_active = true AND %s", QueueParticipantsTable, r.inTenant(QueueParticipantsTable))
 	err := r.db.QueryRow(query, queueID).Scan(&position)
 	if err != nil {
 		return 0, err
@@ -112,3 +169,22 @@ func (r *PostgresRepository) GetNextQueuePosition(queueID int) (int, error) {
 
 	return position, nil
 }
+
+// GetJoinStats возвращает сведения об участии пользователя в очередях для проверки правил присоединения;
+// присоединения считаются по истории событий, так как повторное присоединение перезаписывает строку участника
+func (r *PostgresRepository) GetJoinStats(queueID, userID int, since time.Time) (models.JoinStats, error) {
+	var stats models.JoinStats
+	query := fmt.Sprintf(`SELECT
+			COUNT(*) FILTER (WHERE is_active) AS active_queues,
+			(SELECT COUNT(*) FROM %s e WHERE e.user_id = $2 AND e.event_type = '%s' AND e.created_at >= $3 AND %s) AS joins_since,
+			MAX(left_at) FILTER (WHERE queue_id = $1 AND NOT is_active) AS last_left_at
+		FROM %s WHERE user_id = $2 AND %s`,
+		QueueEventsTable, models.EventJoined, r.inTenant("e"),
+		QueueParticipantsTable, r.inTenant(QueueParticipantsTable))
+	err := r.db.Get(&stats, query, queueID, userID, since)
+	if err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Имена ограничений уникальности таблицы записей на слоты
+const (
+	slotBookingsSlotUnique = "queue_slot_bookings_slot_unique"
+	slotBookingsUserUnique = "queue_slot_bookings_user_unique"
+)
+
+// BookSlot записывает пользователя на слот очереди; очередь и пользователь должны принадлежать арендатору
+func (r *PostgresRepository) BookSlot(queueID, userID, slot int) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := r.checkSlotInSchedule(tx, queueID, slot); err != nil {
+		return 0, err
+	}
+
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (tenant_id, queue_id, user_id, slot_number) VALUES ($1, $2, $3, $4) RETURNING id", SlotBookingsTable)
+	err = tx.QueryRow(query, r.tenantID, queueID, userID, slot).Scan(&id)
+	if err != nil {
+		return 0, slotBookingError(err)
+	}
+
+	if err := recordQueueEvent(tx, queueID, userID, models.EventBooked, slot, nil); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// RescheduleSlot переносит запись пользователя на другой слот
+func (r *PostgresRepository) RescheduleSlot(queueID, userID, slot int) error {
+	return r.changeSlotBooking(queueID, userID, models.EventRescheduled,
+		fmt.Sprintf("UPDATE %s SET slot_number = $3, booked_at = NOW() WHERE queue_id = $1 AND user_id = $2 AND %s RETURNING slot_number",
+			SlotBookingsTable, r.inTenant(SlotBookingsTable)), slot)
+}
+
+// CancelSlotBooking отменяет запись пользователя на слот
+func (r *PostgresRepository) CancelSlotBooking(queueID, userID int) error {
+	return r.changeSlotBooking(queueID, userID, models.EventCancelled,
+		fmt.Sprintf("DELETE FROM %s WHERE queue_id = $1 AND user_id = $2 AND %s RETURNING slot_number", SlotBookingsTable, r.inTenant(SlotBookingsTable)), 0)
+}
+
+// changeSlotBooking изменяет запись пользователя на слот запросом query и записывает событие в историю;
+// newSlot - номер слота, на который переносится запись (0 - запись отменяется)
+func (r *PostgresRepository) changeSlotBooking(queueID, userID int, eventType, query string, newSlot int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	args := []interface{}{queueID, userID}
+	if newSlot > 0 {
+		if err := r.checkSlotInSchedule(tx, queueID, newSlot); err != nil {
+			return err
+		}
+		args = append(args, newSlot)
+	}
+
+	var slot int
+	err = tx.QueryRow(query, args...).Scan(&slot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("booking not found")
+	}
+	if err != nil {
+		return slotBookingError(err)
+	}
+
+	if err := recordQueueEvent(tx, queueID, userID, eventType, slot, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockSlotSchedule блокирует строку очереди до конца транзакции и возвращает ее расписание слотов;
+// lock - режим блокировки (FOR UPDATE при изменении расписания, FOR SHARE при записи на слот)
+func (r *PostgresRepository) lockSlotSchedule(tx *sqlx.Tx, queueID int, lock string) (models.Queue, error) {
+	var queue models.Queue
+	query := fmt.Sprintf("SELECT mode, time_start, time_end, slot_duration FROM %s WHERE id = $1 AND %s %s", QueuesTable, r.inTenant(QueuesTable), lock)
+	err := tx.Get(&queue, query, queueID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return queue, fmt.Errorf("queue not found")
+	}
+	return queue, err
+}
+
+// checkSlotInSchedule проверяет, что слот есть в текущем расписании очереди; расписание блокируется до конца транзакции,
+// поэтому оно не может измениться между проверкой слота и сохранением записи
+func (r *PostgresRepository) checkSlotInSchedule(tx *sqlx.Tx, queueID, slot int) error {
+	queue, err := r.lockSlotSchedule(tx, queueID, "FOR SHARE")
+	if err != nil {
+		return err
+	}
+	if slot < 1 || slot > queue.SlotCount() {
+		return fmt.Errorf("slot %d does not exist", slot)
+	}
+	return nil
+}
+
+// GetSlotBookings возвращает все записи на слоты очереди вместе с данными пользователей
+func (r *PostgresRepository) GetSlotBookings(queueID int) ([]models.SlotBooking, error) {
+	var bookings []models.SlotBooking
+	query := fmt.Sprintf(`SELECT b.id, b.queue_id, b.user_id, b.slot_number, b.booked_at, u.username, u.tg_nick
+		FROM %s b JOIN %s u ON u.id = b.user_id
+		WHERE b.queue_id = $1 AND %s ORDER BY b.slot_number`, SlotBookingsTable, UserTable, r.inTenant("b"))
+	err := r.db.Select(&bookings, query, queueID)
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// slotBookingError переводит нарушения уникальности в понятные ошибки
+func slotBookingError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23505" {
+		return err
+	}
+
+	switch pqErr.Constraint {
+	case slotBookingsSlotUnique:
+		return fmt.Errorf("slot is already booked")
+	case slotBookingsUserUnique:
+		return fmt.Errorf("user already has a booking in this queue")
+	}
+
+	return err
+}
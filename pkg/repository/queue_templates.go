@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"fmt"
+	"sso/models"
+)
+
+// templateColumns содержит список выбираемых полей шаблона вместе с допущенными группами и организационными единицами
+var templateColumns = fmt.Sprintf("id, title, duration, mode, slot_duration, capacity, desks, created_at, "+
+	"ARRAY(SELECT group_id FROM %[1]s g WHERE g.template_id = %[3]s.id ORDER BY group_id) AS allowed_groups, "+
+	"ARRAY(SELECT org_unit_id FROM %[2]s u WHERE u.template_id = %[3]s.id ORDER BY org_unit_id) AS allowed_org_units",
+	QueueTemplateGroupsTable, QueueTemplateOrgUnitsTable, QueueTemplatesTable)
+
+// CreateQueueTemplate создает шаблон очереди арендатора вместе со списками допущенных групп и организационных единиц
+func (r *PostgresRepository) CreateQueueTemplate(template models.QueueTemplate) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := r.checkInTenant(tx, GroupTable, "group", template.AllowedGroups); err != nil {
+		return 0, err
+	}
+	if err := r.checkInTenant(tx, OrgUnitsTable, "org unit", template.AllowedUnits); err != nil {
+		return 0, err
+	}
+
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (tenant_id, title, duration, mode, slot_duration, capacity, desks) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id", QueueTemplatesTable)
+	err = tx.QueryRow(query, r.tenantID, template.Title, template.Duration, template.Mode, template.SlotDuration, template.Capacity, template.Desks).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	groupsQuery := fmt.Sprintf("INSERT INTO %s (template_id, group_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", QueueTemplateGroupsTable)
+	for _, groupID := range template.AllowedGroups {
+		if _, err := tx.Exec(groupsQuery, id, groupID); err != nil {
+			return 0, err
+		}
+	}
+
+	unitsQuery := fmt.Sprintf("INSERT INTO %s (template_id, org_unit_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", QueueTemplateOrgUnitsTable)
+	for _, unitID := range template.AllowedUnits {
+		if _, err := tx.Exec(unitsQuery, id, unitID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetQueueTemplateByID возвращает шаблон очереди по ID
+func (r *PostgresRepository) GetQueueTemplateByID(id int) (models.QueueTemplate, error) {
+	var template models.QueueTemplate
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 AND %s", templateColumns, QueueTemplatesTable, r.inTenant(QueueTemplatesTable))
+	err := r.db.Get(&template, query, id)
+	if err != nil {
+		return template, err
+	}
+	return template, nil
+}
+
+// GetAllQueueTemplates возвращает все шаблоны очередей
+func (r *PostgresRepository) GetAllQueueTemplates() ([]models.QueueTemplate, error) {
+	var templates []models.QueueTemplate
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY title", templateColumns, QueueTemplatesTable, r.inTenant(QueueTemplatesTable))
+	err := r.db.Select(&templates, query)
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// DeleteQueueTemplate удаляет шаблон очереди
+func (r *PostgresRepository) DeleteQueueTemplate(id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND %s", QueueTemplatesTable, r.inTenant(QueueTemplatesTable))
+	_, err := r.db.Exec(query, id)
+	return err
+}
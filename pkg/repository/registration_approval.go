@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"fmt"
+	"sso/models"
+)
+
+// GetPendingUsers возвращает пользователей группы, ожидающих подтверждения регистрации
+func (r *PostgresRepository) GetPendingUsers(groupID int) ([]models.User, error) {
+	var users []models.User
+	query := fmt.Sprintf(`SELECT %s FROM %s u JOIN %s gm ON gm.user_id = u.id
+		WHERE gm.group_id = $1 AND u.status = $2 AND %s ORDER BY u.id`, userColumns("u"), UserTable, GroupMembershipsTable, r.inTenant("u"))
+	err := r.db.Select(&users, query, groupID, models.UserStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ApproveUser подтверждает регистрацию пользователя группы
+func (r *PostgresRepository) ApproveUser(userID, groupID, approvedBy int) error {
+	query := fmt.Sprintf(`UPDATE %s SET status = $1, approved_by = $2, approved_at = NOW()
+		WHERE id = $3 AND status = $5 AND %s AND EXISTS (SELECT 1 FROM %s WHERE user_id = $3 AND group_id = $4)`,
+		UserTable, r.inTenant(UserTable), GroupMembershipsTable)
+	result, err := r.db.Exec(query, models.UserStatusActive, approvedBy, userID, groupID, models.UserStatusPending)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("pending user not found")
+	}
+	return nil
+}
+
+// RejectUser удаляет неподтвержденного пользователя группы
+func (r *PostgresRepository) RejectUser(userID, groupID int) error {
+	query := fmt.Sprintf(`DELETE FROM %s
+		WHERE id = $1 AND status = $3 AND %s AND EXISTS (SELECT 1 FROM %s WHERE user_id = $1 AND group_id = $2)`,
+		UserTable, r.inTenant(UserTable), GroupMembershipsTable)
+	result, err := r.db.Exec(query, userID, groupID, models.UserStatusPending)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("pending user not found")
+	}
+	return nil
+}
@@ -2,21 +2,53 @@
 package repository
 
 import (
+	"database/sql"
 	"sso/models"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
 
 // Константы с названиями таблиц в базе данных
 const (
-	UserTable              = "users"              // Таблица пользователей
-	GroupTable             = "groups"             // Таблица групп
-	QueuesTable            = "queues"             // Таблица очередей
-	QueueParticipantsTable = "queue_participants" // Таблица участников очередей
+	UserTable                  = "users"                    // Таблица пользователей
+	GroupTable                 = "groups"                   // Таблица групп
+	QueuesTable                = "queues"                   // Таблица очередей
+	QueueParticipantsTable     = "queue_participants"       // Таблица участников очередей
+	SlotBookingsTable          = "queue_slot_bookings"      // Таблица записей на слоты
+	QueueGroupsTable           = "queue_groups"             // Таблица групп, допущенных в очереди
+	QueueTemplatesTable        = "queue_templates"          // Таблица шаблонов очередей
+	QueueTemplateGroupsTable   = "queue_template_groups"    // Таблица групп, допущенных в очереди по шаблону
+	QueueEventsTable           = "queue_events"             // Таблица истории событий участников очередей
+	TelegramLinkCodesTable     = "telegram_link_codes"      // Таблица кодов привязки чатов Telegram
+	OutboxMessagesTable        = "outbox_messages"          // Таблица исходящих сообщений (outbox)
+	WebhooksTable              = "webhooks"                 // Таблица webhook-подписок
+	WebhookDeliveriesTable     = "webhook_deliveries"       // Таблица журнала доставки webhook
+	PasswordResetTokensTable   = "password_reset_tokens"    // Таблица токенов сброса пароля
+	PasswordResetCodesTable    = "password_reset_codes"     // Таблица кодов сброса пароля через Telegram
+	RateLimitBucketsTable      = "rate_limit_buckets"       // Таблица корзин ограничения частоты входа
+	RecoveryCodesTable         = "recovery_codes"           // Таблица резервных кодов двухфакторной аутентификации
+	APIKeysTable               = "api_keys"                 // Таблица персональных API-ключей
+	SessionsTable              = "sessions"                 // Таблица сессий пользователей
+	GroupMembershipsTable      = "group_memberships"        // Таблица членства пользователей в группах
+	InvitationsTable           = "invitations"              // Таблица приглашений для регистрации
+	OrgUnitsTable              = "org_units"                // Таблица организационных единиц (факультеты и кафедры)
+	OrgUnitAdminsTable         = "org_unit_admins"          // Таблица администраторов организационных единиц
+	QueueOrgUnitsTable         = "queue_org_units"          // Таблица организационных единиц, допущенных в очереди
+	TenantsTable               = "tenants"                  // Таблица арендаторов
+	SignInFailuresTable        = "sign_in_failures"         // Таблица неудачных попыток входа и блокировок по никам
+	QueueTemplateOrgUnitsTable = "queue_template_org_units" // Таблица организационных единиц, допущенных в очереди по шаблону
+	DeletedQueuesTable         = "deleted_queues"           // Таблица удаленных очередей для отмененных событий календаря
 )
 
 // Repository определяет интерфейс для работы с базой данных
 type Repository interface {
+	// Методы для работы с арендаторами
+	ForTenant(tenantID int) Repository                                         // Репозиторий, ограниченный данными арендатора
+	CreateTenant(tenant models.Tenant, admin models.RegisterUser) (int, error) // Создание арендатора и его администратора
+	GetTenants() ([]models.Tenant, error)                                      // Все арендаторы
+	GetTenantByCode(code string) (models.Tenant, error)                        // Получение арендатора по коду
+
 	// Методы для работы с пользователями
 	CreateUser(user models.RegisterUser, groupID int) (int, error) // Создание пользователя
 	GetUserByID(id int) (models.User, error)                       // Получение пользователя по ID
@@ -27,6 +59,88 @@ type Repository interface {
 	UpdateUser(id int, user models.User) error                     // Обновление пользователя
 	DeleteUser(id int) error                                       // Удаление пользователя
 
+	// Методы для работы с паролями
+	UpdatePasswordHash(userID int, passwordHash string) error                                                       // Сохранение нового хеша пароля
+	CreatePasswordResetToken(userID, createdBy int, tokenHash string, expiresAt time.Time) error                    // Сохранение токена сброса пароля
+	ResetPasswordByToken(tokenHash, passwordHash string) (int, error)                                               // Установка пароля по токену сброса
+	CreatePasswordResetCode(userID int, codeHash string, expiresAt time.Time, interval time.Duration) (bool, error) // Сохранение кода сброса пароля
+	UsePasswordResetCodeAttempt(userID, maxAttempts int) (string, error)                                            // Учет попытки ввода кода сброса
+	ResetPasswordByCode(userID int, codeHash, passwordHash string) error                                            // Установка пароля по коду сброса
+
+	// Методы для защиты входа от перебора паролей
+	GetSignInLockedUntil(tgNick string) (sql.NullTime, error)                                        // Время окончания блокировки входа по нику
+	RecordSignInFailure(tgNick string, maxFailures int, lockout time.Duration) (sql.NullTime, error) // Учет неудачной попытки входа
+	ResetSignInFailures(userID int) error                                                            // Сброс неудачных попыток и снятие блокировки
+	TakeRateLimitToken(key string, rate float64, burst int) (bool, time.Duration, error)             // Токен из общей корзины ограничения частоты
+
+	// Методы для работы с двухфакторной аутентификацией
+	GetTwoFactorState(userID int) (models.TwoFactorState, error)       // Настройки TOTP пользователя
+	SetTOTPSecret(userID int, secret string) error                     // Сохранение неподтвержденного секрета TOTP
+	EnableTwoFactor(userID int, step int64, codeHashes []string) error // Подтверждение TOTP и выдача резервных кодов
+	DisableTwoFactor(userID int) error                                 // Отключение TOTP
+	UseTOTPStep(userID int, step int64) (bool, error)                  // Отметка использованного временного шага
+	UseRecoveryCode(userID int, codeHash string) (bool, error)         // Погашение резервного кода
+	ReplaceRecoveryCodes(userID int, codeHashes []string) error        // Замена резервных кодов
+
+	// Методы для работы с API-ключами
+	CreateAPIKey(key models.APIKey) (int, error)            // Сохранение API-ключа
+	GetAPIKeyByID(id int) (models.APIKey, error)            // Получение API-ключа по ID
+	GetAPIKeyByPrefix(prefix string) (models.APIKey, error) // Получение API-ключа по открытой части
+	GetAPIKeys(userID int) ([]models.APIKey, error)         // API-ключи пользователя (0 - всех пользователей)
+	RevokeAPIKey(id int) error                              // Отзыв API-ключа
+	TouchAPIKey(id int) error                               // Отметка об использовании API-ключа
+
+	// Методы для работы с сессиями
+	CreateSession(session models.Session) (int, error)          // Сохранение сессии
+	GetActiveSession(id, userID int) (models.Session, error)    // Получение активной сессии пользователя
+	GetActiveSessions(userID int) ([]models.Session, error)     // Активные сессии пользователя
+	TouchSession(id int) error                                  // Обновление времени последнего запроса
+	RevokeSession(id, userID int) error                         // Отзыв сессии пользователя
+	RevokeUserSessions(userID int) (int, error)                 // Отзыв всех сессий пользователя
+	RevokeOtherSessions(userID, keepID int) (int, error)        // Отзыв всех сессий пользователя, кроме указанной
+	SignOutEverywhere(userID int) (models.SignOutResult, error) // Отзыв сессий и API-ключей и отвязка чата бота
+
+	// Методы для работы с модераторами групп и приглашениями
+	AddGroupModerator(groupID, userID int) error                                                           // Назначение модератора группы
+	RemoveGroupModerator(groupID, userID int) error                                                        // Снятие модератора группы
+	GetGroupModerators(groupID int) ([]models.User, error)                                                 // Модераторы группы
+	IsGroupModerator(userID, groupID int) (bool, error)                                                    // Проверка роли модератора
+	CreateInvitation(invitation models.Invitation, codeHash string) (int, error)                           // Сохранение приглашения
+	GetInvitations(groupID int) ([]models.Invitation, error)                                               // Приглашения группы
+	RevokeInvitation(id, groupID int) error                                                                // Отзыв приглашения
+	CreateUserByInvitation(user models.RegisterUser, codeHash string, tgUserID sql.NullInt64) (int, error) // Регистрация по приглашению
+
+	// Методы для работы с участниками групп
+	GetGroupMembers(groupID int) ([]models.GroupMember, error)    // Участники группы с ролями
+	AddGroupMember(groupID, userID int) error                     // Добавление участника группы
+	RemoveGroupMember(groupID, userID int) error                  // Исключение участника из группы
+	TransferGroupMember(userID, fromGroupID, toGroupID int) error // Перевод участника в другую группу
+
+	// Методы для подтверждения регистрации
+	GetPendingUsers(groupID int) ([]models.User, error) // Неподтвержденные пользователи группы
+	ApproveUser(userID, groupID, approvedBy int) error  // Подтверждение регистрации
+	RejectUser(userID, groupID int) error               // Удаление неподтвержденного пользователя
+
+	// Методы для работы с организационной структурой
+	CreateOrgUnit(unit models.OrgUnit) (int, error)           // Создание факультета или кафедры
+	GetOrgUnitByID(id int) (models.OrgUnit, error)            // Получение единицы по ID
+	GetOrgUnits() ([]models.OrgUnit, error)                   // Все единицы
+	GetOrgUnitChildren(id int) ([]models.OrgUnit, error)      // Дочерние единицы
+	GetOrgUnitGroups(id int) ([]models.Group, error)          // Группы, входящие непосредственно в единицу
+	UpdateOrgUnit(unit models.OrgUnit) error                  // Обновление единицы
+	DeleteOrgUnit(id int) error                               // Удаление пустой единицы
+	GetOrgUnitPath(id int) ([]int64, error)                   // Единица и все ее предки
+	GetGroupOrgUnits(groupID int) ([]int64, error)            // Единицы, в поддерево которых входит группа
+	SetGroupOrgUnit(groupID int, unitID *int) error           // Перенос группы в единицу
+	AddOrgUnitAdmin(unitID, userID int) error                 // Назначение администратора единицы
+	RemoveOrgUnitAdmin(unitID, userID int) error              // Снятие администратора единицы
+	GetOrgUnitAdmins(unitID int) ([]models.User, error)       // Администраторы единицы
+	IsOrgUnitAdmin(userID int, unitIDs []int64) (bool, error) // Проверка прав администратора хотя бы одной из единиц
+
+	// Методы для массового импорта пользователей
+	GetExistingTgNicks(tgNicks []string) ([]string, error)                                         // Уже занятые ники из списка
+	ImportUsers(rows []models.ImportUserRow, createdBy int, expiresAt time.Time) ([]string, error) // Создание групп, пользователей и токенов активации
+
 	// Методы для работы с группами
 	CreateGroup(code, comment string) (int, error)    // Создание группы
 	GetGroupByID(id int) (models.Group, error)        // Получение группы по ID
@@ -36,22 +150,80 @@ type Repository interface {
 	DeleteGroup(id int) error                         // Удаление группы
 
 	// Методы для работы с очередями
-	CreateQueue(queue models.Queue) (int, error) // Создание очереди
-	GetQueueByID(id int) (models.Queue, error)   // Получение очереди по ID
-	GetAllQueues() ([]models.Queue, error)       // Получение всех очередей
-	UpdateQueue(queue models.Queue) error        // Обновление очереди
-	DeleteQueue(id int) error                    // Удаление очереди
+	CreateQueue(queue models.Queue) (int, error)       // Создание очереди
+	CreateQueues(queues []models.Queue) ([]int, error) // Создание нескольких очередей в одной транзакции
+	GetQueueByID(id int) (models.Queue, error)         // Получение очереди по ID
+	GetAllQueues() ([]models.Queue, error)             // Получение всех очередей
+	UpdateQueue(queue models.Queue) error              // Обновление очереди
+	DeleteQueue(id int) error                          // Удаление очереди
+	CloseQueue(id int) error                           // Закрытие очереди для новых участников
+
+	// Методы для работы с шаблонами очередей
+	CreateQueueTemplate(template models.QueueTemplate) (int, error) // Создание шаблона очереди
+	GetQueueTemplateByID(id int) (models.QueueTemplate, error)      // Получение шаблона по ID
+	GetAllQueueTemplates() ([]models.QueueTemplate, error)          // Получение всех шаблонов
+	DeleteQueueTemplate(id int) error                               // Удаление шаблона
 
 	// Методы для работы с участниками очередей
-	JoinQueue(queueID, userID int) (int, error)                          // Присоединение к очереди
-	LeaveQueue(queueID, userID int) error                                // Покидание очереди
-	GetQueueParticipants(queueID int) ([]models.QueueParticipant, error) // Получение участников очереди
-	GetUserQueuePosition(queueID, userID int) (int, error)               // Получение позиции пользователя в очереди
-	ShiftQueue(queueID int) error                                        // Сдвиг очереди
-	GetNextQueuePosition(queueID int) (int, error)                       // Получение следующей позиции в очереди
+	JoinQueue(queueID, userID int) (int, error)                                  // Присоединение к очереди
+	LeaveQueue(queueID, userID int) error                                        // Покидание очереди
+	GetQueueParticipants(queueID int) ([]models.QueueParticipant, error)         // Получение участников очереди
+	GetUserQueuePosition(queueID, userID int) (int, error)                       // Получение позиции пользователя в очереди
+	ShiftQueue(queueID int, outcome string) error                                // Сдвиг очереди с итогом приема первого участника
+	GetNextQueuePosition(queueID int) (int, error)                               // Получение следующей позиции в очереди
+	GetJoinStats(queueID, userID int, since time.Time) (models.JoinStats, error) // Сведения для проверки правил присоединения
+	GetParticipantsExport(queueID int) ([]models.ParticipantExportRow, error)    // Участники очереди для выгрузки
+
+	// Методы для работы с записями на слоты
+	BookSlot(queueID, userID, slot int) (int, error)           // Запись на слот
+	RescheduleSlot(queueID, userID, slot int) error            // Перенос записи на другой слот
+	CancelSlotBooking(queueID, userID int) error               // Отмена записи на слот
+	GetSlotBookings(queueID int) ([]models.SlotBooking, error) // Получение всех записей на слоты очереди
+
+	// Методы для работы с лентами календаря
+	HasCalendarToken(userID int) (bool, error)                       // Проверка, выдан ли токен ленты календаря
+	SetCalendarToken(userID int, tokenHash string) error             // Сохранение хеша токена ленты календаря
+	GetUserByCalendarToken(tokenHash string) (models.User, error)    // Получение пользователя по хешу токена ленты
+	GetDeletedQueues(since time.Time) ([]models.DeletedQueue, error) // Удаленные очереди для отмененных событий календаря
+	GetUserQueueIDs(userID int) ([]int, error)                       // ID очередей, в которых участвует пользователь
+	GetUserSlotBookings(userID int) ([]models.SlotBooking, error)    // Записи пользователя на слоты
+
+	// Методы для работы с Telegram
+	CreateTelegramLinkCode(userID int, code string, expiresAt time.Time) error             // Сохранение кода привязки чата
+	LinkTelegramChat(code string, chatID int64) (int, error)                               // Привязка чата по коду
+	UnlinkTelegramChat(userID int) error                                                   // Отвязка чата Telegram от пользователя
+	GetTelegramChatID(userID int) (int64, error)                                           // Получение чата Telegram пользователя
+	GetUserByTelegramChat(chatID int64) (models.User, error)                               // Получение пользователя по чату Telegram
+	GetUserByTelegramID(tgUserID int64) (models.User, error)                               // Получение пользователя по аккаунту Telegram
+	LinkTelegramUser(userID int, tgUserID int64) error                                     // Подключение входа через Telegram
+	CreateTelegramUser(user models.RegisterUser, groupID int, tgUserID int64) (int, error) // Создание пользователя, входящего через Telegram
+
+	// Методы для работы с outbox
+	ClaimOutboxMessages(limit int, lease time.Duration) ([]models.OutboxMessage, error)                    // Выборка сообщений, готовых к доставке
+	CompleteOutboxMessage(id int64, deliveredSinks []string) error                                         // Отметка о доставке сообщения
+	RetryOutboxMessage(id int64, deliveredSinks []string, nextAttemptAt time.Time, lastError string) error // Планирование повторной доставки
+	DeadLetterOutboxMessage(id int64, deliveredSinks []string, lastError string) error                     // Перевод сообщения в dead-letter
+	GetOutboxMessages(status string, limit int) ([]models.OutboxMessage, error)                            // Получение сообщений в указанном состоянии
+	RequeueOutboxMessage(id int64) error                                                                   // Повторная постановка сообщения из dead-letter
+	PruneOutboxMessages(deliveredBefore time.Time) (int, error)                                            // Удаление доставленных сообщений старше срока хранения
+
+	// Методы для работы с webhook-подписками
+	CreateWebhook(webhook models.Webhook) (int, error)                           // Создание подписки
+	GetWebhookByID(id int) (models.Webhook, error)                               // Получение подписки по ID
+	GetAllWebhooks() ([]models.Webhook, error)                                   // Получение всех подписок
+	GetActiveWebhooks(eventType string) ([]models.Webhook, error)                // Получение активных подписок на событие
+	DeleteWebhook(id int) error                                                  // Удаление подписки
+	AddWebhookDelivery(delivery models.WebhookDelivery) error                    // Запись попытки доставки
+	HasSuccessfulWebhookDelivery(webhookID int, messageID int64) (bool, error)   // Проверка успешной доставки сообщения
+	GetWebhookDeliveries(webhookID, limit int) ([]models.WebhookDelivery, error) // Журнал доставки подписки
+
+	// Методы для работы со статистикой
+	GetQueueStats(filter models.StatsFilter) ([]models.QueueStats, error)           // Статистика по очередям
+	GetGroupAttendance(filter models.StatsFilter) ([]models.GroupAttendance, error) // Посещаемость по группам
 }
 
-// NewRepository создает новый экземпляр PostgreSQL репозитория
+// NewRepository создает новый экземпляр PostgreSQL репозитория; данные арендаторов доступны
+// только через репозиторий, полученный из ForTenant
 func NewRepository(db *sqlx.DB) *PostgresRepository {
 	return &PostgresRepository{db: db}
 }
@@ -6,12 +6,45 @@ import (
 	"sso/models"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // PostgresRepository реализует интерфейс Repository для PostgreSQL
 type PostgresRepository struct {
-	db *sqlx.DB // Подключение к базе данных PostgreSQL
+	db       *sqlx.DB // Подключение к базе данных PostgreSQL
+	tenantID int      // Арендатор, которым ограничены запросы (0 - данные арендаторов недоступны)
+}
+
+// ForTenant возвращает репозиторий, все запросы которого ограничены данными арендатора
+func (r *PostgresRepository) ForTenant(tenantID int) Repository {
+	return &PostgresRepository{db: r.db, tenantID: tenantID}
+}
+
+// inTenant возвращает условие отбора строк таблицы (или псевдонима) table, принадлежащих арендатору репозитория
+func (r *PostgresRepository) inTenant(table string) string {
+	return fmt.Sprintf("%s.tenant_id = %d", table, r.tenantID)
+}
+
+// refInTenant возвращает условие, что column ссылается на строку таблицы table арендатора репозитория
+func (r *PostgresRepository) refInTenant(column, table string) string {
+	return fmt.Sprintf("%s IN (SELECT id FROM %s WHERE tenant_id = %d)", column, table, r.tenantID)
+}
+
+// checkInTenant проверяет, что все ids ссылаются на строки таблицы table арендатора репозитория;
+// иначе возвращает ошибку "<name> not found"
+func (r *PostgresRepository) checkInTenant(db sqlx.Queryer, table, name string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var foreign bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM unnest($1::integer[]) AS x(id) WHERE NOT %s)", r.refInTenant("x.id", table))
+	if err := sqlx.Get(db, &foreign, query, pq.Array(ids)); err != nil {
+		return err
+	}
+	if foreign {
+		return fmt.Errorf("%s not found", name)
+	}
+	return nil
 }
 
 // NewPostgresDB создает новое подключение к PostgreSQL базе данных
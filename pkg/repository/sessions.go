@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sso/models"
+)
+
+// sessionColumns содержит список выбираемых полей сессии
+const sessionColumns = "id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at"
+
+// CreateSession сохраняет сессию пользователя арендатора и возвращает ее ID
+func (r *PostgresRepository) CreateSession(session models.Session) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, user_agent, ip, expires_at)
+		SELECT $1::integer, $2, $3, $4 WHERE %s RETURNING id`, SessionsTable, r.refInTenant("$1::integer", UserTable))
+	err := r.db.QueryRow(query, session.UserID, session.UserAgent, session.IP, session.ExpiresAt).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("user not found")
+	}
+	return id, err
+}
+
+// GetActiveSession возвращает неотозванную и неистекшую сессию пользователя вместе с его статусом
+func (r *PostgresRepository) GetActiveSession(id, userID int) (models.Session, error) {
+	var session models.Session
+	query := fmt.Sprintf(`SELECT s.id, s.user_id, s.user_agent, s.ip, s.created_at, s.last_seen_at, s.expires_at, s.revoked_at,
+			u.status AS user_status
+		FROM %s s JOIN %s u ON u.id = s.user_id
+		WHERE s.id = $1 AND s.user_id = $2 AND s.revoked_at IS NULL AND s.expires_at > NOW() AND %s`, SessionsTable, UserTable, r.inTenant("u"))
+	err := r.db.Get(&session, query, id, userID)
+	return session, err
+}
+
+// GetActiveSessions возвращает активные сессии пользователя, начиная с последней использованной
+func (r *PostgresRepository) GetActiveSessions(userID int) ([]models.Session, error) {
+	var sessions []models.Session
+	query := fmt.Sprintf(`SELECT %s FROM %s
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW() AND %s
+		ORDER BY last_seen_at DESC`, sessionColumns, SessionsTable, r.refInTenant("user_id", UserTable))
+	err := r.db.Select(&sessions, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// TouchSession обновляет время последнего запроса с токеном сессии
+func (r *PostgresRepository) TouchSession(id int) error {
+	query := fmt.Sprintf("UPDATE %s SET last_seen_at = NOW() WHERE id = $1 AND %s", SessionsTable, r.refInTenant("user_id", UserTable))
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// RevokeSession отзывает активную сессию пользователя
+func (r *PostgresRepository) RevokeSession(id, userID int) error {
+	query := fmt.Sprintf(`UPDATE %s SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW() AND %s`, SessionsTable, r.refInTenant("user_id", UserTable))
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("session not found")
+	}
+	return nil
+}
+
+// RevokeUserSessions отзывает все активные сессии пользователя и возвращает их количество
+func (r *PostgresRepository) RevokeUserSessions(userID int) (int, error) {
+	query := fmt.Sprintf(`UPDATE %s SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW() AND %s`, SessionsTable, r.refInTenant("user_id", UserTable))
+	result, err := r.db.Exec(query, userID)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// SignOutEverywhere в одной транзакции отзывает все сессии и API-ключи пользователя и отвязывает его чат Telegram бота
+func (r *PostgresRepository) SignOutEverywhere(userID int) (models.SignOutResult, error) {
+	var result models.SignOutResult
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	// Отвязка чата заодно проверяет, что пользователь принадлежит арендатору
+	var linked bool
+	userQuery := fmt.Sprintf(`UPDATE %[1]s u SET tg_chat_id = NULL FROM %[1]s prev
+		WHERE u.id = $1 AND prev.id = u.id AND %[2]s RETURNING prev.tg_chat_id IS NOT NULL`, UserTable, r.inTenant("u"))
+	if err := tx.Get(&linked, userQuery, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return result, fmt.Errorf("user not found")
+		}
+		return result, err
+	}
+	result.TelegramUnlinked = linked
+
+	sessionsQuery := fmt.Sprintf("UPDATE %s SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()", SessionsTable)
+	sessions, err := tx.Exec(sessionsQuery, userID)
+	if err != nil {
+		return result, err
+	}
+	revokedSessions, err := sessions.RowsAffected()
+	if err != nil {
+		return result, err
+	}
+	result.Sessions = int(revokedSessions)
+
+	keysQuery := fmt.Sprintf("UPDATE %s SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", APIKeysTable)
+	keys, err := tx.Exec(keysQuery, userID)
+	if err != nil {
+		return result, err
+	}
+	revokedKeys, err := keys.RowsAffected()
+	if err != nil {
+		return result, err
+	}
+	result.APIKeys = int(revokedKeys)
+
+	return result, tx.Commit()
+}
+
+// RevokeOtherSessions отзывает все активные сессии пользователя, кроме keepID, и возвращает их количество
+func (r *PostgresRepository) RevokeOtherSessions(userID, keepID int) (int, error) {
+	query := fmt.Sprintf(`UPDATE %s SET revoked_at = NOW()
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL AND expires_at > NOW() AND %s`, SessionsTable, r.refInTenant("user_id", UserTable))
+	result, err := r.db.Exec(query, userID, keepID)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/pkg/ratelimit"
+	"time"
+)
+
+// signInFailuresRetention задает, сколько хранится счетчик ника без новых неудачных попыток и без блокировки
+const signInFailuresRetention = 24 * time.Hour
+
+// GetSignInLockedUntil возвращает время окончания блокировки входа по нормализованному Telegram нику арендатора;
+// блокировка ведется и для ников, которых нет среди пользователей
+func (r *PostgresRepository) GetSignInLockedUntil(tgNick string) (sql.NullTime, error) {
+	var lockedUntil sql.NullTime
+	query := fmt.Sprintf("SELECT locked_until FROM %s WHERE tenant_id = $1 AND tg_nick = $2", SignInFailuresTable)
+	err := r.db.Get(&lockedUntil, query, r.tenantID, tgNick)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sql.NullTime{}, nil
+	}
+	return lockedUntil, err
+}
+
+// RecordSignInFailure учитывает неудачную попытку входа по нормализованному Telegram нику независимо от того,
+// существует ли пользователь; после maxFailures попыток подряд блокирует вход на lockout и сбрасывает счетчик.
+// Возвращает время окончания блокировки
+func (r *PostgresRepository) RecordSignInFailure(tgNick string, maxFailures int, lockout time.Duration) (sql.NullTime, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	defer tx.Rollback()
+
+	var result struct {
+		LockedUntil sql.NullTime `db:"locked_until"`
+		Inserted    bool         `db:"inserted"`
+	}
+	query := fmt.Sprintf(`INSERT INTO %[1]s AS f (tenant_id, tg_nick, failures, locked_until, updated_at)
+		VALUES ($1, $2, CASE WHEN 1 >= $3 THEN 0 ELSE 1 END, CASE WHEN 1 >= $3 THEN NOW() + $4 * INTERVAL '1 second' END, NOW())
+		ON CONFLICT (tenant_id, tg_nick) DO UPDATE SET
+			failures = CASE WHEN f.failures + 1 >= $3 THEN 0 ELSE f.failures + 1 END,
+			locked_until = CASE WHEN f.failures + 1 >= $3 THEN NOW() + $4 * INTERVAL '1 second' ELSE f.locked_until END,
+			updated_at = NOW()
+		RETURNING f.locked_until, (xmax = 0) AS inserted`, SignInFailuresTable)
+	if err := tx.Get(&result, query, r.tenantID, tgNick, maxFailures, lockout.Seconds()); err != nil {
+		return sql.NullTime{}, err
+	}
+
+	if result.Inserted {
+		// Вместе с новым счетчиком удаляем давно не обновлявшиеся счетчики без действующей блокировки
+		cleanupQuery := fmt.Sprintf(`DELETE FROM %s WHERE updated_at < NOW() - $1 * INTERVAL '1 second'
+			AND (locked_until IS NULL OR locked_until < NOW())`, SignInFailuresTable)
+		if _, err := tx.Exec(cleanupQuery, signInFailuresRetention.Seconds()); err != nil {
+			return sql.NullTime{}, err
+		}
+	}
+	return result.LockedUntil, tx.Commit()
+}
+
+// ResetSignInFailures сбрасывает счетчик неудачных попыток и снимает блокировку входа по нику пользователя
+func (r *PostgresRepository) ResetSignInFailures(userID int) error {
+	var tgNick string
+	userQuery := fmt.Sprintf("SELECT LOWER(tg_nick) FROM %s WHERE id = $1 AND %s", UserTable, r.inTenant(UserTable))
+	if err := r.db.Get(&tgNick, userQuery, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user not found")
+		}
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE tenant_id = $1 AND tg_nick = $2", SignInFailuresTable)
+	_, err := r.db.Exec(query, r.tenantID, tgNick)
+	return err
+}
+
+// TakeRateLimitToken забирает токен из корзины ограничения частоты, общей для всех экземпляров приложения;
+// арендатор, если он нужен, входит в ключ корзины
+func (r *PostgresRepository) TakeRateLimitToken(key string, rate float64, burst int) (bool, time.Duration, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback()
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (key, tokens, updated_at) VALUES ($1, $2, NOW()) ON CONFLICT (key) DO NOTHING", RateLimitBucketsTable)
+	result, err := tx.Exec(insertQuery, key, burst)
+	if err != nil {
+		return false, 0, err
+	}
+	if created, err := result.RowsAffected(); err == nil && created > 0 {
+		// Вместе с новой корзиной удаляем корзины, которые полностью восстановились
+		cleanupQuery := fmt.Sprintf("DELETE FROM %s WHERE updated_at < NOW() - $1 * INTERVAL '1 second'", RateLimitBucketsTable)
+		if _, err := tx.Exec(cleanupQuery, float64(burst)/rate); err != nil {
+			return false, 0, err
+		}
+	}
+
+	var bucket struct {
+		Tokens  float64 `db:"tokens"`
+		Elapsed float64 `db:"elapsed"`
+	}
+	selectQuery := fmt.Sprintf("SELECT tokens, EXTRACT(EPOCH FROM NOW() - updated_at)::float8 AS elapsed FROM %s WHERE key = $1 FOR UPDATE", RateLimitBucketsTable)
+	if err := tx.Get(&bucket, selectQuery, key); err != nil {
+		return false, 0, err
+	}
+
+	tokens, allowed, wait := ratelimit.Refill(bucket.Tokens, time.Duration(bucket.Elapsed*float64(time.Second)), rate, burst)
+	updateQuery := fmt.Sprintf("UPDATE %s SET tokens = $1, updated_at = NOW() WHERE key = $2", RateLimitBucketsTable)
+	if _, err := tx.Exec(updateQuery, tokens, key); err != nil {
+		return false, 0, err
+	}
+
+	return allowed, wait, tx.Commit()
+}
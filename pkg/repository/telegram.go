@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/models"
+	"time"
+)
+
+// CreateTelegramLinkCode сохраняет код привязки чата пользователя арендатора, заменяя ранее выданные коды пользователя
+func (r *PostgresRepository) CreateTelegramLinkCode(userID int, code string, expiresAt time.Time) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 OR expires_at < NOW()", TelegramLinkCodesTable)
+	if _, err := tx.Exec(deleteQuery, userID); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (code, user_id, expires_at) SELECT $1, $2::integer, $3 WHERE %s",
+		TelegramLinkCodesTable, r.refInTenant("$2::integer", UserTable))
+	result, err := tx.Exec(insertQuery, code, userID, expiresAt)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return tx.Commit()
+}
+
+// LinkTelegramChat погашает код привязки и сохраняет чат за пользователем; возвращает ID пользователя.
+// Бот общий для всех арендаторов, поэтому код ищется среди всех арендаторов. Чат привязывается только к одному
+// пользователю во всем приложении: привязка другого пользователя того же арендатора снимается, а чат, привязанный
+// в другом арендаторе, не перепривязывается, чтобы пользователь одного арендатора не отключал уведомления другого
+func (r *PostgresRepository) LinkTelegramChat(code string, chatID int64) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var userID, tenantID int
+	codeQuery := fmt.Sprintf(`DELETE FROM %s c USING %s u WHERE c.code = $1 AND c.expires_at > NOW() AND u.id = c.user_id
+		RETURNING c.user_id, u.tenant_id`, TelegramLinkCodesTable, UserTable)
+	err = tx.QueryRow(codeQuery, code).Scan(&userID, &tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("link code is invalid or expired")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var linkedElsewhere bool
+	conflictQuery := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE tg_chat_id = $1 AND tenant_id <> $2)", UserTable)
+	if err := tx.Get(&linkedElsewhere, conflictQuery, chatID, tenantID); err != nil {
+		return 0, err
+	}
+	if linkedElsewhere {
+		return 0, models.ErrTelegramChatLinkedElsewhere
+	}
+
+	unlinkQuery := fmt.Sprintf("UPDATE %s SET tg_chat_id = NULL WHERE tg_chat_id = $1 AND id <> $2 AND tenant_id = $3", UserTable)
+	if _, err := tx.Exec(unlinkQuery, chatID, userID, tenantID); err != nil {
+		return 0, err
+	}
+
+	linkQuery := fmt.Sprintf("UPDATE %s SET tg_chat_id = $1 WHERE id = $2", UserTable)
+	if _, err := tx.Exec(linkQuery, chatID, userID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// UnlinkTelegramChat отвязывает чат Telegram от пользователя арендатора
+func (r *PostgresRepository) UnlinkTelegramChat(userID int) error {
+	query := fmt.Sprintf("UPDATE %s SET tg_chat_id = NULL WHERE id = $1 AND %s", UserTable, r.inTenant(UserTable))
+	result, err := r.db.Exec(query, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// GetTelegramChatID возвращает чат Telegram пользователя или 0, если чат не привязан
+func (r *PostgresRepository) GetTelegramChatID(userID int) (int64, error) {
+	var chatID int64
+	query := fmt.Sprintf("SELECT COALESCE(tg_chat_id, 0) FROM %s WHERE id = $1 AND %s", UserTable, r.inTenant(UserTable))
+	err := r.db.Get(&chatID, query, userID)
+	return chatID, err
+}
+
+// GetUserByTelegramChat возвращает пользователя, к которому привязан чат Telegram; поиск идет среди всех арендаторов,
+// арендатор пользователя возвращается в TenantID
+func (r *PostgresRepository) GetUserByTelegramChat(chatID int64) (models.User, error) {
+	var user models.User
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE tg_chat_id = $1", userColumns(UserTable), UserTable)
+	err := r.db.Get(&user, query, chatID)
+	return user, err
+}
+
+// GetUserByTelegramID возвращает пользователя, подтвердившего аккаунт Telegram
+func (r *PostgresRepository) GetUserByTelegramID(tgUserID int64) (models.User, error) {
+	var user models.User
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE tg_user_id = $1 AND %s", userColumns(UserTable), UserTable, r.inTenant(UserTable))
+	err := r.db.Get(&user, query, tgUserID)
+	return user, err
+}
+
+// LinkTelegramUser привязывает аккаунт Telegram к пользователю, у которого он еще не подключен
+func (r *PostgresRepository) LinkTelegramUser(userID int, tgUserID int64) error {
+	query := fmt.Sprintf("UPDATE %s SET tg_user_id = $1 WHERE id = $2 AND tg_user_id IS NULL AND %s", UserTable, r.inTenant(UserTable))
+	result, err := r.db.Exec(query, tgUserID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("user already has another telegram account")
+	}
+	return nil
+}
+
+// CreateTelegramUser создает пользователя, входящего через Telegram, без пароля
+func (r *PostgresRepository) CreateTelegramUser(user models.RegisterUser, groupID int, tgUserID int64) (int, error) {
+	user.Password = ""
+	return r.createUser(user, groupID, sql.NullInt64{Int64: tgUserID, Valid: true})
+}
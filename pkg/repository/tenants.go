@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"fmt"
+	"sso/models"
+)
+
+// tenantColumns содержит список выбираемых полей арендатора
+const tenantColumns = "id, code, title, created_at"
+
+// CreateTenant в одной транзакции создает арендатора и его первого администратора; администратор не состоит в группах
+func (r *PostgresRepository) CreateTenant(tenant models.Tenant, admin models.RegisterUser) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	tenantQuery := fmt.Sprintf("INSERT INTO %s (code, title) VALUES ($1, $2) RETURNING id", TenantsTable)
+	if err := tx.QueryRow(tenantQuery, tenant.Code, tenant.Title).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	adminQuery := fmt.Sprintf(`INSERT INTO %s (tenant_id, username, tg_nick, password_hash, is_admin, status)
+		VALUES ($1, $2, $3, $4, true, $5)`, UserTable)
+	if _, err := tx.Exec(adminQuery, id, admin.Username, admin.TgNick, admin.Password, models.UserStatusActive); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// GetTenants возвращает всех арендаторов
+func (r *PostgresRepository) GetTenants() ([]models.Tenant, error) {
+	var tenants []models.Tenant
+	query := fmt.Sprintf("SELECT %s FROM %s ORDER BY id", tenantColumns, TenantsTable)
+	err := r.db.Select(&tenants, query)
+	if err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+// GetTenantByCode возвращает арендатора по коду
+func (r *PostgresRepository) GetTenantByCode(code string) (models.Tenant, error) {
+	var tenant models.Tenant
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE code = $1", tenantColumns, TenantsTable)
+	err := r.db.Get(&tenant, query, code)
+	return tenant, err
+}
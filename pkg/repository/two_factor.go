@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"fmt"
+	"sso/models"
+)
+
+// GetTwoFactorState возвращает настройки TOTP пользователя
+func (r *PostgresRepository) GetTwoFactorState(userID int) (models.TwoFactorState, error) {
+	var state models.TwoFactorState
+	query := fmt.Sprintf("SELECT totp_secret, totp_enabled, totp_last_step FROM %s WHERE id = $1 AND %s", UserTable, r.inTenant(UserTable))
+	err := r.db.Get(&state, query, userID)
+	return state, err
+}
+
+// SetTOTPSecret сохраняет новый секрет TOTP, пока двухфакторная аутентификация не подтверждена
+func (r *PostgresRepository) SetTOTPSecret(userID int, secret string) error {
+	query := fmt.Sprintf("UPDATE %s SET totp_secret = $1, totp_last_step = NULL WHERE id = $2 AND totp_enabled = false AND %s", UserTable, r.inTenant(UserTable))
+	result, err := r.db.Exec(query, secret, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("two-factor authentication is already enabled")
+	}
+	return nil
+}
+
+// EnableTwoFactor подтверждает подключение TOTP и сохраняет резервные коды
+func (r *PostgresRepository) EnableTwoFactor(userID int, step int64, codeHashes []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	enableQuery := fmt.Sprintf(`UPDATE %s SET totp_enabled = true, totp_last_step = $1
+		WHERE id = $2 AND totp_secret IS NOT NULL AND totp_enabled = false AND %s`, UserTable, r.inTenant(UserTable))
+	result, err := tx.Exec(enableQuery, step, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("two-factor authentication is already enabled or not set up")
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", RecoveryCodesTable)
+	if _, err := tx.Exec(deleteQuery, userID); err != nil {
+		return err
+	}
+	insertQuery := fmt.Sprintf("INSERT INTO %s (user_id, code_hash) VALUES ($1, $2)", RecoveryCodesTable)
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(insertQuery, userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DisableTwoFactor отключает TOTP и удаляет резервные коды
+func (r *PostgresRepository) DisableTwoFactor(userID int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	updateQuery := fmt.Sprintf("UPDATE %s SET totp_secret = NULL, totp_enabled = false, totp_last_step = NULL WHERE id = $1 AND %s", UserTable, r.inTenant(UserTable))
+	result, err := tx.Exec(updateQuery, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", RecoveryCodesTable)
+	if _, err := tx.Exec(deleteQuery, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep отмечает временной шаг использованным; возвращает false, если код этого или более позднего шага уже вводился
+func (r *PostgresRepository) UseTOTPStep(userID int, step int64) (bool, error) {
+	query := fmt.Sprintf(`UPDATE %s SET totp_last_step = $1
+		WHERE id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1) AND %s`, UserTable, r.inTenant(UserTable))
+	result, err := r.db.Exec(query, step, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// UseRecoveryCode погашает неиспользованный резервный код; возвращает false, если такого кода нет
+func (r *PostgresRepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL AND %s", RecoveryCodesTable, r.refInTenant("user_id", UserTable))
+	result, err := r.db.Exec(query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ReplaceRecoveryCodes заменяет все резервные коды пользователя арендатора новыми
+func (r *PostgresRepository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.checkInTenant(tx, UserTable, "user", []int64{int64(userID)}); err != nil {
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", RecoveryCodesTable)
+	if _, err := tx.Exec(deleteQuery, userID); err != nil {
+		return err
+	}
+	insertQuery := fmt.Sprintf("INSERT INTO %s (user_id, code_hash) VALUES ($1, $2)", RecoveryCodesTable)
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(insertQuery, userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
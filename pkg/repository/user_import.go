@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// GetExistingTgNicks возвращает ники пользователей арендатора, совпадающие с никами из списка без учета регистра
+func (r *PostgresRepository) GetExistingTgNicks(tgNicks []string) ([]string, error) {
+	var existing []string
+	query := fmt.Sprintf("SELECT tg_nick FROM %s WHERE LOWER(tg_nick) = ANY(SELECT LOWER(nick) FROM UNNEST($1::text[]) AS nick) AND %s",
+		UserTable, r.inTenant(UserTable))
+	err := r.db.Select(&existing, query, pq.Array(tgNicks))
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// ImportUsers в одной транзакции создает недостающие группы арендатора, пользователей со статусом new без пароля
+// и токены активации к ним; заполняет ID созданных пользователей и возвращает коды созданных групп
+func (r *PostgresRepository) ImportUsers(rows []models.ImportUserRow, createdBy int, expiresAt time.Time) ([]string, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	createGroupQuery := fmt.Sprintf("INSERT INTO %s (tenant_id, code) VALUES ($1, $2) ON CONFLICT (tenant_id, code) DO NOTHING RETURNING id", GroupTable)
+	getGroupQuery := fmt.Sprintf("SELECT id FROM %s WHERE code = $1 AND %s", GroupTable, r.inTenant(GroupTable))
+	createUserQuery := fmt.Sprintf(`INSERT INTO %s (tenant_id, username, tg_nick, password_hash)
+		VALUES ($1, $2, $3, '') ON CONFLICT DO NOTHING RETURNING id`, UserTable)
+	createTokenQuery := fmt.Sprintf("INSERT INTO %s (token_hash, user_id, created_by, expires_at) VALUES ($1, $2, $3, $4)", PasswordResetTokensTable)
+
+	var groupsCreated []string
+	groupIDs := make(map[string]int)
+	for i := range rows {
+		row := &rows[i]
+		if row.Status != models.ImportStatusNew {
+			continue
+		}
+
+		groupID, ok := groupIDs[row.Group]
+		if !ok {
+			err := tx.QueryRow(createGroupQuery, r.tenantID, row.Group).Scan(&groupID)
+			switch {
+			case err == nil:
+				groupsCreated = append(groupsCreated, row.Group)
+			case errors.Is(err, sql.ErrNoRows):
+				if err := tx.Get(&groupID, getGroupQuery, row.Group); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, err
+			}
+			groupIDs[row.Group] = groupID
+		}
+
+		// Ник мог быть занят после проверки файла; такой пользователь остается без изменений
+		err := tx.QueryRow(createUserQuery, r.tenantID, row.Username, row.TgNick).Scan(&row.UserID)
+		if errors.Is(err, sql.ErrNoRows) {
+			row.Status = models.ImportStatusExists
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := r.addGroupMember(tx, groupID, row.UserID, models.GroupRoleMember); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(createTokenQuery, row.TokenHash, row.UserID, createdBy, expiresAt); err != nil {
+			return nil, err
+		}
+		row.Status = models.ImportStatusCreated
+	}
+
+	return groupsCreated, tx.Commit()
+}
@@ -1,21 +1,58 @@
 package repository
 
 import (
+	"database/sql"
 	"fmt"
 	"sso/models"
+
+	"github.com/jmoiron/sqlx"
 )
 
 func (r *PostgresRepository) CreateUser(user models.RegisterUser, groupID int) (int, error) {
+	return r.createUser(user, groupID, sql.NullInt64{})
+}
+
+// createUser в одной транзакции создает пользователя и делает его участником группы
+func (r *PostgresRepository) createUser(user models.RegisterUser, groupID int, tgUserID sql.NullInt64) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := r.insertUser(tx, user, groupID, tgUserID)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// insertUser создает пользователя арендатора и делает его участником группы в рамках транзакции
+func (r *PostgresRepository) insertUser(tx *sqlx.Tx, user models.RegisterUser, groupID int, tgUserID sql.NullInt64) (int, error) {
 	var id int
-	query := fmt.Sprintf("INSERT INTO %s (username, tg_nick, group_id, password_hash) VALUES ($1, $2, $3, $4) RETURNING id", UserTable)
-	err := r.db.QueryRow(query, user.Username, user.TgNick, groupID, user.Password).Scan(&id)
-	return id, err
+	query := fmt.Sprintf(`INSERT INTO %s (tenant_id, username, tg_nick, password_hash, tg_user_id, status)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, UserTable)
+	if err := tx.QueryRow(query, r.tenantID, user.Username, user.TgNick, user.Password, tgUserID, userStatus(user)).Scan(&id); err != nil {
+		return 0, err
+	}
+	if err := r.addGroupMember(tx, groupID, id, models.GroupRoleMember); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// userStatus возвращает статус создаваемого пользователя; по умолчанию пользователь подтвержден
+func userStatus(user models.RegisterUser) string {
+	if user.Status == "" {
+		return models.UserStatusActive
+	}
+	return user.Status
 }
 
 // GetAllUsers возвращает всех пользователей
 func (r *PostgresRepository) GetAllUsers() ([]models.User, error) {
 	var users []models.User
-	query := fmt.Sprintf("SELECT id, username, tg_nick, group_id, password_hash, is_admin FROM %s ORDER BY username", UserTable)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY username", userColumns(UserTable), UserTable, r.inTenant(UserTable))
 	err := r.db.Select(&users, query)
 	if err != nil {
 		return nil, err
@@ -23,23 +60,57 @@ func (r *PostgresRepository) GetAllUsers() ([]models.User, error) {
 	return users, nil
 }
 
-// UpdateUser обновляет данные пользователя
+// UpdateUser обновляет данные пользователя; если список групп задан, членство приводится к нему,
+// а роли в группах, оставшихся в списке, сохраняются. Пустой список отклоняется: пользователь состоит хотя бы в одной группе
 func (r *PostgresRepository) UpdateUser(id int, user models.User) error {
-	query := fmt.Sprintf("UPDATE %s SET username = $1, tg_nick = $2, group_id = $3, is_admin = $4 WHERE id = $5", UserTable)
-	_, err := r.db.Exec(query, user.Username, user.TgNick, user.GroupID, user.IsAdmin, id)
-	return err
+	if user.GroupIDs != nil && len(user.GroupIDs) == 0 {
+		return fmt.Errorf("user must stay in at least one group")
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("UPDATE %s SET username = $1, tg_nick = $2, is_admin = $3 WHERE id = $4 AND %s", UserTable, r.inTenant(UserTable))
+	result, err := tx.Exec(query, user.Username, user.TgNick, user.IsAdmin, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	if user.GroupIDs != nil {
+		deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND NOT (group_id = ANY($2))", GroupMembershipsTable)
+		if _, err := tx.Exec(deleteQuery, id, user.GroupIDs); err != nil {
+			return err
+		}
+		for _, groupID := range user.GroupIDs {
+			if err := r.addGroupMember(tx, int(groupID), id, models.GroupRoleMember); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
 }
 
 // DeleteUser удаляет пользователя
 func (r *PostgresRepository) DeleteUser(id int) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", UserTable)
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND %s", UserTable, r.inTenant(UserTable))
 	_, err := r.db.Exec(query, id)
 	return err
 }
 
 func (r *PostgresRepository) GetUserByID(id int) (models.User, error) {
 	var user models.User
-	query := "SELECT id, username, tg_nick, group_id, password_hash, is_admin FROM users WHERE id = $1"
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 AND %s", userColumns(UserTable), UserTable, r.inTenant(UserTable))
 	err := r.db.Get(&user, query, id)
 	if err != nil {
 		return user, err
@@ -47,10 +118,10 @@ func (r *PostgresRepository) GetUserByID(id int) (models.User, error) {
 	return user, nil
 }
 
-// GetUserByTgName возвращает пользователя по Telegram имени
+// GetUserByTgName возвращает пользователя по Telegram имени без учета регистра
 func (r *PostgresRepository) GetUserByTgName(tgName string) (models.User, error) {
 	var user models.User
-	query := fmt.Sprintf("SELECT id, username, tg_nick, group_id, password_hash, is_admin FROM %s WHERE tg_nick = $1", UserTable)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE LOWER(tg_nick) = LOWER($1) AND %s", userColumns(UserTable), UserTable, r.inTenant(UserTable))
 	err := r.db.Get(&user, query, tgName)
 	if err != nil {
 		return user, err
@@ -61,7 +132,7 @@ func (r *PostgresRepository) GetUserByTgName(tgName string) (models.User, error)
 // GetUserIsAdmin проверяет, является ли пользователь администратором
 func (r *PostgresRepository) GetUserIsAdmin(id int) (bool, error) {
 	var isAdmin bool
-	query := fmt.Sprintf("SELECT is_admin FROM %s WHERE id = $1", UserTable)
+	query := fmt.Sprintf("SELECT is_admin FROM %s WHERE id = $1 AND %s", UserTable, r.inTenant(UserTable))
 	err := r.db.Get(&isAdmin, query, id)
 	if err != nil {
 		return false, err
@@ -69,10 +140,10 @@ func (r *PostgresRepository) GetUserIsAdmin(id int) (bool, error) {
 	return isAdmin, nil
 }
 
-// GetUserIdByTgNick возвращает ID пользователя по Telegram нику
+// GetUserIdByTgNick возвращает ID пользователя по Telegram нику без учета регистра
 func (r *PostgresRepository) GetUserIdByTgNick(tgNick string) (int, error) {
 	var id int
-	query := fmt.Sprintf("SELECT id FROM %s WHERE tg_nick = $1", UserTable)
+	query := fmt.Sprintf("SELECT id FROM %s WHERE LOWER(tg_nick) = LOWER($1) AND %s", UserTable, r.inTenant(UserTable))
 	err := r.db.Get(&id, query, tgNick)
 	if err != nil {
 		return 0, err
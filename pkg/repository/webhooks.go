@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"fmt"
+	"sso/models"
+)
+
+// webhookColumns содержит список выбираемых полей webhook-подписки
+const webhookColumns = "id, url, secret, event_types, is_active, created_at"
+
+// CreateWebhook создает webhook-подписку арендатора
+func (r *PostgresRepository) CreateWebhook(webhook models.Webhook) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (tenant_id, url, secret, event_types) VALUES ($1, $2, $3, $4) RETURNING id", WebhooksTable)
+	err := r.db.QueryRow(query, r.tenantID, webhook.URL, webhook.Secret, webhook.EventTypes).Scan(&id)
+	return id, err
+}
+
+// GetWebhookByID возвращает webhook-подписку по ID
+func (r *PostgresRepository) GetWebhookByID(id int) (models.Webhook, error) {
+	var webhook models.Webhook
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 AND %s", webhookColumns, WebhooksTable, r.inTenant(WebhooksTable))
+	err := r.db.Get(&webhook, query, id)
+	return webhook, err
+}
+
+// GetAllWebhooks возвращает все webhook-подписки
+func (r *PostgresRepository) GetAllWebhooks() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY id", webhookColumns, WebhooksTable, r.inTenant(WebhooksTable))
+	err := r.db.Select(&webhooks, query)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// GetActiveWebhooks возвращает активные подписки арендатора на событие указанного типа
+func (r *PostgresRepository) GetActiveWebhooks(eventType string) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE is_active = true AND $1 = ANY(event_types) AND %s ORDER BY id",
+		webhookColumns, WebhooksTable, r.inTenant(WebhooksTable))
+	err := r.db.Select(&webhooks, query, eventType)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook удаляет webhook-подписку вместе с журналом доставки
+func (r *PostgresRepository) DeleteWebhook(id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND %s", WebhooksTable, r.inTenant(WebhooksTable))
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+// AddWebhookDelivery записывает попытку доставки подписки арендатора в журнал
+func (r *PostgresRepository) AddWebhookDelivery(delivery models.WebhookDelivery) error {
+	query := fmt.Sprintf(`INSERT INTO %s (webhook_id, outbox_message_id, event_type, status_code, success, error, duration_ms)
+		SELECT $1::integer, $2::bigint, $3, $4::integer, $5::boolean, $6, $7::integer WHERE %s`,
+		WebhookDeliveriesTable, r.refInTenant("$1::integer", WebhooksTable))
+	_, err := r.db.Exec(query, delivery.WebhookID, delivery.OutboxMessageID, delivery.EventType,
+		delivery.StatusCode, delivery.Success, delivery.Error, delivery.DurationMs)
+	return err
+}
+
+// HasSuccessfulWebhookDelivery проверяет, доставлено ли сообщение подписке ранее
+func (r *PostgresRepository) HasSuccessfulWebhookDelivery(webhookID int, messageID int64) (bool, error) {
+	var delivered bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE webhook_id = $1 AND outbox_message_id = $2 AND success = true AND %s)",
+		WebhookDeliveriesTable, r.refInTenant("webhook_id", WebhooksTable))
+	err := r.db.Get(&delivered, query, webhookID, messageID)
+	return delivered, err
+}
+
+// GetWebhookDeliveries возвращает последние попытки доставки подписки
+func (r *PostgresRepository) GetWebhookDeliveries(webhookID, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	query := fmt.Sprintf(`SELECT id, webhook_id, outbox_message_id, event_type, status_code, success, error, duration_ms, created_at
+		FROM %s WHERE webhook_id = $1 AND %s ORDER BY id DESC LIMIT $2`, WebhookDeliveriesTable, r.refInTenant("webhook_id", WebhooksTable))
+	err := r.db.Select(&deliveries, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"sso/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Параметры API-ключей
+const (
+	APIKeyPrefix      = "sso_" // Начало каждого API-ключа, по которому его можно отличить от JWT токена
+	apiKeyPrefixBytes = 6      // Длина открытой части ключа
+	apiKeySecretBytes = 24     // Длина секретной части ключа
+	apiKeyNameMaxLen  = 255    // Максимальная длина названия ключа
+)
+
+// ErrInvalidAPIKey возвращается для неизвестного, отозванного или просроченного API-ключа
+var ErrInvalidAPIKey = errors.New("api key is invalid or expired")
+
+// CreateAPIKey создает API-ключ пользователя; область admin доступна только администратору
+func (s *AuthService) CreateAPIKey(userID int, isAdmin bool, input models.CreateAPIKeyRequest) (models.CreatedAPIKey, error) {
+	if utf8.RuneCountInString(input.Name) > apiKeyNameMaxLen {
+		return models.CreatedAPIKey{}, fmt.Errorf("api key name is too long")
+	}
+	scopes := input.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{models.APIKeyScopeRead}
+	}
+	for _, scope := range scopes {
+		if !containsString(models.APIKeyScopes, scope) {
+			return models.CreatedAPIKey{}, fmt.Errorf("unsupported api key scope: %s", scope)
+		}
+		if scope == models.APIKeyScopeAdmin && !isAdmin {
+			return models.CreatedAPIKey{}, fmt.Errorf("admin scope requires admin rights")
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return models.CreatedAPIKey{}, fmt.Errorf("expires_at must be in the future")
+	}
+
+	prefix, err := randomToken(apiKeyPrefixBytes)
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+	secret, err := randomToken(apiKeySecretBytes)
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+	key := APIKeyPrefix + prefix + "_" + secret
+
+	apiKey := models.APIKey{
+		UserID:    userID,
+		Name:      input.Name,
+		Prefix:    APIKeyPrefix + prefix,
+		KeyHash:   hashToken(key),
+		Scopes:    scopes,
+		ExpiresAt: input.ExpiresAt,
+	}
+	apiKey.ID, err = s.repo.CreateAPIKey(apiKey)
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+	apiKey.CreatedAt = time.Now()
+
+	return models.CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// GetAPIKeys возвращает API-ключи пользователя
+func (s *AuthService) GetAPIKeys(userID int) ([]models.APIKey, error) {
+	return s.repo.GetAPIKeys(userID)
+}
+
+// GetAllAPIKeys возвращает API-ключи всех пользователей
+func (s *AuthService) GetAllAPIKeys() ([]models.APIKey, error) {
+	return s.repo.GetAPIKeys(0)
+}
+
+// RevokeAPIKey отзывает API-ключ; пользователь может отозвать только свой ключ, администратор - любой
+func (s *AuthService) RevokeAPIKey(userID, keyID int, isAdmin bool) error {
+	key, err := s.repo.GetAPIKeyByID(keyID)
+	if err != nil || (key.UserID != userID && !isAdmin) {
+		return fmt.Errorf("api key not found")
+	}
+	return s.repo.RevokeAPIKey(keyID)
+}
+
+// AuthenticateAPIKey проверяет API-ключ и возвращает владельца и права, с которыми выполняется запрос
+func (s *AuthService) AuthenticateAPIKey(key string) (models.APIKeyIdentity, error) {
+	const op = "AuthenticateAPIKey"
+
+	rest := strings.TrimPrefix(key, APIKeyPrefix)
+	separator := strings.IndexByte(rest, '_')
+	if rest == key || separator <= 0 {
+		return models.APIKeyIdentity{}, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.repo.GetAPIKeyByPrefix(APIKeyPrefix + rest[:separator])
+	if err != nil {
+		return models.APIKeyIdentity{}, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(key)), []byte(apiKey.KeyHash)) != 1 {
+		return models.APIKeyIdentity{}, ErrInvalidAPIKey
+	}
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now())) {
+		return models.APIKeyIdentity{}, ErrInvalidAPIKey
+	}
+	if apiKey.OwnerStatus == models.UserStatusPending {
+		return models.APIKeyIdentity{}, ErrAccountPendingApproval
+	}
+
+	// Ключ ищется среди всех арендаторов; дальше запрос выполняется у арендатора владельца
+	if err := s.repo.ForTenant(apiKey.OwnerTenant).TouchAPIKey(apiKey.ID); err != nil {
+		log.Printf("%s: %v", op, err)
+	}
+
+	identity := models.APIKeyIdentity{UserID: apiKey.UserID, Scopes: apiKey.Scopes, TenantID: apiKey.OwnerTenant}
+	// Права администратора определяются текущим статусом владельца, а не моментом создания ключа
+	identity.IsAdmin = apiKey.OwnerAdmin && identity.HasScope(models.APIKeyScopeAdmin)
+	return identity, nil
+}
+
+// containsString проверяет, содержится ли строка в списке
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
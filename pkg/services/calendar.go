@@ -0,0 +1,213 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sso/models"
+	"sso/pkg/ical"
+	"strings"
+	"time"
+)
+
+// calendarTokenBytes задает длину случайной части токена ленты календаря
+const calendarTokenBytes = 24
+
+// ErrCalendarLinkIssued возвращается при повторном запросе ссылки: в базе хранится только хеш токена,
+// поэтому выданную ссылку показать нельзя, ее можно только заменить новой
+var ErrCalendarLinkIssued = errors.New("calendar link already issued, reset it to get a new one")
+
+// GetCalendarURL выдает ссылку на персональную ленту календаря при первом обращении
+func (s *AuthService) GetCalendarURL(userID int) (string, error) {
+	issued, err := s.repo.HasCalendarToken(userID)
+	if err != nil {
+		return "", err
+	}
+	if issued {
+		return "", ErrCalendarLinkIssued
+	}
+	return s.ResetCalendarURL(userID)
+}
+
+// ResetCalendarURL выдает новый токен ленты календаря; прежняя ссылка перестает работать
+func (s *AuthService) ResetCalendarURL(userID int) (string, error) {
+	token, err := randomToken(calendarTokenBytes)
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.SetCalendarToken(userID, hashToken(token)); err != nil {
+		return "", err
+	}
+	return s.calendarURL(token), nil
+}
+
+// GetUserCalendar формирует ленту iCalendar с очередями, доступными группе пользователя или в которых он участвует
+func (s *AuthService) GetUserCalendar(token string) (string, error) {
+	user, err := s.repo.GetUserByCalendarToken(hashToken(token))
+	if err != nil {
+		return "", fmt.Errorf("calendar not found")
+	}
+	// Лента запрашивается без заголовка арендатора, поэтому очереди берутся у арендатора владельца токена
+	return s.forTenant(user.TenantID).userCalendar(user)
+}
+
+// userCalendar формирует ленту iCalendar пользователя арендатора сервиса
+func (s *AuthService) userCalendar(user models.User) (string, error) {
+	queues, err := s.repo.GetAllQueues()
+	if err != nil {
+		return "", err
+	}
+	joinedIDs, err := s.repo.GetUserQueueIDs(user.ID)
+	if err != nil {
+		return "", err
+	}
+	bookings, err := s.repo.GetUserSlotBookings(user.ID)
+	if err != nil {
+		return "", err
+	}
+	units, err := s.userOrgUnits(user)
+	if err != nil {
+		return "", err
+	}
+	since := time.Now().Add(-s.cfg.Calendar.Lookback)
+	deleted, err := s.repo.GetDeletedQueues(since)
+	if err != nil {
+		return "", err
+	}
+
+	joined := make(map[int]bool, len(joinedIDs))
+	for _, id := range joinedIDs {
+		joined[id] = true
+	}
+	booked := make(map[int]models.SlotBooking, len(bookings))
+	for _, booking := range bookings {
+		booked[booking.QueueID] = booking
+	}
+
+	events := make([]ical.Event, 0, len(queues)+len(deleted))
+	for _, queue := range queues {
+		if queue.TimeEnd.Before(since) {
+			continue
+		}
+		if !joined[queue.ID] && !queueAvailableFor(queue, user, units) {
+			continue
+		}
+		booking, ok := booked[queue.ID]
+		if !ok {
+			events = append(events, s.queueEvent(queue, nil))
+			continue
+		}
+		events = append(events, s.queueEvent(queue, &booking))
+	}
+	// Удаленная очередь остается отмененным событием у тех, кто был в ней или мог ее видеть
+	for _, queue := range deleted {
+		if !containsID(queue.UserIDs, user.ID) && !queueAvailableFor(queue.Queue, user, units) {
+			continue
+		}
+		event := s.queueEvent(queue.Queue, nil)
+		event.URL = ""
+		event.Status = ical.StatusCancelled
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+
+	calendar := ical.Calendar{
+		Name:     "Очереди " + user.Username,
+		TimeZone: s.cfg.Calendar.TimeZone,
+		Events:   events,
+	}
+	return calendar.String(), nil
+}
+
+// GetQueueCalendar формирует файл iCalendar с одной очередью
+func (s *AuthService) GetQueueCalendar(queueID int) (string, error) {
+	queue, err := s.repo.GetQueueByID(queueID)
+	if err != nil {
+		return "", err
+	}
+
+	calendar := ical.Calendar{
+		Name:     queue.Title,
+		TimeZone: s.cfg.Calendar.TimeZone,
+		Events:   []ical.Event{s.queueEvent(queue, nil)},
+	}
+	return calendar.String(), nil
+}
+
+// queueEvent преобразует очередь в событие календаря; booking дополняет описание временем слота пользователя
+func (s *AuthService) queueEvent(queue models.Queue, booking *models.SlotBooking) ical.Event {
+	publicURL := strings.TrimRight(s.cfg.Calendar.PublicURL, "/")
+	host := "sso"
+	if parsed, err := url.Parse(publicURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+
+	event := ical.Event{
+		UID:      fmt.Sprintf("queue-%d@%s", queue.ID, host),
+		Sequence: queue.Sequence,
+		Stamp:    queue.UpdatedAt,
+		Start:    queue.TimeStart,
+		End:      queue.TimeEnd,
+		Summary:  queue.Title,
+	}
+	if publicURL != "" {
+		event.URL = fmt.Sprintf("%s/api/queues/%d", publicURL, queue.ID)
+	}
+
+	if queue.Mode == models.QueueModeSlots {
+		event.Description = fmt.Sprintf("Запись на слоты по %d мин.", queue.SlotDuration)
+		if booking != nil {
+			slots := buildSlots(queue)
+			if booking.SlotNumber >= 1 && booking.SlotNumber <= len(slots) {
+				slot := slots[booking.SlotNumber-1]
+				location := s.calendarLocation()
+				event.Description += fmt.Sprintf("\nВаш слот №%d: %s–%s", slot.Number,
+					slot.TimeStart.In(location).Format("15:04"), slot.TimeEnd.In(location).Format("15:04"))
+			}
+			if booking.BookedAt.After(event.Stamp) {
+				event.Stamp = booking.BookedAt
+			}
+		}
+	}
+
+	return event
+}
+
+// calendarLocation возвращает часовой пояс для отображения времени в описаниях событий
+func (s *AuthService) calendarLocation() *time.Location {
+	if s.cfg.Calendar.TimeZone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(s.cfg.Calendar.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// calendarURL формирует ссылку на ленту календаря по токену
+func (s *AuthService) calendarURL(token string) string {
+	return fmt.Sprintf("%s/calendar/%s.ics", strings.TrimRight(s.cfg.Calendar.PublicURL, "/"), token)
+}
+
+// containsID проверяет наличие ID в списке
+func containsID(ids []int64, id int) bool {
+	for _, candidate := range ids {
+		if candidate == int64(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// randomToken генерирует криптографически случайный токен в шестнадцатеричном виде
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
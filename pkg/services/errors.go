@@ -0,0 +1,35 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+// Коды нарушений правил присоединения к очереди и входа
+const (
+	PolicyMaxActiveQueues = "max_active_queues" // Превышено число одновременных очередей
+	PolicyRejoinCooldown  = "rejoin_cooldown"   // Не истекла пауза после выхода из очереди
+	PolicyDailyJoinLimit  = "daily_join_limit"  // Превышен лимит присоединений за сутки
+	PolicyAccountLocked   = "account_locked"    // Вход временно заблокирован после неудачных попыток
+)
+
+// PolicyError описывает нарушение правила присоединения к очереди или входа
+type PolicyError struct {
+	Code       string        // Машиночитаемый код нарушенного правила
+	Message    string        // Описание нарушения
+	RetryAfter time.Duration // Через сколько можно повторить попытку (0 - неизвестно)
+}
+
+// Error возвращает описание нарушения
+func (e *PolicyError) Error() string {
+	return e.Message
+}
+
+// newPolicyError создает ошибку нарушения правила с форматированным описанием
+func newPolicyError(code string, retryAfter time.Duration, format string, args ...interface{}) *PolicyError {
+	return &PolicyError{
+		Code:       code,
+		Message:    fmt.Sprintf(format, args...),
+		RetryAfter: retryAfter,
+	}
+}
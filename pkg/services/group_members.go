@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sso/models"
+)
+
+// ErrGroupRequired возвращается при попытке оставить пользователя без групп
+var ErrGroupRequired = errors.New("user must stay in at least one group")
+
+// GetGroupMembers возвращает участников группы модератору группы или администратору
+func (s *AuthService) GetGroupMembers(userID int, isAdmin bool, groupID int) ([]models.GroupMember, error) {
+	if err := s.checkGroupModerator(userID, isAdmin, groupID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetGroupMembers(groupID)
+}
+
+// AddGroupMember добавляет пользователя в группу; добавить его может модератор группы или администратор
+func (s *AuthService) AddGroupMember(userID int, isAdmin bool, groupID, memberID int) error {
+	if err := s.checkGroupModerator(userID, isAdmin, groupID); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetGroupByID(groupID); err != nil {
+		return fmt.Errorf("group not found")
+	}
+	if _, err := s.repo.GetUserByID(memberID); err != nil {
+		return fmt.Errorf("user not found")
+	}
+	return s.repo.AddGroupMember(groupID, memberID)
+}
+
+// RemoveGroupMember исключает пользователя из группы и выводит его из очередей, в которые он больше не допущен;
+// последнюю группу пользователя можно только сменить переводом
+func (s *AuthService) RemoveGroupMember(userID int, isAdmin bool, groupID, memberID int) (models.GroupMembershipChange, error) {
+	if err := s.checkGroupModerator(userID, isAdmin, groupID); err != nil {
+		return models.GroupMembershipChange{}, err
+	}
+	member, err := s.repo.GetUserByID(memberID)
+	if err != nil || !member.InGroup(groupID) {
+		return models.GroupMembershipChange{}, fmt.Errorf("member not found")
+	}
+	if len(member.GroupIDs) == 1 {
+		return models.GroupMembershipChange{}, fmt.Errorf("%w, transfer the user instead", ErrGroupRequired)
+	}
+
+	if err := s.repo.RemoveGroupMember(groupID, memberID); err != nil {
+		return models.GroupMembershipChange{}, err
+	}
+	return s.leaveIneligibleQueues(memberID)
+}
+
+// TransferGroupMember переводит пользователя в другую группу и выводит его из очередей, в которые он больше не допущен;
+// перевести может тот, кто модерирует обе группы, или администратор
+func (s *AuthService) TransferGroupMember(userID int, isAdmin bool, groupID, memberID int, input models.GroupTransferRequest) (models.GroupMembershipChange, error) {
+	if input.ToGroupID == groupID {
+		return models.GroupMembershipChange{}, fmt.Errorf("user is already in this group")
+	}
+	if err := s.checkGroupModerator(userID, isAdmin, groupID); err != nil {
+		return models.GroupMembershipChange{}, err
+	}
+	if err := s.checkGroupModerator(userID, isAdmin, input.ToGroupID); err != nil {
+		return models.GroupMembershipChange{}, err
+	}
+	if _, err := s.repo.GetGroupByID(input.ToGroupID); err != nil {
+		return models.GroupMembershipChange{}, fmt.Errorf("group not found")
+	}
+
+	if err := s.repo.TransferGroupMember(memberID, groupID, input.ToGroupID); err != nil {
+		return models.GroupMembershipChange{}, err
+	}
+	return s.leaveIneligibleQueues(memberID)
+}
+
+// leaveIneligibleQueues выводит пользователя из очередей и отменяет его записи на слоты там,
+// куда после смены групп он больше не допущен
+func (s *AuthService) leaveIneligibleQueues(userID int) (models.GroupMembershipChange, error) {
+	change := models.GroupMembershipChange{UserID: userID, LeftQueues: []int{}}
+
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return change, err
+	}
+	units, err := s.userOrgUnits(user)
+	if err != nil {
+		return change, err
+	}
+	queueIDs, err := s.repo.GetUserQueueIDs(userID)
+	if err != nil {
+		return change, err
+	}
+
+	for _, queueID := range queueIDs {
+		queue, err := s.repo.GetQueueByID(queueID)
+		if err != nil {
+			return change, err
+		}
+		if queueAvailableFor(queue, user, units) {
+			continue
+		}
+		if queue.Mode == models.QueueModeSlots {
+			err = s.repo.CancelSlotBooking(queueID, userID)
+		} else {
+			err = s.repo.LeaveQueue(queueID, userID)
+		}
+		if err != nil {
+			return change, err
+		}
+		change.LeftQueues = append(change.LeftQueues, queueID)
+	}
+	return change, nil
+}
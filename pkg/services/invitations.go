@@ -0,0 +1,146 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/models"
+	"strings"
+	"time"
+)
+
+// Параметры приглашений
+const (
+	invitationCodeBytes  = 8                  // Длина кода приглашения
+	maxInvitationUses    = 1000               // Максимальное число регистраций по одному приглашению
+	defaultInvitationTTL = 7 * 24 * time.Hour // Время жизни приглашения, если оно не задано в конфигурации
+)
+
+// Ошибки регистрации по приглашению
+var (
+	ErrInvitationRequired     = errors.New("invitation code is required")
+	ErrInvalidInvitation      = errors.New("invitation is invalid, expired or already used")
+	ErrGroupModeratorRequired = errors.New("group moderator access required")
+)
+
+// AddGroupModerator назначает пользователя модератором группы
+func (s *AuthService) AddGroupModerator(groupID, userID int) error {
+	if _, err := s.repo.GetGroupByID(groupID); err != nil {
+		return fmt.Errorf("group not found")
+	}
+	if _, err := s.repo.GetUserByID(userID); err != nil {
+		return fmt.Errorf("user not found")
+	}
+	return s.repo.AddGroupModerator(groupID, userID)
+}
+
+// RemoveGroupModerator снимает пользователя с роли модератора группы
+func (s *AuthService) RemoveGroupModerator(groupID, userID int) error {
+	return s.repo.RemoveGroupModerator(groupID, userID)
+}
+
+// GetGroupModerators возвращает модераторов группы
+func (s *AuthService) GetGroupModerators(groupID int) ([]models.User, error) {
+	return s.repo.GetGroupModerators(groupID)
+}
+
+// CreateInvitation выдает приглашение в группу; выдать его может модератор группы или администратор
+func (s *AuthService) CreateInvitation(userID int, isAdmin bool, groupID int, input models.CreateInvitationRequest) (models.CreatedInvitation, error) {
+	if err := s.checkGroupModerator(userID, isAdmin, groupID); err != nil {
+		return models.CreatedInvitation{}, err
+	}
+	if _, err := s.repo.GetGroupByID(groupID); err != nil {
+		return models.CreatedInvitation{}, fmt.Errorf("group not found")
+	}
+
+	maxUses := input.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+	if maxUses < 1 || maxUses > maxInvitationUses {
+		return models.CreatedInvitation{}, fmt.Errorf("max_uses must be between 1 and %d", maxInvitationUses)
+	}
+
+	ttl := s.cfg.Registration.InvitationTTL
+	if ttl <= 0 {
+		ttl = defaultInvitationTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(time.Now()) {
+			return models.CreatedInvitation{}, fmt.Errorf("expires_at must be in the future")
+		}
+		expiresAt = *input.ExpiresAt
+	}
+
+	code, err := randomToken(invitationCodeBytes)
+	if err != nil {
+		return models.CreatedInvitation{}, err
+	}
+	invitation := models.Invitation{
+		GroupID:   groupID,
+		CreatedBy: &userID,
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	invitation.ID, err = s.repo.CreateInvitation(invitation, hashToken(code))
+	if err != nil {
+		return models.CreatedInvitation{}, err
+	}
+
+	return models.CreatedInvitation{Invitation: invitation, Code: code}, nil
+}
+
+// GetInvitations возвращает приглашения группы модератору группы или администратору
+func (s *AuthService) GetInvitations(userID int, isAdmin bool, groupID int) ([]models.Invitation, error) {
+	if err := s.checkGroupModerator(userID, isAdmin, groupID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetInvitations(groupID)
+}
+
+// RevokeInvitation отзывает приглашение группы; уже зарегистрированные по нему пользователи остаются в группе
+func (s *AuthService) RevokeInvitation(userID int, isAdmin bool, groupID, invitationID int) error {
+	if err := s.checkGroupModerator(userID, isAdmin, groupID); err != nil {
+		return err
+	}
+	return s.repo.RevokeInvitation(invitationID, groupID)
+}
+
+// checkGroupModerator проверяет, что пользователь - администратор, модератор группы или администратор ее факультета или кафедры
+func (s *AuthService) checkGroupModerator(userID int, isAdmin bool, groupID int) error {
+	if isAdmin {
+		return nil
+	}
+	ok, err := s.repo.IsGroupModerator(userID, groupID)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+
+	units, err := s.repo.GetGroupOrgUnits(groupID)
+	if err != nil {
+		return err
+	}
+	ok, err = s.repo.IsOrgUnitAdmin(userID, units)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrGroupModeratorRequired
+	}
+	return nil
+}
+
+// createInvitedUser регистрирует пользователя в группе из приглашения, погашая одно использование приглашения
+func (s *AuthService) createInvitedUser(user models.RegisterUser, tgUserID sql.NullInt64) (int, error) {
+	code := strings.ToLower(strings.TrimSpace(user.Invitation))
+	id, err := s.repo.CreateUserByInvitation(user, hashToken(code), tgUserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInvalidInvitation
+	}
+	return id, err
+}
@@ -3,42 +3,81 @@ package services
 
 import (
 	"errors"
+	"log"
 	"sso/models"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // tokenClaims представляет структуру JWT токена с пользовательскими данными
 type tokenClaims struct {
 	jwt.RegisteredClaims
-	UserId  int  `json:"user_id"`  // ID пользователя
-	IsAdmin bool `json:"is_admin"` // Флаг администратора
+	UserId    int    `json:"user_id"`           // ID пользователя
+	TenantID  int    `json:"tenant_id"`         // Арендатор пользователя
+	IsAdmin   bool   `json:"is_admin"`          // Флаг администратора
+	SessionID int    `json:"sid,omitempty"`     // ID сессии, которой выдан токен доступа
+	Purpose   string `json:"purpose,omitempty"` // Назначение токена; пустое у токенов доступа к API
 }
 
-// VerificationPassword проверяет пароль пользователя и возвращает JWT токен
-func (s *AuthService) VerificationPassword(username, password string) (string, error) {
-	const op = "VerificationPassword"
-	user, err := s.repo.GetUserByTgName(username)
+// Назначения токенов, не дающих доступа к API
+const (
+	purposePreAuth = "pre_auth" // Первый шаг входа, пройденный до ввода кода двухфакторной аутентификации
+)
+
+// defaultPreAuthTTL задает время жизни токена первого шага, если оно не задано в конфигурации
+const defaultPreAuthTTL = 5 * time.Minute
+
+// sessionTouchInterval задает, как часто обновляется время последнего запроса сессии
+const sessionTouchInterval = time.Minute
+
+// sessionUserAgentMaxLen ограничивает длину сохраняемого User-Agent
+const sessionUserAgentMaxLen = 512
+
+// ParseToken парсит JWT токен, проверяет, что его сессия не отозвана, и возвращает данные пользователя;
+// сессия ищется у арендатора из токена, поэтому токен одного арендатора не действует у другого
+func (s *AuthService) ParseToken(tokenStr string) (models.TokenIdentity, error) {
+	const op = "ParseToken"
+
+	claims, err := parseClaims(tokenStr)
 	if err != nil {
-		return "", err
+		return models.TokenIdentity{}, err
 	}
 
-	// Проверка пароля с использованием bcrypt
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", errors.New("invalid password")
+	// Токен первого шага входа не дает доступа к API
+	if claims.Purpose != "" || claims.SessionID == 0 || claims.TenantID == 0 {
+		return models.TokenIdentity{}, errors.New("token is invalid")
 	}
 
-	// Создание JWT токена при успешной аутентификации
-	return s.NewToken(models.AuthUser{
-		TgNick:   username,
-		Password: password,
-	})
+	repo := s.repo.ForTenant(claims.TenantID)
+	session, err := repo.GetActiveSession(claims.SessionID, claims.UserId)
+	if err != nil {
+		return models.TokenIdentity{}, errors.New("session has been revoked or expired")
+	}
+	if session.UserStatus == models.UserStatusPending {
+		return models.TokenIdentity{}, ErrAccountPendingApproval
+	}
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		if err := repo.TouchSession(session.ID); err != nil {
+			log.Printf("%s: %v", op, err)
+		}
+	}
+
+	return models.TokenIdentity{UserID: claims.UserId, IsAdmin: claims.IsAdmin, SessionID: claims.SessionID, TenantID: claims.TenantID}, nil
 }
 
-// ParseToken парсит JWT токен и возвращает ID пользователя и статус администратора
-func (s *AuthService) ParseToken(tokenStr string) (int, bool, error) {
+// parsePreAuthToken проверяет токен первого шага входа, выданный арендатором сервиса, и возвращает ID пользователя
+func (s *AuthService) parsePreAuthToken(tokenStr string) (int, error) {
+	claims, err := parseClaims(tokenStr)
+	if err != nil || claims.Purpose != purposePreAuth || claims.TenantID != s.tenantID {
+		return 0, errors.New("pre-auth token is invalid or expired")
+	}
+	return claims.UserId, nil
+}
+
+// parseClaims проверяет подпись и срок действия JWT токена и извлекает claims
+func parseClaims(tokenStr string) (*tokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Проверяем метод подписи
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -47,41 +86,60 @@ func (s *AuthService) ParseToken(tokenStr string) (int, bool, error) {
 		return []byte(jwtSecret), nil
 	})
 	if err != nil {
-		return 0, false, err
+		return nil, err
 	}
 
 	// Извлекаем claims из токена
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok || !token.Valid {
-		return 0, false, errors.New("token is invalid")
+		return nil, errors.New("token is invalid")
 	}
-
-	return claims.UserId, claims.IsAdmin, nil
+	return claims, nil
 }
 
-// NewToken создает новый JWT токен для пользователя
-func (s *AuthService) NewToken(userAuth models.AuthUser) (string, error) {
-	// Получаем ID пользователя по Telegram никнейму
-	userId, err := s.repo.GetUserIdByTgNick(userAuth.TgNick)
-	if err != nil {
-		return "", err
-	}
+// newUserToken создает сессию и подписывает JWT токен с ID пользователя, арендатором, статусом администратора и ID сессии
+func (s *AuthService) newUserToken(userId int, isAdmin bool, client models.ClientInfo) (string, error) {
+	expiresAt := time.Now().Add(tokenTTL)
 
-	// Получаем статус администратора
-	isAdmin, err := s.repo.GetUserIsAdmin(userId)
+	userAgent := client.UserAgent
+	if len(userAgent) > sessionUserAgentMaxLen {
+		userAgent = strings.ToValidUTF8(userAgent[:sessionUserAgentMaxLen], "")
+	}
+	sessionID, err := s.repo.CreateSession(models.Session{UserID: userId, UserAgent: userAgent, IP: client.IP, ExpiresAt: expiresAt})
 	if err != nil {
 		return "", err
 	}
 
 	// Создаем claims для JWT токена
-	claims := &tokenClaims{
+	return signClaims(&tokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenTTL)), // Устанавливаем время истечения
+			ExpiresAt: jwt.NewNumericDate(expiresAt), // Устанавливаем время истечения
 		},
-		UserId:  userId,
-		IsAdmin: isAdmin,
+		UserId:    userId,
+		TenantID:  s.tenantID,
+		IsAdmin:   isAdmin,
+		SessionID: sessionID,
+	})
+}
+
+// newPreAuthToken подписывает короткоживущий токен первого шага входа
+func (s *AuthService) newPreAuthToken(userId int) (string, error) {
+	ttl := s.cfg.TwoFactor.PreAuthTTL
+	if ttl <= 0 {
+		ttl = defaultPreAuthTTL
 	}
+	return signClaims(&tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+		UserId:   userId,
+		TenantID: s.tenantID,
+		Purpose:  purposePreAuth,
+	})
+}
 
+// signClaims подписывает claims секретным ключом приложения
+func signClaims(claims *tokenClaims) (string, error) {
 	// Создаем и подписываем токен
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(jwtSecret))
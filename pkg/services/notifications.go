@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"sso/models"
+	"time"
+)
+
+// Параметры кодов привязки чата Telegram
+const (
+	telegramLinkCodeBytes = 16               // Длина случайной части кода привязки
+	defaultLinkCodeTTL    = 15 * time.Minute // Время жизни кода, если оно не задано в конфигурации
+)
+
+// CreateTelegramLink выдает одноразовую ссылку на бота для привязки чата Telegram
+func (s *AuthService) CreateTelegramLink(userID int) (models.TelegramLink, error) {
+	if s.cfg.Telegram.BotName == "" {
+		return models.TelegramLink{}, fmt.Errorf("telegram bot is not configured")
+	}
+
+	code, err := randomToken(telegramLinkCodeBytes)
+	if err != nil {
+		return models.TelegramLink{}, err
+	}
+
+	ttl := s.cfg.Telegram.LinkCodeTTL
+	if ttl <= 0 {
+		ttl = defaultLinkCodeTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+	if err := s.repo.CreateTelegramLinkCode(userID, code, expiresAt); err != nil {
+		return models.TelegramLink{}, err
+	}
+
+	return models.TelegramLink{
+		Code:      code,
+		URL:       fmt.Sprintf("https://t.me/%s?start=%s", url.PathEscape(s.cfg.Telegram.BotName), code),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// LinkTelegramChat привязывает чат Telegram к пользователю по коду из команды /start;
+// код ищется среди всех арендаторов, арендатор пользователя возвращается в TenantID
+func (s *AuthService) LinkTelegramChat(code string, chatID int64) (models.User, error) {
+	if _, err := s.repo.LinkTelegramChat(code, chatID); err != nil {
+		return models.User{}, err
+	}
+	return s.repo.GetUserByTelegramChat(chatID)
+}
+
+// UnlinkTelegramChat отвязывает чат Telegram пользователя; бот перестает принимать из него команды и слать уведомления
+func (s *AuthService) UnlinkTelegramChat(userID int) error {
+	return s.repo.UnlinkTelegramChat(userID)
+}
+
+// GetUserByTelegramChat возвращает пользователя, привязавшего чат Telegram
+func (s *AuthService) GetUserByTelegramChat(chatID int64) (models.User, error) {
+	user, err := s.repo.GetUserByTelegramChat(chatID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("telegram chat is not linked")
+	}
+
+	// Права администратора в боте определяются так же, как при входе: если 2FA обязательна
+	// для администраторов, до ее подключения пользователь администратором не считается
+	if user.IsAdmin && s.cfg.TwoFactor.RequiredForAdmins {
+		state, err := s.repo.ForTenant(user.TenantID).GetTwoFactorState(user.ID)
+		if err != nil {
+			return models.User{}, err
+		}
+		user.IsAdmin = state.Enabled
+	}
+	return user, nil
+}
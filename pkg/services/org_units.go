@@ -0,0 +1,279 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sso/models"
+	"strings"
+)
+
+// ErrOrgUnitAdminRequired возвращается, если у пользователя нет прав администратора на единицу или ее предков
+var ErrOrgUnitAdminRequired = errors.New("org unit admin access required")
+
+// CreateOrgUnit создает факультет или кафедру; корневую единицу создает только администратор системы
+func (s *AuthService) CreateOrgUnit(userID int, isAdmin bool, input models.OrgUnitRequest) (int, error) {
+	unit, err := newOrgUnit(input)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.checkOrgUnitParent(userID, isAdmin, unit.ParentID); err != nil {
+		return 0, err
+	}
+	return s.repo.CreateOrgUnit(unit)
+}
+
+// GetOrgUnits возвращает все организационные единицы; дерево строится по parent_id
+func (s *AuthService) GetOrgUnits() ([]models.OrgUnit, error) {
+	return s.repo.GetOrgUnits()
+}
+
+// GetOrgUnit возвращает организационную единицу вместе с дочерними единицами и группами
+func (s *AuthService) GetOrgUnit(id int) (models.OrgUnitDetails, error) {
+	unit, err := s.repo.GetOrgUnitByID(id)
+	if err != nil {
+		return models.OrgUnitDetails{}, fmt.Errorf("org unit not found")
+	}
+	children, err := s.repo.GetOrgUnitChildren(id)
+	if err != nil {
+		return models.OrgUnitDetails{}, err
+	}
+	groups, err := s.repo.GetOrgUnitGroups(id)
+	if err != nil {
+		return models.OrgUnitDetails{}, err
+	}
+	return models.OrgUnitDetails{OrgUnit: unit, Children: children, Groups: groups}, nil
+}
+
+// UpdateOrgUnit изменяет организационную единицу; перенос в другую ветку требует прав и на новую родительскую единицу
+func (s *AuthService) UpdateOrgUnit(userID int, isAdmin bool, id int, input models.OrgUnitRequest) error {
+	if err := s.checkOrgUnitAdmin(userID, isAdmin, id); err != nil {
+		return err
+	}
+	current, err := s.repo.GetOrgUnitByID(id)
+	if err != nil {
+		return fmt.Errorf("org unit not found")
+	}
+	unit, err := newOrgUnit(input)
+	if err != nil {
+		return err
+	}
+	unit.ID = id
+
+	if !sameParent(current.ParentID, unit.ParentID) {
+		if err := s.checkOrgUnitParent(userID, isAdmin, unit.ParentID); err != nil {
+			return err
+		}
+		// Единицу нельзя сделать потомком самой себя
+		if unit.ParentID != nil {
+			path, err := s.repo.GetOrgUnitPath(*unit.ParentID)
+			if err != nil {
+				return err
+			}
+			for _, ancestorID := range path {
+				if int(ancestorID) == id {
+					return fmt.Errorf("org unit cannot be moved into its own subtree")
+				}
+			}
+		}
+	}
+	return s.repo.UpdateOrgUnit(unit)
+}
+
+// DeleteOrgUnit удаляет пустую организационную единицу; нужны права на ее родительскую единицу
+func (s *AuthService) DeleteOrgUnit(userID int, isAdmin bool, id int) error {
+	unit, err := s.repo.GetOrgUnitByID(id)
+	if err != nil {
+		return fmt.Errorf("org unit not found")
+	}
+	if err := s.checkOrgUnitParent(userID, isAdmin, unit.ParentID); err != nil {
+		return err
+	}
+	return s.repo.DeleteOrgUnit(id)
+}
+
+// SetGroupOrgUnit включает группу в организационную единицу; группу из другой единицы переносит тот, у кого есть права на обе
+func (s *AuthService) SetGroupOrgUnit(userID int, isAdmin bool, unitID, groupID int) error {
+	if err := s.checkOrgUnitAdmin(userID, isAdmin, unitID); err != nil {
+		return err
+	}
+	group, err := s.repo.GetGroupByID(groupID)
+	if err != nil {
+		return fmt.Errorf("group not found")
+	}
+	if group.OrgUnitID != nil && *group.OrgUnitID != unitID {
+		if err := s.checkOrgUnitAdmin(userID, isAdmin, *group.OrgUnitID); err != nil {
+			return err
+		}
+	}
+	return s.repo.SetGroupOrgUnit(groupID, &unitID)
+}
+
+// RemoveGroupOrgUnit выводит группу из организационной единицы
+func (s *AuthService) RemoveGroupOrgUnit(userID int, isAdmin bool, unitID, groupID int) error {
+	if err := s.checkOrgUnitAdmin(userID, isAdmin, unitID); err != nil {
+		return err
+	}
+	group, err := s.repo.GetGroupByID(groupID)
+	if err != nil || group.OrgUnitID == nil || *group.OrgUnitID != unitID {
+		return fmt.Errorf("group not found in org unit")
+	}
+	return s.repo.SetGroupOrgUnit(groupID, nil)
+}
+
+// AddOrgUnitAdmin назначает администратора организационной единицы
+func (s *AuthService) AddOrgUnitAdmin(userID int, isAdmin bool, unitID, adminID int) error {
+	if err := s.checkOrgUnitAdmin(userID, isAdmin, unitID); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetUserByID(adminID); err != nil {
+		return fmt.Errorf("user not found")
+	}
+	return s.repo.AddOrgUnitAdmin(unitID, adminID)
+}
+
+// RemoveOrgUnitAdmin снимает администратора организационной единицы
+func (s *AuthService) RemoveOrgUnitAdmin(userID int, isAdmin bool, unitID, adminID int) error {
+	if err := s.checkOrgUnitAdmin(userID, isAdmin, unitID); err != nil {
+		return err
+	}
+	return s.repo.RemoveOrgUnitAdmin(unitID, adminID)
+}
+
+// GetOrgUnitAdmins возвращает администраторов организационной единицы
+func (s *AuthService) GetOrgUnitAdmins(userID int, isAdmin bool, unitID int) ([]models.User, error) {
+	if err := s.checkOrgUnitAdmin(userID, isAdmin, unitID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetOrgUnitAdmins(unitID)
+}
+
+// checkOrgUnitAdmin проверяет, что пользователь - администратор системы, единицы или одного из ее предков
+func (s *AuthService) checkOrgUnitAdmin(userID int, isAdmin bool, unitID int) error {
+	if isAdmin {
+		return nil
+	}
+	path, err := s.repo.GetOrgUnitPath(unitID)
+	if err != nil {
+		return err
+	}
+	if len(path) == 0 {
+		return fmt.Errorf("org unit not found")
+	}
+	ok, err := s.repo.IsOrgUnitAdmin(userID, path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrOrgUnitAdminRequired
+	}
+	return nil
+}
+
+// CheckQueueManager проверяет право управлять очередью: администратор системы управляет любой очередью,
+// администратор единицы - очередью, допуск в которую ограничен его поддеревом
+func (s *AuthService) CheckQueueManager(userID int, isAdmin bool, queueID int) error {
+	if isAdmin {
+		return nil
+	}
+	queue, err := s.repo.GetQueueByID(queueID)
+	if err != nil {
+		return fmt.Errorf("queue not found")
+	}
+	return s.checkQueueScope(userID, queue)
+}
+
+// CheckQueueRestrictions проверяет, что ограничения допуска очереди не выходят за поддерево администратора единицы
+func (s *AuthService) CheckQueueRestrictions(userID int, isAdmin bool, queue models.Queue) error {
+	if isAdmin {
+		return nil
+	}
+	return s.checkQueueScope(userID, queue)
+}
+
+// CheckStatsAccess проверяет право на сводную статистику: администратору единицы она доступна только с фильтром по его единице
+func (s *AuthService) CheckStatsAccess(userID int, isAdmin bool, filter models.StatsFilter) error {
+	if isAdmin {
+		return nil
+	}
+	if filter.OrgUnitID == 0 {
+		return ErrOrgUnitAdminRequired
+	}
+	return s.checkOrgUnitAdmin(userID, false, filter.OrgUnitID)
+}
+
+// checkQueueScope проверяет, что очередь ограничена единицами и группами, каждая из которых входит в поддерево,
+// администрируемое пользователем; открытая для всех очередь доступна только администратору системы
+func (s *AuthService) checkQueueScope(userID int, queue models.Queue) error {
+	if len(queue.AllowedGroups) == 0 && len(queue.AllowedUnits) == 0 {
+		return ErrOrgUnitAdminRequired
+	}
+	units := append([]int64{}, queue.AllowedUnits...)
+	for _, groupID := range queue.AllowedGroups {
+		group, err := s.repo.GetGroupByID(int(groupID))
+		if err != nil {
+			return fmt.Errorf("group not found")
+		}
+		if group.OrgUnitID == nil {
+			return ErrOrgUnitAdminRequired
+		}
+		units = append(units, int64(*group.OrgUnitID))
+	}
+	for _, unitID := range units {
+		if err := s.checkOrgUnitAdmin(userID, false, int(unitID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOrgUnitParent проверяет права на размещение единицы под родительской; корень дерева доступен только администратору системы
+func (s *AuthService) checkOrgUnitParent(userID int, isAdmin bool, parentID *int) error {
+	if parentID == nil {
+		if !isAdmin {
+			return ErrOrgUnitAdminRequired
+		}
+		return nil
+	}
+	if _, err := s.repo.GetOrgUnitByID(*parentID); err != nil {
+		return fmt.Errorf("parent org unit not found")
+	}
+	return s.checkOrgUnitAdmin(userID, isAdmin, *parentID)
+}
+
+// userOrgUnits возвращает единицы, в поддерево которых входят группы пользователя
+func (s *AuthService) userOrgUnits(user models.User) ([]int64, error) {
+	var units []int64
+	for _, groupID := range user.GroupIDs {
+		groupUnits, err := s.repo.GetGroupOrgUnits(int(groupID))
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, groupUnits...)
+	}
+	return units, nil
+}
+
+// newOrgUnit проверяет запрос и формирует организационную единицу
+func newOrgUnit(input models.OrgUnitRequest) (models.OrgUnit, error) {
+	unit := models.OrgUnit{
+		ParentID: input.ParentID,
+		Kind:     input.Kind,
+		Code:     strings.TrimSpace(input.Code),
+		Title:    strings.TrimSpace(input.Title),
+	}
+	if unit.Kind != models.OrgUnitFaculty && unit.Kind != models.OrgUnitDepartment {
+		return models.OrgUnit{}, fmt.Errorf("kind must be %q or %q", models.OrgUnitFaculty, models.OrgUnitDepartment)
+	}
+	if unit.Code == "" {
+		return models.OrgUnit{}, fmt.Errorf("code is required")
+	}
+	return unit, nil
+}
+
+// sameParent сравнивает родительские единицы
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
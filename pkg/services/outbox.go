@@ -0,0 +1,27 @@
+package services
+
+import (
+	"fmt"
+	"sso/models"
+)
+
+// outboxListLimit ограничивает число сообщений outbox в ответе
+const outboxListLimit = 100
+
+// GetOutboxMessages возвращает последние сообщения outbox в указанном состоянии (по умолчанию dead-letter)
+func (s *AuthService) GetOutboxMessages(status string) ([]models.OutboxMessage, error) {
+	if status == "" {
+		status = models.OutboxStatusDead
+	}
+	switch status {
+	case models.OutboxStatusPending, models.OutboxStatusDelivered, models.OutboxStatusDead:
+	default:
+		return nil, fmt.Errorf("unknown outbox status %q", status)
+	}
+	return s.repo.GetOutboxMessages(status, outboxListLimit)
+}
+
+// RequeueOutboxMessage возвращает сообщение из dead-letter в очередь доставки
+func (s *AuthService) RequeueOutboxMessage(id int64) error {
+	return s.repo.RequeueOutboxMessage(id)
+}
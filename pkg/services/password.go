@@ -0,0 +1,112 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sso/models"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Параметры паролей и токенов сброса
+const (
+	passwordResetTokenBytes  = 32             // Длина случайной части токена сброса
+	defaultPasswordMinLength = 8              // Минимальная длина пароля, если она не задана в конфигурации
+	defaultResetTokenTTL     = 24 * time.Hour // Время жизни токена сброса, если оно не задано в конфигурации
+)
+
+// ErrInvalidOldPassword возвращается, если при смене пароля указан неверный текущий пароль
+var ErrInvalidOldPassword = errors.New("invalid old password")
+
+// ChangePassword меняет пароль пользователя после проверки текущего и завершает все его сессии, кроме текущей
+func (s *AuthService) ChangePassword(userID, sessionID int, input models.ChangePasswordRequest) error {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	// Пользователи, созданные через Telegram, пароля не имеют и задают его через сброс
+	if user.PasswordHash == "" {
+		return ErrInvalidOldPassword
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.OldPassword)); err != nil {
+		return ErrInvalidOldPassword
+	}
+
+	passwordHash, err := s.newPasswordHash(input.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePasswordHash(userID, passwordHash); err != nil {
+		return err
+	}
+	_, err = s.repo.RevokeOtherSessions(userID, sessionID)
+	return err
+}
+
+// CreatePasswordReset выдает одноразовый токен для сброса пароля пользователя; прежние токены перестают работать
+func (s *AuthService) CreatePasswordReset(userID, adminID int) (models.PasswordResetToken, error) {
+	if _, err := s.repo.GetUserByID(userID); err != nil {
+		return models.PasswordResetToken{}, fmt.Errorf("user not found")
+	}
+
+	token, err := randomToken(passwordResetTokenBytes)
+	if err != nil {
+		return models.PasswordResetToken{}, err
+	}
+
+	ttl := s.cfg.Password.ResetTokenTTL
+	if ttl <= 0 {
+		ttl = defaultResetTokenTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+	if err := s.repo.CreatePasswordResetToken(userID, adminID, hashToken(token), expiresAt); err != nil {
+		return models.PasswordResetToken{}, err
+	}
+
+	return models.PasswordResetToken{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ResetPassword устанавливает новый пароль по одноразовому токену сброса
+func (s *AuthService) ResetPassword(input models.ResetPasswordRequest) error {
+	passwordHash, err := s.newPasswordHash(input.NewPassword)
+	if err != nil {
+		return err
+	}
+	userID, err := s.repo.ResetPasswordByToken(hashToken(input.Token), passwordHash)
+	if err != nil {
+		return err
+	}
+	// После сброса пароля прежние сессии могут принадлежать тому, кто завладел аккаунтом
+	_, err = s.repo.RevokeUserSessions(userID)
+	return err
+}
+
+// newPasswordHash проверяет требования к новому паролю и хеширует его
+func (s *AuthService) newPasswordHash(password string) (string, error) {
+	minLength := s.cfg.Password.MinLength
+	if minLength <= 0 {
+		minLength = defaultPasswordMinLength
+	}
+	if utf8.RuneCountInString(password) < minLength {
+		return "", fmt.Errorf("password must be at least %d characters long", minLength)
+	}
+	// bcrypt учитывает только первые 72 байта пароля
+	if len(password) > 72 {
+		return "", fmt.Errorf("password is too long")
+	}
+	return s.generatePasswordHash(password)
+}
+
+// hashToken возвращает SHA-256 токена для хранения в базе данных
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
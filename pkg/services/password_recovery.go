@@ -0,0 +1,123 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sso/models"
+	"time"
+)
+
+// Параметры кодов сброса пароля через Telegram
+const (
+	resetCodeDigits          = 6                // Число цифр в коде сброса
+	defaultResetCodeTTL      = 10 * time.Minute // Время жизни кода, если оно не задано в конфигурации
+	defaultResetCodeAttempts = 5                // Число попыток ввода кода, если оно не задано в конфигурации
+	defaultResetCodeInterval = time.Minute      // Интервал между отправками кода, если он не задан в конфигурации
+)
+
+// Ошибки сброса пароля через Telegram
+var (
+	ErrMessengerNotConfigured = errors.New("telegram bot is not configured")
+	ErrInvalidResetCode       = errors.New("reset code is invalid or expired")
+)
+
+// Messenger отправляет сообщения в привязанный чат пользователя
+type Messenger interface {
+	SendMessage(chatID int64, text string) error // Отправка текстового сообщения в чат
+}
+
+// RequestPasswordResetCode отправляет одноразовый код сброса пароля в привязанный чат Telegram.
+// Для неизвестного ника или пользователя без привязанного чата ошибка не возвращается,
+// чтобы по ответу нельзя было узнать, существует ли аккаунт
+func (s *AuthService) RequestPasswordResetCode(input models.ForgotPasswordRequest) error {
+	const op = "RequestPasswordResetCode"
+	if s.messenger == nil {
+		return ErrMessengerNotConfigured
+	}
+
+	user, err := s.repo.GetUserByTgName(input.TgNick)
+	if err != nil {
+		return nil
+	}
+	chatID, err := s.repo.GetTelegramChatID(user.ID)
+	if err != nil || chatID == 0 {
+		return nil
+	}
+
+	code, err := randomDigits(resetCodeDigits)
+	if err != nil {
+		return err
+	}
+	ttl := s.cfg.Password.ResetCodeTTL
+	if ttl <= 0 {
+		ttl = defaultResetCodeTTL
+	}
+	interval := s.cfg.Password.ResetCodeInterval
+	if interval <= 0 {
+		interval = defaultResetCodeInterval
+	}
+
+	// Повторный запрос раньше интервала не отправляет новый код, чтобы чат нельзя было засыпать сообщениями
+	created, err := s.repo.CreatePasswordResetCode(user.ID, hashToken(code), time.Now().Add(ttl), interval)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return nil
+	}
+
+	text := fmt.Sprintf("Код для сброса пароля: %s\nКод действует %d мин. Никому его не сообщайте.", code, int(ttl.Minutes()))
+	if err := s.messenger.SendMessage(chatID, text); err != nil {
+		log.Printf("%s: %v", op, err)
+	}
+	return nil
+}
+
+// ResetPasswordWithCode устанавливает новый пароль по коду из Telegram; каждая попытка уменьшает число оставшихся
+func (s *AuthService) ResetPasswordWithCode(input models.ResetPasswordByCodeRequest) error {
+	passwordHash, err := s.newPasswordHash(input.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByTgName(input.TgNick)
+	if err != nil {
+		return ErrInvalidResetCode
+	}
+
+	maxAttempts := s.cfg.Password.ResetCodeAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultResetCodeAttempts
+	}
+	codeHash, err := s.repo.UsePasswordResetCodeAttempt(user.ID, maxAttempts)
+	if err != nil {
+		return ErrInvalidResetCode
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(input.Code)), []byte(codeHash)) != 1 {
+		return ErrInvalidResetCode
+	}
+
+	if err := s.repo.ResetPasswordByCode(user.ID, codeHash, passwordHash); err != nil {
+		return ErrInvalidResetCode
+	}
+	// После сброса пароля прежние сессии могут принадлежать тому, кто завладел аккаунтом
+	_, err = s.repo.RevokeUserSessions(user.ID)
+	return err
+}
+
+// randomDigits генерирует криптографически случайный цифровой код заданной длины
+func randomDigits(size int) (string, error) {
+	code := make([]byte, size)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
@@ -1,10 +1,15 @@
 package services
 
 import (
+	"fmt"
 	"sso/models"
+	"time"
 )
 
 func (s *AuthService) CreateQueue(queue models.Queue) (int, error) {
+	if err := validateQueue(&queue); err != nil {
+		return 0, err
+	}
 	return s.repo.CreateQueue(queue)
 }
 
@@ -16,7 +21,51 @@ func (s *AuthService) GetAllQueues() ([]models.Queue, error) {
 	return s.repo.GetAllQueues()
 }
 
+// GetAvailableQueues возвращает незавершенные очереди, открытые для группы пользователя
+func (s *AuthService) GetAvailableQueues(userID int) ([]models.Queue, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	units, err := s.userOrgUnits(user)
+	if err != nil {
+		return nil, err
+	}
+	queues, err := s.repo.GetAllQueues()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	available := make([]models.Queue, 0, len(queues))
+	for _, queue := range queues {
+		if queue.TimeEnd.After(now) && queueAvailableFor(queue, user, units) {
+			available = append(available, queue)
+		}
+	}
+	return available, nil
+}
+
 func (s *AuthService) UpdateQueue(queue models.Queue) error {
+	// Если режим не передан, меняются только название и время, остальные настройки очереди сохраняются
+	if queue.Mode == "" {
+		current, err := s.repo.GetQueueByID(queue.ID)
+		if err != nil {
+			return err
+		}
+		queue.Mode = current.Mode
+		queue.SlotDuration = current.SlotDuration
+		queue.Capacity = current.Capacity
+		queue.Desks = current.Desks
+		queue.AllowedGroups = current.AllowedGroups
+		queue.AllowedUnits = current.AllowedUnits
+	}
+	if err := validateQueue(&queue); err != nil {
+		return err
+	}
+	if err := s.checkSlotSchedule(queue); err != nil {
+		return err
+	}
 	return s.repo.UpdateQueue(queue)
 }
 
@@ -24,8 +73,40 @@ func (s *AuthService) DeleteQueue(id int) error {
 	return s.repo.DeleteQueue(id)
 }
 
+// CloseQueue закрывает очередь для новых участников; текущие участники остаются в очереди
+func (s *AuthService) CloseQueue(id int) error {
+	return s.repo.CloseQueue(id)
+}
+
 // Queue Participants methods
 func (s *AuthService) JoinQueue(queueID, userID int) (int, error) {
+	queue, err := s.repo.GetQueueByID(queueID)
+	if err != nil {
+		return 0, err
+	}
+	if queue.Mode == models.QueueModeSlots {
+		return 0, fmt.Errorf("queue works in slot booking mode, book a slot instead")
+	}
+	if queue.ClosedAt != nil {
+		return 0, fmt.Errorf("queue is closed")
+	}
+	if err := s.checkQueueEligibility(queue, userID); err != nil {
+		return 0, err
+	}
+	if err := s.checkFairness(queueID, userID); err != nil {
+		return 0, err
+	}
+
+	// Проверяем ограничение на число активных участников
+	if queue.Capacity > 0 {
+		participants, err := s.repo.GetQueueParticipants(queueID)
+		if err != nil {
+			return 0, err
+		}
+		if len(participants) >= queue.Capacity {
+			return 0, fmt.Errorf("queue is full")
+		}
+	}
 	return s.repo.JoinQueue(queueID, userID)
 }
 
@@ -33,12 +114,36 @@ func (s *AuthService) LeaveQueue(queueID, userID int) error {
 	return s.repo.LeaveQueue(queueID, userID)
 }
 
+// GetQueuePosition возвращает позицию пользователя в очереди
+func (s *AuthService) GetQueuePosition(queueID, userID int) (int, error) {
+	position, err := s.repo.GetUserQueuePosition(queueID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("user is not in queue")
+	}
+	return position, nil
+}
+
 func (s *AuthService) GetQueueParticipants(queueID int) ([]models.QueueParticipant, error) {
 	return s.repo.GetQueueParticipants(queueID)
 }
 
-func (s *AuthService) ShiftQueue(queueID int) error {
-	return s.repo.ShiftQueue(queueID)
+// ExportQueueParticipants возвращает всех участников очереди для выгрузки, включая принятых
+func (s *AuthService) ExportQueueParticipants(queueID int) ([]models.ParticipantExportRow, error) {
+	if _, err := s.repo.GetQueueByID(queueID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetParticipantsExport(queueID)
+}
+
+// ShiftQueue убирает первого участника из очереди; outcome задает итог приема (served или no_show)
+func (s *AuthService) ShiftQueue(queueID int, outcome string) error {
+	if outcome == "" {
+		outcome = models.EventServed
+	}
+	if outcome != models.EventServed && outcome != models.EventNoShow {
+		return fmt.Errorf("unknown shift outcome %q", outcome)
+	}
+	return s.repo.ShiftQueue(queueID, outcome)
 }
 
 // User management methods
@@ -50,10 +155,123 @@ func (s *AuthService) GetAllUsers() ([]models.User, error) {
 	return s.repo.GetAllUsers()
 }
 
-func (s *AuthService) UpdateUser(id int, user models.User) error {
-	return s.repo.UpdateUser(id, user)
+// UpdateUser обновляет данные пользователя; при смене состава групп пользователь должен остаться хотя бы в одной
+// и выводится из очередей, в которые он больше не допущен
+func (s *AuthService) UpdateUser(id int, user models.User) (models.GroupMembershipChange, error) {
+	if user.GroupIDs != nil && len(user.GroupIDs) == 0 {
+		return models.GroupMembershipChange{}, ErrGroupRequired
+	}
+	if err := s.repo.UpdateUser(id, user); err != nil {
+		return models.GroupMembershipChange{}, err
+	}
+	if user.GroupIDs == nil {
+		return models.GroupMembershipChange{UserID: id, LeftQueues: []int{}}, nil
+	}
+	return s.leaveIneligibleQueues(id)
 }
 
 func (s *AuthService) DeleteUser(id int) error {
 	return s.repo.DeleteUser(id)
 }
+
+// checkQueueEligibility проверяет, что одна из групп пользователя или их факультет или кафедра допущены в очередь
+func (s *AuthService) checkQueueEligibility(queue models.Queue, userID int) error {
+	if len(queue.AllowedGroups) == 0 && len(queue.AllowedUnits) == 0 {
+		return nil
+	}
+
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	units, err := s.userOrgUnits(user)
+	if err != nil {
+		return err
+	}
+	if !queueAvailableFor(queue, user, units) {
+		return fmt.Errorf("queue is not available for your group")
+	}
+
+	return nil
+}
+
+// queueAvailableFor сообщает, допущена ли в очередь одна из групп пользователя или одна из единиц, в которые они входят
+func queueAvailableFor(queue models.Queue, user models.User, units []int64) bool {
+	if len(queue.AllowedGroups) == 0 && len(queue.AllowedUnits) == 0 {
+		return true
+	}
+	for _, groupID := range queue.AllowedGroups {
+		if user.InGroup(int(groupID)) {
+			return true
+		}
+	}
+	for _, unitID := range queue.AllowedUnits {
+		for _, userUnitID := range units {
+			if unitID == userUnitID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkFairness проверяет правила честного присоединения к очереди и возвращает *PolicyError при нарушении
+func (s *AuthService) checkFairness(queueID, userID int) error {
+	policy := s.cfg.Fairness
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	stats, err := s.repo.GetJoinStats(queueID, userID, dayStart)
+	if err != nil {
+		return err
+	}
+
+	if policy.MaxActiveQueues > 0 && stats.ActiveQueues >= policy.MaxActiveQueues {
+		return newPolicyError(PolicyMaxActiveQueues, 0,
+			"you are already in %d queues, leave one of them first", stats.ActiveQueues)
+	}
+
+	if policy.RejoinCooldown > 0 && stats.LastLeftAt.Valid {
+		if wait := stats.LastLeftAt.Time.Add(policy.RejoinCooldown).Sub(now); wait > 0 {
+			return newPolicyError(PolicyRejoinCooldown, wait,
+				"you left this queue recently, you can rejoin in %s", wait.Round(time.Second))
+		}
+	}
+
+	if policy.DailyJoinLimit > 0 && stats.JoinsSince >= policy.DailyJoinLimit {
+		return newPolicyError(PolicyDailyJoinLimit, dayStart.AddDate(0, 0, 1).Sub(now),
+			"daily limit of %d queue joins reached", policy.DailyJoinLimit)
+	}
+
+	return nil
+}
+
+// validateQueue проверяет настройки очереди и подставляет значения по умолчанию
+func validateQueue(queue *models.Queue) error {
+	if queue.Mode == "" {
+		queue.Mode = models.QueueModeFIFO
+	}
+	if queue.Desks == 0 {
+		queue.Desks = 1
+	}
+	if queue.Capacity < 0 || queue.Desks < 0 {
+		return fmt.Errorf("capacity and desks must not be negative")
+	}
+
+	switch queue.Mode {
+	case models.QueueModeFIFO:
+		queue.SlotDuration = 0
+	case models.QueueModeSlots:
+		if queue.SlotDuration <= 0 {
+			return fmt.Errorf("slot duration must be positive in slot mode")
+		}
+		// Интервал приема должен вмещать хотя бы один слот
+		if queue.TimeEnd.Sub(queue.TimeStart) < time.Duration(queue.SlotDuration)*time.Minute {
+			return fmt.Errorf("queue time range is shorter than one slot")
+		}
+	default:
+		return fmt.Errorf("unknown queue mode %q", queue.Mode)
+	}
+
+	return nil
+}
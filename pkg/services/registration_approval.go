@@ -0,0 +1,41 @@
+package services
+
+import (
+	"errors"
+	"sso/models"
+)
+
+// ErrAccountPendingApproval возвращается при входе и запросах пользователя, чья регистрация еще не подтверждена
+var ErrAccountPendingApproval = errors.New("account is pending approval by a group moderator")
+
+// registrationStatus возвращает статус пользователя, регистрирующегося самостоятельно
+func (s *AuthService) registrationStatus() string {
+	if s.cfg.Registration.RequireApproval {
+		return models.UserStatusPending
+	}
+	return models.UserStatusActive
+}
+
+// GetPendingUsers возвращает очередь неподтвержденных регистраций группы
+func (s *AuthService) GetPendingUsers(userID int, isAdmin bool, groupID int) ([]models.User, error) {
+	if err := s.checkGroupModerator(userID, isAdmin, groupID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPendingUsers(groupID)
+}
+
+// ApproveUser подтверждает регистрацию пользователя группы, после чего он может войти в систему
+func (s *AuthService) ApproveUser(userID int, isAdmin bool, groupID, pendingUserID int) error {
+	if err := s.checkGroupModerator(userID, isAdmin, groupID); err != nil {
+		return err
+	}
+	return s.repo.ApproveUser(pendingUserID, groupID, userID)
+}
+
+// RejectUser отклоняет регистрацию и удаляет неподтвержденного пользователя группы
+func (s *AuthService) RejectUser(userID int, isAdmin bool, groupID, pendingUserID int) error {
+	if err := s.checkGroupModerator(userID, isAdmin, groupID); err != nil {
+		return err
+	}
+	return s.repo.RejectUser(pendingUserID, groupID)
+}
@@ -2,6 +2,7 @@
 package services
 
 import (
+	"io"
 	"sso/models"
 	"sso/pkg/repository"
 	"time"
@@ -17,10 +18,81 @@ const (
 
 // Authorization определяет интерфейс для работы с авторизацией и управлением данными
 type Authorization interface {
+	// Арендаторы
+	ForTenant(tenantID int) Authorization                       // Сервис, ограниченный данными арендатора
+	GetTenantByCode(code string) (models.Tenant, error)         // Получение арендатора по коду
+	GetTenants() ([]models.Tenant, error)                       // Все арендаторы
+	CreateTenant(input models.CreateTenantRequest) (int, error) // Создание арендатора и его администратора
+
 	// Аутентификация и авторизация
-	CreateUser(user models.RegisterUser) (int, error) // Создание нового пользователя
-	NewToken(user models.AuthUser) (string, error)    // Генерация JWT токена
-	ParseToken(tokenStr string) (int, bool, error)    // Парсинг JWT токена
+	CreateUser(user models.RegisterUser) (int, error)                                                            // Создание нового пользователя
+	Authenticate(input models.AuthUser) (models.User, error)                                                     // Проверка Telegram ника и пароля
+	SignIn(input models.AuthUser, client models.ClientInfo) (models.SignInResult, error)                         // Вход по паролю с учетом блокировки аккаунта
+	ParseToken(tokenStr string) (models.TokenIdentity, error)                                                    // Парсинг JWT токена и проверка его сессии
+	SignInWithTelegram(input models.TelegramLoginRequest, client models.ClientInfo) (models.SignInResult, error) // Вход через Telegram Login Widget
+
+	// Двухфакторная аутентификация
+	CompleteTwoFactorSignIn(input models.TwoFactorSignInRequest, client models.ClientInfo) (string, error) // Второй шаг входа по коду
+	SetupTwoFactor(userID int) (models.TwoFactorSetup, error)                                              // Выдача секрета TOTP
+	ConfirmTwoFactor(userID int, code string) ([]string, error)                                            // Подтверждение TOTP и выдача резервных кодов
+	DisableTwoFactor(userID int, code string) error                                                        // Отключение TOTP
+	RegenerateRecoveryCodes(userID int, code string) ([]string, error)                                     // Новые резервные коды
+
+	// API-ключи
+	CreateAPIKey(userID int, isAdmin bool, input models.CreateAPIKeyRequest) (models.CreatedAPIKey, error) // Создание API-ключа
+	GetAPIKeys(userID int) ([]models.APIKey, error)                                                        // Список API-ключей пользователя
+	GetAllAPIKeys() ([]models.APIKey, error)                                                               // Список всех API-ключей
+	RevokeAPIKey(userID, keyID int, isAdmin bool) error                                                    // Отзыв API-ключа
+	AuthenticateAPIKey(key string) (models.APIKeyIdentity, error)                                          // Проверка API-ключа
+
+	// Сессии
+	GetSessions(userID, currentID int) ([]models.Session, error) // Активные сессии пользователя
+	RevokeSession(userID, sessionID int) error                   // Выход из сессии
+	SignOutEverywhere(userID int) (models.SignOutResult, error)  // Выход со всех устройств: сессии, API-ключи и чат бота
+
+	// Модераторы групп и приглашения
+	AddGroupModerator(groupID, userID int) error                                                                                    // Назначение модератора группы
+	RemoveGroupModerator(groupID, userID int) error                                                                                 // Снятие модератора группы
+	GetGroupModerators(groupID int) ([]models.User, error)                                                                          // Модераторы группы
+	CreateInvitation(userID int, isAdmin bool, groupID int, input models.CreateInvitationRequest) (models.CreatedInvitation, error) // Выдача приглашения в группу
+	GetInvitations(userID int, isAdmin bool, groupID int) ([]models.Invitation, error)                                              // Приглашения группы
+	RevokeInvitation(userID int, isAdmin bool, groupID, invitationID int) error                                                     // Отзыв приглашения
+
+	// Участники групп
+	GetGroupMembers(userID int, isAdmin bool, groupID int) ([]models.GroupMember, error)                                                          // Участники группы
+	AddGroupMember(userID int, isAdmin bool, groupID, memberID int) error                                                                         // Добавление участника группы
+	RemoveGroupMember(userID int, isAdmin bool, groupID, memberID int) (models.GroupMembershipChange, error)                                      // Исключение участника из группы
+	TransferGroupMember(userID int, isAdmin bool, groupID, memberID int, input models.GroupTransferRequest) (models.GroupMembershipChange, error) // Перевод участника в другую группу
+
+	// Подтверждение регистрации
+	GetPendingUsers(userID int, isAdmin bool, groupID int) ([]models.User, error) // Очередь неподтвержденных регистраций группы
+	ApproveUser(userID int, isAdmin bool, groupID, pendingUserID int) error       // Подтверждение регистрации
+	RejectUser(userID int, isAdmin bool, groupID, pendingUserID int) error        // Отклонение регистрации
+
+	// Организационная структура
+	CreateOrgUnit(userID int, isAdmin bool, input models.OrgUnitRequest) (int, error)  // Создание факультета или кафедры
+	GetOrgUnits() ([]models.OrgUnit, error)                                            // Все организационные единицы
+	GetOrgUnit(id int) (models.OrgUnitDetails, error)                                  // Единица с дочерними единицами и группами
+	UpdateOrgUnit(userID int, isAdmin bool, id int, input models.OrgUnitRequest) error // Изменение единицы
+	DeleteOrgUnit(userID int, isAdmin bool, id int) error                              // Удаление пустой единицы
+	SetGroupOrgUnit(userID int, isAdmin bool, unitID, groupID int) error               // Включение группы в единицу
+	RemoveGroupOrgUnit(userID int, isAdmin bool, unitID, groupID int) error            // Вывод группы из единицы
+	AddOrgUnitAdmin(userID int, isAdmin bool, unitID, adminID int) error               // Назначение администратора единицы
+	RemoveOrgUnitAdmin(userID int, isAdmin bool, unitID, adminID int) error            // Снятие администратора единицы
+	GetOrgUnitAdmins(userID int, isAdmin bool, unitID int) ([]models.User, error)      // Администраторы единицы
+	CheckQueueManager(userID int, isAdmin bool, queueID int) error                     // Право управлять очередью
+	CheckQueueRestrictions(userID int, isAdmin bool, queue models.Queue) error         // Право задать ограничения допуска очереди
+	CheckStatsAccess(userID int, isAdmin bool, filter models.StatsFilter) error        // Право на сводную статистику с фильтром
+
+	// Массовый импорт
+	ImportUsers(adminID int, data io.Reader, dryRun bool) (models.ImportUsersResult, error) // Импорт студентов из CSV
+
+	// Пароли
+	ChangePassword(userID, sessionID int, input models.ChangePasswordRequest) error // Смена пароля с проверкой текущего
+	CreatePasswordReset(userID, adminID int) (models.PasswordResetToken, error)     // Выдача токена для сброса пароля
+	ResetPassword(input models.ResetPasswordRequest) error                          // Установка пароля по токену сброса
+	RequestPasswordResetCode(input models.ForgotPasswordRequest) error              // Отправка кода сброса пароля в Telegram
+	ResetPasswordWithCode(input models.ResetPasswordByCodeRequest) error            // Установка пароля по коду из Telegram
 
 	// Управление группами
 	CreateGroup(code, comment string) (int, error)    // Создание новой группы
@@ -31,34 +103,87 @@ type Authorization interface {
 	DeleteGroup(id int) error                         // Удаление группы
 
 	// Управление очередями
-	CreateQueue(queue models.Queue) (int, error) // Создание новой очереди
-	GetQueueByID(id int) (models.Queue, error)   // Получение очереди по ID
-	GetAllQueues() ([]models.Queue, error)       // Получение всех очередей
-	UpdateQueue(queue models.Queue) error        // Обновление очереди
-	DeleteQueue(id int) error                    // Удаление очереди
+	CreateQueue(queue models.Queue) (int, error)           // Создание новой очереди
+	GetQueueByID(id int) (models.Queue, error)             // Получение очереди по ID
+	GetAllQueues() ([]models.Queue, error)                 // Получение всех очередей
+	GetAvailableQueues(userID int) ([]models.Queue, error) // Получение незавершенных очередей, доступных пользователю
+	UpdateQueue(queue models.Queue) error                  // Обновление очереди
+	DeleteQueue(id int) error                              // Удаление очереди
+	CloseQueue(id int) error                               // Закрытие очереди для новых участников
+
+	// Шаблоны и клонирование очередей
+	CreateQueueTemplate(template models.QueueTemplate) (int, error)                          // Создание шаблона очереди
+	GetQueueTemplateByID(id int) (models.QueueTemplate, error)                               // Получение шаблона по ID
+	GetAllQueueTemplates() ([]models.QueueTemplate, error)                                   // Получение всех шаблонов
+	DeleteQueueTemplate(id int) error                                                        // Удаление шаблона
+	CloneQueue(queueID int, schedule models.ScheduleRequest) ([]int, error)                  // Клонирование очереди на новое время
+	CreateQueuesFromTemplate(templateID int, schedule models.ScheduleRequest) ([]int, error) // Создание очередей по шаблону
 
 	// Управление участниками очередей
-	JoinQueue(queueID, userID int) (int, error)                          // Присоединение к очереди
-	LeaveQueue(queueID, userID int) error                                // Покидание очереди
-	GetQueueParticipants(queueID int) ([]models.QueueParticipant, error) // Получение участников очереди
-	ShiftQueue(queueID int) error                                        // Сдвиг очереди
+	JoinQueue(queueID, userID int) (int, error)                                 // Присоединение к очереди
+	LeaveQueue(queueID, userID int) error                                       // Покидание очереди
+	GetQueuePosition(queueID, userID int) (int, error)                          // Получение позиции пользователя в очереди
+	GetQueueParticipants(queueID int) ([]models.QueueParticipant, error)        // Получение участников очереди
+	ExportQueueParticipants(queueID int) ([]models.ParticipantExportRow, error) // Участники очереди для выгрузки
+	ShiftQueue(queueID int, outcome string) error                               // Сдвиг очереди с итогом приема первого участника
+
+	// Запись на слоты
+	GetQueueSlots(queueID, userID int) ([]models.QueueSlot, error) // Получение слотов очереди с отметкой занятости
+	GetQueueTimetable(queueID int) ([]models.QueueSlot, error)     // Получение расписания слотов с записавшимися
+	BookSlot(queueID, userID, slot int) (int, error)               // Запись на слот
+	RescheduleSlot(queueID, userID, slot int) error                // Перенос записи на другой слот
+	CancelSlot(queueID, userID int) error                          // Отмена записи на слот
+
+	// Ленты календаря
+	GetCalendarURL(userID int) (string, error)    // Выдача ссылки на персональную ленту календаря
+	ResetCalendarURL(userID int) (string, error)  // Выдача новой ссылки на ленту календаря
+	GetUserCalendar(token string) (string, error) // Персональная лента iCalendar по токену
+	GetQueueCalendar(queueID int) (string, error) // Файл iCalendar одной очереди
+
+	// Telegram
+	CreateTelegramLink(userID int) (models.TelegramLink, error)            // Выдача ссылки для привязки чата Telegram
+	LinkTelegramChat(code string, chatID int64) (models.User, error)       // Привязка чата Telegram по коду
+	UnlinkTelegramChat(userID int) error                                   // Отвязка своего чата Telegram
+	GetUserByTelegramChat(chatID int64) (models.User, error)               // Получение пользователя по чату Telegram
+	LinkTelegramLogin(userID int, input models.TelegramLoginRequest) error // Подключение входа через Telegram к своему аккаунту
+
+	// Outbox
+	GetOutboxMessages(status string) ([]models.OutboxMessage, error) // Сообщения outbox в указанном состоянии
+	RequeueOutboxMessage(id int64) error                             // Повторная доставка сообщения из dead-letter
+
+	// Webhook-подписки
+	CreateWebhook(input models.CreateWebhookRequest) (models.Webhook, error) // Создание подписки
+	GetAllWebhooks() ([]models.Webhook, error)                               // Получение всех подписок
+	DeleteWebhook(id int) error                                              // Удаление подписки
+	GetWebhookDeliveries(webhookID int) ([]models.WebhookDelivery, error)    // Журнал доставки подписки
+
+	// Статистика участия в очередях
+	GetQueueStats(queueID int) (models.StatsReport, error)          // Статистика одной очереди
+	GetStats(filter models.StatsFilter) (models.StatsReport, error) // Сводная статистика по очередям
 
 	// Управление пользователями
-	GetUserByID(id int) (models.User, error)   // Получение пользователя по ID
-	GetAllUsers() ([]models.User, error)       // Получение всех пользователей
-	UpdateUser(id int, user models.User) error // Обновление пользователя
-	DeleteUser(id int) error                   // Удаление пользователя
+	GetUserByID(id int) (models.User, error)                                   // Получение пользователя по ID
+	GetAllUsers() ([]models.User, error)                                       // Получение всех пользователей
+	UpdateUser(id int, user models.User) (models.GroupMembershipChange, error) // Обновление пользователя
+	DeleteUser(id int) error                                                   // Удаление пользователя
+	UnlockUser(userID int) error                                               // Снятие блокировки входа
 }
 
 // AuthService реализует интерфейс Authorization и содержит бизнес-логику приложения
 type AuthService struct {
-	repo repository.Repository // Репозиторий для работы с базой данных
+	repo      repository.Repository // Репозиторий для работы с базой данных
+	cfg       models.Config         // Конфигурация приложения
+	messenger Messenger             // Отправка сообщений в Telegram (nil - бот не настроен)
+	tenantID  int                   // Арендатор, данными которого ограничен сервис
 }
 
-// NewAuthService создает новый экземпляр сервиса авторизации
-func NewAuthService(repo repository.Repository) *AuthService {
+// NewAuthService создает новый экземпляр сервиса авторизации; сервис для работы с данными арендатора
+// получается из него вызовом ForTenant
+func NewAuthService(repo repository.Repository, cfg models.Config, messenger Messenger) *AuthService {
 	return &AuthService{
-		repo: repo,
+		repo:      repo,
+		cfg:       cfg,
+		messenger: messenger,
 	}
 }
 
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"sso/models"
+)
+
+// GetSessions возвращает активные сессии пользователя и отмечает текущую
+func (s *AuthService) GetSessions(userID, currentID int) ([]models.Session, error) {
+	sessions, err := s.repo.GetActiveSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeSession завершает сессию пользователя; выданный ей токен перестает действовать
+func (s *AuthService) RevokeSession(userID, sessionID int) error {
+	return s.repo.RevokeSession(sessionID, userID)
+}
+
+// SignOutEverywhere принудительно выводит пользователя со всех устройств: завершает сессии, отзывает API-ключи
+// и отвязывает чат Telegram бота, чтобы ни один из способов входа не продолжал работать
+func (s *AuthService) SignOutEverywhere(userID int) (models.SignOutResult, error) {
+	return s.repo.SignOutEverywhere(userID)
+}
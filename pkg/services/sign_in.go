@@ -0,0 +1,101 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"sso/models"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials возвращается при неверном нике или пароле; причина не уточняется,
+// чтобы по ответу нельзя было узнать, существует ли пользователь
+var ErrInvalidCredentials = errors.New("invalid tg_nick or password")
+
+// dummyPasswordHash сравнивается с паролем, если пользователь не найден или у него нет пароля,
+// чтобы время ответа не зависело от существования аккаунта
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password for timing"), bcrypt.DefaultCost)
+
+// Authenticate проверяет Telegram ник и пароль и возвращает пользователя
+func (s *AuthService) Authenticate(input models.AuthUser) (models.User, error) {
+	user, err := s.repo.GetUserByTgName(input.TgNick)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, err
+	}
+
+	// Пользователи, созданные через Telegram, пароля не имеют и входят только через Telegram
+	hash := []byte(user.PasswordHash)
+	found := err == nil && user.PasswordHash != ""
+	if !found {
+		hash = dummyPasswordHash
+	}
+
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(input.Password)); err != nil || !found {
+		return models.User{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// SignIn выполняет вход по Telegram нику и паролю с учетом временной блокировки аккаунта;
+// при подключенной двухфакторной аутентификации вместо JWT токена выдается токен первого шага
+func (s *AuthService) SignIn(input models.AuthUser, client models.ClientInfo) (models.SignInResult, error) {
+	const op = "SignIn"
+
+	// Неудачные попытки и блокировка учитываются по нику и для несуществующих пользователей,
+	// чтобы по блокировке нельзя было узнать, существует ли аккаунт
+	lockedUntil, err := s.repo.GetSignInLockedUntil(signInKey(input.TgNick))
+	if err != nil {
+		return models.SignInResult{}, err
+	}
+	if lockedUntil.Valid && lockedUntil.Time.After(time.Now()) {
+		return models.SignInResult{}, accountLockedError(lockedUntil.Time)
+	}
+
+	user, err := s.Authenticate(input)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			if lockErr := s.recordSignInFailure(input.TgNick); lockErr != nil {
+				return models.SignInResult{}, lockErr
+			}
+		}
+		return models.SignInResult{}, err
+	}
+
+	if err := s.repo.ResetSignInFailures(user.ID); err != nil {
+		log.Printf("%s: %v", op, err)
+	}
+	return s.completeSignIn(user, client)
+}
+
+// UnlockUser снимает блокировку входа и сбрасывает счетчик неудачных попыток
+func (s *AuthService) UnlockUser(userID int) error {
+	return s.repo.ResetSignInFailures(userID)
+}
+
+// signInKey нормализует Telegram ник для учета неудачных попыток входа так же, как ключ ограничения частоты
+func signInKey(tgNick string) string {
+	return strings.ToLower(tgNick)
+}
+
+// recordSignInFailure учитывает неудачную попытку входа; возвращает ошибку блокировки, если аккаунт заблокирован
+func (s *AuthService) recordSignInFailure(tgNick string) error {
+	if s.cfg.SignIn.MaxFailures <= 0 || s.cfg.SignIn.LockoutDuration <= 0 {
+		return nil
+	}
+	lockedUntil, err := s.repo.RecordSignInFailure(signInKey(tgNick), s.cfg.SignIn.MaxFailures, s.cfg.SignIn.LockoutDuration)
+	if err != nil {
+		return err
+	}
+	if lockedUntil.Valid && lockedUntil.Time.After(time.Now()) {
+		return accountLockedError(lockedUntil.Time)
+	}
+	return nil
+}
+
+// accountLockedError формирует ошибку временной блокировки входа
+func accountLockedError(lockedUntil time.Time) *PolicyError {
+	return newPolicyError(PolicyAccountLocked, time.Until(lockedUntil), "account is temporarily locked after too many failed sign-in attempts")
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"fmt"
+	"sso/models"
+	"time"
+)
+
+// GetQueueSlots возвращает слоты очереди с отметкой занятости без данных других пользователей
+func (s *AuthService) GetQueueSlots(queueID, userID int) ([]models.QueueSlot, error) {
+	slots, err := s.queueTimetable(queueID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range slots {
+		slots[i].IsMine = slots[i].UserID == userID
+		slots[i].UserID = 0
+		slots[i].Username = ""
+		slots[i].TgNick = ""
+	}
+
+	return slots, nil
+}
+
+// GetQueueTimetable возвращает расписание слотов очереди вместе с записавшимися пользователями
+func (s *AuthService) GetQueueTimetable(queueID int) ([]models.QueueSlot, error) {
+	return s.queueTimetable(queueID)
+}
+
+// BookSlot записывает пользователя на свободный слот
+func (s *AuthService) BookSlot(queueID, userID, slot int) (int, error) {
+	queue, err := s.bookableSlot(queueID, slot)
+	if err != nil {
+		return 0, err
+	}
+	if queue.ClosedAt != nil {
+		return 0, fmt.Errorf("queue is closed")
+	}
+	if err := s.checkQueueEligibility(queue, userID); err != nil {
+		return 0, err
+	}
+	return s.repo.BookSlot(queueID, userID, slot)
+}
+
+// RescheduleSlot переносит запись пользователя на другой свободный слот
+func (s *AuthService) RescheduleSlot(queueID, userID, slot int) error {
+	queue, err := s.bookableSlot(queueID, slot)
+	if err != nil {
+		return err
+	}
+	if err := s.checkBookingChangeable(queue, userID); err != nil {
+		return err
+	}
+	return s.repo.RescheduleSlot(queueID, userID, slot)
+}
+
+// CancelSlot отменяет запись пользователя на слот
+func (s *AuthService) CancelSlot(queueID, userID int) error {
+	queue, err := s.slotQueue(queueID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkBookingChangeable(queue, userID); err != nil {
+		return err
+	}
+	return s.repo.CancelSlotBooking(queueID, userID)
+}
+
+// checkBookingChangeable запрещает переносить и отменять запись в закрытой очереди и на слот, прием в котором уже начался
+func (s *AuthService) checkBookingChangeable(queue models.Queue, userID int) error {
+	if queue.ClosedAt != nil {
+		return fmt.Errorf("queue is closed")
+	}
+
+	bookings, err := s.repo.GetSlotBookings(queue.ID)
+	if err != nil {
+		return err
+	}
+	slots := buildSlots(queue)
+	for _, booking := range bookings {
+		if booking.UserID != userID || booking.SlotNumber < 1 || booking.SlotNumber > len(slots) {
+			continue
+		}
+		if !slots[booking.SlotNumber-1].TimeStart.After(time.Now()) {
+			return fmt.Errorf("slot %d has already started", booking.SlotNumber)
+		}
+	}
+	return nil
+}
+
+// checkSlotSchedule запрещает менять расписание слотов, на которые уже записаны пользователи: записи хранят номер слота,
+// поэтому перенос начала или изменение длины слота молча перенесли бы записавшихся на другое время
+func (s *AuthService) checkSlotSchedule(queue models.Queue) error {
+	current, err := s.repo.GetQueueByID(queue.ID)
+	if err != nil {
+		return err
+	}
+	if current.Mode != models.QueueModeSlots {
+		return nil
+	}
+
+	bookings, err := s.repo.GetSlotBookings(queue.ID)
+	if err != nil {
+		return err
+	}
+	lastSlot := 0
+	for _, booking := range bookings {
+		if booking.SlotNumber > lastSlot {
+			lastSlot = booking.SlotNumber
+		}
+	}
+	if lastSlot > 0 && !current.KeepsSlots(queue, lastSlot) {
+		return models.ErrSlotBookingsExist
+	}
+	return nil
+}
+
+// queueTimetable строит слоты очереди и заполняет их записями из базы данных
+func (s *AuthService) queueTimetable(queueID int) ([]models.QueueSlot, error) {
+	queue, err := s.slotQueue(queueID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.repo.GetSlotBookings(queueID)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := buildSlots(queue)
+	for _, booking := range bookings {
+		// Расписание нельзя сократить при существующих записях, но запись вне расписания не должна ломать ответ
+		if booking.SlotNumber < 1 || booking.SlotNumber > len(slots) {
+			continue
+		}
+		slot := &slots[booking.SlotNumber-1]
+		slot.IsFree = false
+		slot.UserID = booking.UserID
+		slot.Username = booking.Username
+		slot.TgNick = booking.TgNick
+	}
+
+	return slots, nil
+}
+
+// bookableSlot проверяет, что на слот с указанным номером можно записаться, и возвращает очередь
+func (s *AuthService) bookableSlot(queueID, number int) (models.Queue, error) {
+	queue, err := s.slotQueue(queueID)
+	if err != nil {
+		return queue, err
+	}
+
+	slots := buildSlots(queue)
+	if number < 1 || number > len(slots) {
+		return queue, fmt.Errorf("slot %d does not exist", number)
+	}
+
+	if !slots[number-1].TimeStart.After(time.Now()) {
+		return queue, fmt.Errorf("slot %d has already started", number)
+	}
+
+	return queue, nil
+}
+
+// slotQueue возвращает очередь и проверяет, что она работает в режиме записи на слоты
+func (s *AuthService) slotQueue(queueID int) (models.Queue, error) {
+	queue, err := s.repo.GetQueueByID(queueID)
+	if err != nil {
+		return queue, err
+	}
+	if queue.Mode != models.QueueModeSlots {
+		return queue, fmt.Errorf("queue does not work in slot booking mode")
+	}
+	return queue, nil
+}
+
+// buildSlots делит интервал приема очереди на слоты; неполный хвост интервала отбрасывается
+func buildSlots(queue models.Queue) []models.QueueSlot {
+	duration := time.Duration(queue.SlotDuration) * time.Minute
+	count := queue.SlotCount()
+	slots := make([]models.QueueSlot, 0, count)
+	for i := 0; i < count; i++ {
+		start := queue.TimeStart.Add(time.Duration(i) * duration)
+		slots = append(slots, models.QueueSlot{
+			Number:    i + 1,
+			TimeStart: start,
+			TimeEnd:   start.Add(duration),
+			IsFree:    true,
+		})
+	}
+
+	return slots
+}
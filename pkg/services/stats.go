@@ -0,0 +1,62 @@
+package services
+
+import (
+	"sso/models"
+)
+
+// GetQueueStats возвращает статистику участия в одной очереди
+func (s *AuthService) GetQueueStats(queueID int) (models.StatsReport, error) {
+	if _, err := s.repo.GetQueueByID(queueID); err != nil {
+		return models.StatsReport{}, err
+	}
+	return s.GetStats(models.StatsFilter{QueueID: queueID})
+}
+
+// GetStats возвращает сводную статистику, разбивку по очередям и посещаемость групп по фильтру
+func (s *AuthService) GetStats(filter models.StatsFilter) (models.StatsReport, error) {
+	queues, err := s.repo.GetQueueStats(filter)
+	if err != nil {
+		return models.StatsReport{}, err
+	}
+
+	groups, err := s.repo.GetGroupAttendance(filter)
+	if err != nil {
+		return models.StatsReport{}, err
+	}
+
+	report := models.StatsReport{
+		Queues: make([]models.QueueStats, 0, len(queues)),
+		Groups: groups,
+	}
+	if report.Groups == nil {
+		report.Groups = []models.GroupAttendance{}
+	}
+
+	// Сводное среднее ожидание взвешиваем по числу принятых в каждой очереди
+	var totalWait float64
+	for _, queue := range queues {
+		queue.NoShowRate = noShowRate(queue)
+		report.Queues = append(report.Queues, queue)
+
+		report.Total.Joined += queue.Joined
+		report.Total.Left += queue.Left
+		report.Total.Served += queue.Served
+		report.Total.NoShow += queue.NoShow
+		totalWait += queue.AvgWaitSeconds * float64(queue.Served)
+	}
+	if report.Total.Served > 0 {
+		report.Total.AvgWaitSeconds = totalWait / float64(report.Total.Served)
+	}
+	report.Total.NoShowRate = noShowRate(report.Total)
+
+	return report, nil
+}
+
+// noShowRate вычисляет долю неявок среди вызванных участников
+func noShowRate(stats models.QueueStats) float64 {
+	called := stats.Served + stats.NoShow
+	if called == 0 {
+		return 0
+	}
+	return float64(stats.NoShow) / float64(called)
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sso/models"
+	"sso/pkg/telegram"
+	"strings"
+	"time"
+)
+
+// ErrTelegramLinkRequired возвращается при входе через Telegram, если ник занят аккаунтом с паролем:
+// владелец аккаунта должен войти по паролю и подтвердить привязку Telegram в профиле
+var ErrTelegramLinkRequired = errors.New("an account with this nick already exists: sign in with the password and link telegram in the profile")
+
+// SignInWithTelegram проверяет данные Telegram Login Widget, находит или создает пользователя
+// по ID Telegram и возвращает JWT токен или требование второго шага входа
+func (s *AuthService) SignInWithTelegram(input models.TelegramLoginRequest, client models.ClientInfo) (models.SignInResult, error) {
+	const op = "SignInWithTelegram"
+
+	if err := s.verifyTelegramLogin(input); err != nil {
+		return models.SignInResult{}, err
+	}
+
+	user, err := s.telegramUser(input)
+	if err != nil {
+		log.Printf("%s: %v", op, err)
+		return models.SignInResult{}, err
+	}
+	return s.completeSignIn(user, client)
+}
+
+// LinkTelegramLogin подключает вход через Telegram к аккаунту вошедшего пользователя по данным Telegram Login Widget
+func (s *AuthService) LinkTelegramLogin(userID int, input models.TelegramLoginRequest) error {
+	if err := s.verifyTelegramLogin(input); err != nil {
+		return err
+	}
+
+	linked, err := s.repo.GetUserByTelegramID(input.ID)
+	if err == nil {
+		if linked.ID == userID {
+			return nil
+		}
+		return fmt.Errorf("telegram account is already linked to another user")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return s.repo.LinkTelegramUser(userID, input.ID)
+}
+
+// verifyTelegramLogin проверяет подпись и срок действия данных Telegram Login Widget
+func (s *AuthService) verifyTelegramLogin(input models.TelegramLoginRequest) error {
+	if s.cfg.Telegram.BotToken == "" {
+		return fmt.Errorf("telegram login is not configured")
+	}
+	return telegram.VerifyLogin(s.cfg.Telegram.BotToken, input.CheckFields(), input.Hash,
+		time.Unix(input.AuthDate, 0), time.Now(), s.cfg.Telegram.LoginMaxAge)
+}
+
+// telegramUser возвращает пользователя с подтвержденным аккаунтом Telegram: уже привязанного,
+// зарегистрированного ранее с тем же ником без пароля или нового
+func (s *AuthService) telegramUser(input models.TelegramLoginRequest) (models.User, error) {
+	user, err := s.repo.GetUserByTelegramID(input.ID)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, err
+	}
+
+	// Вход через Telegram подтверждает владение ником, поэтому подключаем его к существующему пользователю без пароля
+	// (например, импортированному из CSV). Аккаунт с паролем мог зарегистрировать на чужой ник кто угодно,
+	// поэтому его владелец подтверждает привязку сам после входа по паролю
+	tgNick := fmt.Sprintf("tg%d", input.ID)
+	if input.Username != "" {
+		tgNick = "@" + input.Username
+		user, err := s.repo.GetUserByTgName(tgNick)
+		if err == nil {
+			if user.PasswordHash != "" {
+				return models.User{}, ErrTelegramLinkRequired
+			}
+			if err := s.repo.LinkTelegramUser(user.ID, input.ID); err != nil {
+				return models.User{}, err
+			}
+			return user, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, err
+		}
+	}
+
+	username := strings.TrimSpace(input.FirstName + " " + input.LastName)
+	if username == "" {
+		username = tgNick
+	}
+	newUser := models.RegisterUser{Username: username, TgNick: tgNick, Invitation: input.Invitation, Status: s.registrationStatus()}
+
+	// Новый пользователь регистрируется по приглашению, как и при регистрации с паролем
+	if input.Invitation != "" {
+		id, err := s.createInvitedUser(newUser, sql.NullInt64{Int64: input.ID, Valid: true})
+		if err != nil {
+			return models.User{}, err
+		}
+		return s.repo.GetUserByID(id)
+	}
+	if !s.cfg.Registration.OpenRegistration {
+		return models.User{}, ErrInvitationRequired
+	}
+
+	if input.Group == "" {
+		return models.User{}, fmt.Errorf("group is required to register via telegram")
+	}
+	group, err := s.repo.GetGroupByCode(input.Group)
+	if err != nil {
+		return models.User{}, fmt.Errorf("group not found")
+	}
+
+	id, err := s.repo.CreateTelegramUser(newUser, group.ID, input.ID)
+	if err != nil {
+		return models.User{}, err
+	}
+	return s.repo.GetUserByID(id)
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"fmt"
+	"sso/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxScheduledQueues ограничивает число очередей, создаваемых одним запросом
+const maxScheduledQueues = 100
+
+// CreateQueueTemplate создает шаблон очереди
+func (s *AuthService) CreateQueueTemplate(template models.QueueTemplate) (int, error) {
+	if template.Duration <= 0 {
+		return 0, fmt.Errorf("template duration must be positive")
+	}
+
+	// Проверяем настройки шаблона так же, как настройки очереди
+	queue := templateQueue(template, time.Time{})
+	if err := validateQueue(&queue); err != nil {
+		return 0, err
+	}
+	template.Mode = queue.Mode
+	template.SlotDuration = queue.SlotDuration
+	template.Desks = queue.Desks
+
+	return s.repo.CreateQueueTemplate(template)
+}
+
+// GetQueueTemplateByID получает шаблон очереди по ID
+func (s *AuthService) GetQueueTemplateByID(id int) (models.QueueTemplate, error) {
+	return s.repo.GetQueueTemplateByID(id)
+}
+
+// GetAllQueueTemplates получает все шаблоны очередей
+func (s *AuthService) GetAllQueueTemplates() ([]models.QueueTemplate, error) {
+	return s.repo.GetAllQueueTemplates()
+}
+
+// DeleteQueueTemplate удаляет шаблон очереди
+func (s *AuthService) DeleteQueueTemplate(id int) error {
+	return s.repo.DeleteQueueTemplate(id)
+}
+
+// CloneQueue создает копии настроек очереди (без участников) на новое время или серию дат
+func (s *AuthService) CloneQueue(queueID int, schedule models.ScheduleRequest) ([]int, error) {
+	source, err := s.repo.GetQueueByID(queueID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Title == "" {
+		schedule.Title = source.Title
+	}
+
+	return s.scheduleQueues(source, source.TimeEnd.Sub(source.TimeStart), schedule)
+}
+
+// CreateQueuesFromTemplate создает очереди по шаблону на новое время или серию дат
+func (s *AuthService) CreateQueuesFromTemplate(templateID int, schedule models.ScheduleRequest) ([]int, error) {
+	template, err := s.repo.GetQueueTemplateByID(templateID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Title == "" {
+		schedule.Title = template.Title
+	}
+
+	duration := time.Duration(template.Duration) * time.Minute
+	return s.scheduleQueues(templateQueue(template, time.Time{}), duration, schedule)
+}
+
+// scheduleQueues создает очереди с настройками base по расписанию schedule
+func (s *AuthService) scheduleQueues(base models.Queue, duration time.Duration, schedule models.ScheduleRequest) ([]int, error) {
+	if !schedule.TimeEnd.IsZero() {
+		duration = schedule.TimeEnd.Sub(schedule.TimeStart)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("time_end must be after time_start")
+	}
+
+	starts, err := scheduleStarts(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	queues := make([]models.Queue, 0, len(starts))
+	for i, start := range starts {
+		queue := base
+		queue.ID = 0
+		queue.Title = renderQueueTitle(schedule.Title, start, i+1)
+		queue.TimeStart = start
+		queue.TimeEnd = start.Add(duration)
+		if err := validateQueue(&queue); err != nil {
+			return nil, err
+		}
+		queues = append(queues, queue)
+	}
+
+	return s.repo.CreateQueues(queues)
+}
+
+// scheduleStarts возвращает времена начала очередей серии
+func scheduleStarts(schedule models.ScheduleRequest) ([]time.Time, error) {
+	if schedule.RepeatDays < 0 {
+		return nil, fmt.Errorf("repeat_days must not be negative")
+	}
+	if schedule.RepeatDays == 0 {
+		return []time.Time{schedule.TimeStart}, nil
+	}
+	if schedule.Until.Before(schedule.TimeStart) {
+		return nil, fmt.Errorf("until must not be before time_start")
+	}
+
+	var starts []time.Time
+	for start := schedule.TimeStart; !start.After(schedule.Until); start = start.AddDate(0, 0, schedule.RepeatDays) {
+		if len(starts) == maxScheduledQueues {
+			return nil, fmt.Errorf("schedule produces more than %d queues", maxScheduledQueues)
+		}
+		starts = append(starts, start)
+	}
+
+	return starts, nil
+}
+
+// renderQueueTitle подставляет в шаблон названия дату ({date}) и порядковый номер ({n}) очереди
+func renderQueueTitle(pattern string, start time.Time, n int) string {
+	return strings.NewReplacer(
+		"{date}", start.Format("02.01.2006"),
+		"{n}", strconv.Itoa(n),
+	).Replace(pattern)
+}
+
+// templateQueue строит очередь с настройками шаблона, начинающуюся в start
+func templateQueue(template models.QueueTemplate, start time.Time) models.Queue {
+	return models.Queue{
+		Title:         template.Title,
+		TimeStart:     start,
+		TimeEnd:       start.Add(time.Duration(template.Duration) * time.Minute),
+		Mode:          template.Mode,
+		SlotDuration:  template.SlotDuration,
+		Capacity:      template.Capacity,
+		Desks:         template.Desks,
+		AllowedGroups: template.AllowedGroups,
+		AllowedUnits:  template.AllowedUnits,
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sso/models"
+	"strings"
+)
+
+// ErrTenantAdminRequired возвращается, если арендаторами управляет не администратор основного арендатора
+var ErrTenantAdminRequired = errors.New("default tenant admin access required")
+
+// ForTenant возвращает сервис, все операции которого ограничены данными арендатора
+func (s *AuthService) ForTenant(tenantID int) Authorization {
+	return s.forTenant(tenantID)
+}
+
+// forTenant возвращает копию сервиса с репозиторием, ограниченным данными арендатора
+func (s *AuthService) forTenant(tenantID int) *AuthService {
+	return &AuthService{
+		repo:      s.repo.ForTenant(tenantID),
+		cfg:       s.cfg,
+		messenger: s.messenger,
+		tenantID:  tenantID,
+	}
+}
+
+// GetTenantByCode возвращает арендатора по коду из заголовка X-Tenant
+func (s *AuthService) GetTenantByCode(code string) (models.Tenant, error) {
+	tenant, err := s.repo.GetTenantByCode(strings.TrimSpace(code))
+	if err != nil {
+		return models.Tenant{}, fmt.Errorf("tenant not found")
+	}
+	return tenant, nil
+}
+
+// GetTenants возвращает всех арендаторов; доступно только администраторам основного арендатора
+func (s *AuthService) GetTenants() ([]models.Tenant, error) {
+	if err := s.checkDefaultTenant(); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTenants()
+}
+
+// CreateTenant создает арендатора вместе с его первым администратором, который входит по нику и паролю;
+// доступно только администраторам основного арендатора
+func (s *AuthService) CreateTenant(input models.CreateTenantRequest) (int, error) {
+	if err := s.checkDefaultTenant(); err != nil {
+		return 0, err
+	}
+
+	input.Code = strings.TrimSpace(input.Code)
+	if input.Code == "" {
+		return 0, fmt.Errorf("tenant code is required")
+	}
+	if input.Admin.Username == "" || input.Admin.TgNick == "" {
+		return 0, fmt.Errorf("tenant admin username and tg_nick are required")
+	}
+	hash, err := s.newPasswordHash(input.Admin.Password)
+	if err != nil {
+		return 0, err
+	}
+	input.Admin.Password = hash
+
+	return s.repo.CreateTenant(models.Tenant{Code: input.Code, Title: input.Title}, input.Admin)
+}
+
+// checkDefaultTenant проверяет, что сервис ограничен основным арендатором
+func (s *AuthService) checkDefaultTenant() error {
+	tenant, err := s.repo.GetTenantByCode(models.DefaultTenantCode)
+	if err != nil {
+		return err
+	}
+	if tenant.ID != s.tenantID {
+		return ErrTenantAdminRequired
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sso/models"
+	"sso/pkg/totp"
+	"strings"
+	"time"
+)
+
+// Параметры двухфакторной аутентификации
+const (
+	recoveryCodeCount   = 10    // Число резервных кодов
+	recoveryCodeBytes   = 5     // Длина случайной части резервного кода
+	totpSkew            = 1     // Допустимое расхождение часов в периодах TOTP
+	defaultTOTPIssuer   = "SSO" // Название сервиса, если оно не задано в конфигурации
+	errTwoFactorEnabled = "two-factor authentication is already enabled"
+)
+
+// ErrInvalidTwoFactorCode возвращается при неверном, просроченном или уже использованном коде
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+// SetupTwoFactor выдает новый секрет TOTP; подключение вступает в силу после подтверждения кодом
+func (s *AuthService) SetupTwoFactor(userID int) (models.TwoFactorSetup, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return models.TwoFactorSetup{}, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return models.TwoFactorSetup{}, err
+	}
+	if err := s.repo.SetTOTPSecret(userID, secret); err != nil {
+		return models.TwoFactorSetup{}, err
+	}
+
+	issuer := s.cfg.TwoFactor.Issuer
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+	return models.TwoFactorSetup{Secret: secret, URI: totp.URI(issuer, user.TgNick, secret)}, nil
+}
+
+// ConfirmTwoFactor включает двухфакторную аутентификацию по первому коду из приложения и возвращает резервные коды
+func (s *AuthService) ConfirmTwoFactor(userID int, code string) ([]string, error) {
+	state, err := s.repo.GetTwoFactorState(userID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled {
+		return nil, errors.New(errTwoFactorEnabled)
+	}
+	if !state.Secret.Valid {
+		return nil, fmt.Errorf("two-factor authentication is not set up")
+	}
+
+	step, ok := totp.Validate(state.Secret.String, normalizeCode(code), time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableTwoFactor(userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor отключает двухфакторную аутентификацию после проверки кода
+func (s *AuthService) DisableTwoFactor(userID int, code string) error {
+	if err := s.verifySecondFactor(userID, code); err != nil {
+		return err
+	}
+	return s.repo.DisableTwoFactor(userID)
+}
+
+// RegenerateRecoveryCodes выдает новые резервные коды взамен прежних после проверки кода
+func (s *AuthService) RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	if err := s.verifySecondFactor(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CompleteTwoFactorSignIn завершает вход по токену первого шага и коду двухфакторной аутентификации
+func (s *AuthService) CompleteTwoFactorSignIn(input models.TwoFactorSignInRequest, client models.ClientInfo) (string, error) {
+	const op = "CompleteTwoFactorSignIn"
+
+	userID, err := s.parsePreAuthToken(input.PreAuthToken)
+	if err != nil {
+		return "", err
+	}
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	// Неверные коды учитываются вместе с неверными паролями и ведут к той же блокировке
+	lockedUntil, err := s.repo.GetSignInLockedUntil(signInKey(user.TgNick))
+	if err != nil {
+		return "", err
+	}
+	if lockedUntil.Valid && lockedUntil.Time.After(time.Now()) {
+		return "", accountLockedError(lockedUntil.Time)
+	}
+
+	if err := s.verifySecondFactor(user.ID, input.Code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if lockErr := s.recordSignInFailure(user.TgNick); lockErr != nil {
+				return "", lockErr
+			}
+		}
+		return "", err
+	}
+
+	if err := s.repo.ResetSignInFailures(user.ID); err != nil {
+		log.Printf("%s: %v", op, err)
+	}
+	return s.newUserToken(user.ID, user.IsAdmin, client)
+}
+
+// completeSignIn выдает JWT токен пользователю, прошедшему первый шаг входа, или требует второй шаг
+func (s *AuthService) completeSignIn(user models.User, client models.ClientInfo) (models.SignInResult, error) {
+	if user.Status == models.UserStatusPending {
+		return models.SignInResult{}, ErrAccountPendingApproval
+	}
+
+	state, err := s.repo.GetTwoFactorState(user.ID)
+	if err != nil {
+		return models.SignInResult{}, err
+	}
+	if state.Enabled {
+		preAuthToken, err := s.newPreAuthToken(user.ID)
+		if err != nil {
+			return models.SignInResult{}, err
+		}
+		return models.SignInResult{TwoFactorRequired: true, PreAuthToken: preAuthToken}, nil
+	}
+
+	// Если 2FA обязательна для администраторов, до ее подключения выдается токен без прав администратора
+	isAdmin := user.IsAdmin
+	setupRequired := false
+	if isAdmin && s.cfg.TwoFactor.RequiredForAdmins {
+		isAdmin, setupRequired = false, true
+	}
+
+	token, err := s.newUserToken(user.ID, isAdmin, client)
+	if err != nil {
+		return models.SignInResult{}, err
+	}
+	return models.SignInResult{Token: token, TwoFactorSetupRequired: setupRequired}, nil
+}
+
+// verifySecondFactor проверяет код TOTP или погашает резервный код
+func (s *AuthService) verifySecondFactor(userID int, code string) error {
+	state, err := s.repo.GetTwoFactorState(userID)
+	if err != nil {
+		return err
+	}
+	if !state.Enabled || !state.Secret.Valid {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	code = normalizeCode(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(state.Secret.String, code, time.Now(), totpSkew)
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		// Код, уже использованный для входа, повторно не принимается
+		used, err := s.repo.UseTOTPStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(userID, hashToken(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// newRecoveryCodes генерирует резервные коды вида xxxxx-xxxxx и их хеши для хранения
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := randomToken(recoveryCodeBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, raw[:len(raw)/2]+"-"+raw[len(raw)/2:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeCode приводит введенный код к виду, в котором он хранится: без пробелов и дефисов, в нижнем регистре
+func normalizeCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
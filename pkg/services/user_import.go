@@ -0,0 +1,163 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"sso/models"
+	"sso/pkg/importer"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Ограничения полей импорта пользователей
+const (
+	importUsernameMaxLen = 255                 // Максимальная длина имени пользователя
+	importGroupMaxLen    = 32                  // Максимальная длина кода группы
+	defaultActivationTTL = 14 * 24 * time.Hour // Время жизни токена активации, если оно не задано в конфигурации
+)
+
+// tgNickPattern описывает допустимый Telegram ник
+var tgNickPattern = regexp.MustCompile(`^@[A-Za-z0-9_]{1,32}$`)
+
+// ImportUsers импортирует студентов из CSV: проверяет все строки и, если ошибок нет, в одной транзакции
+// создает недостающие группы и пользователей с одноразовыми токенами активации. При ошибках в строках
+// или пробном запуске база не изменяется, а результат содержит итог по каждой строке
+func (s *AuthService) ImportUsers(adminID int, data io.Reader, dryRun bool) (models.ImportUsersResult, error) {
+	rows, err := importer.ReadUsersCSV(data)
+	if err != nil {
+		return models.ImportUsersResult{}, err
+	}
+	if len(rows) == 0 {
+		return models.ImportUsersResult{}, fmt.Errorf("file contains no users")
+	}
+	result := models.ImportUsersResult{DryRun: dryRun, Valid: true, Rows: rows, GroupsCreated: []string{}}
+
+	// Проверяем строки и повторы ников внутри файла
+	seen := make(map[string]int)
+	var tgNicks []string
+	for i := range rows {
+		row := &rows[i]
+		row.Status = models.ImportStatusNew
+		if err := validateImportRow(row); err != nil {
+			row.Status, row.Error = models.ImportStatusError, err.Error()
+			result.Valid = false
+			continue
+		}
+		key := strings.ToLower(row.TgNick)
+		if line, ok := seen[key]; ok {
+			row.Status, row.Error = models.ImportStatusError, fmt.Sprintf("duplicate tg_nick, first seen on line %d", line)
+			result.Valid = false
+			continue
+		}
+		seen[key] = row.Line
+		tgNicks = append(tgNicks, row.TgNick)
+	}
+
+	// Уже зарегистрированные пользователи не изменяются; ники сравниваются без учета регистра
+	existing, err := s.repo.GetExistingTgNicks(tgNicks)
+	if err != nil {
+		return models.ImportUsersResult{}, err
+	}
+	existingNicks := make(map[string]bool, len(existing))
+	for _, nick := range existing {
+		existingNicks[strings.ToLower(nick)] = true
+	}
+	for i := range rows {
+		if rows[i].Status == models.ImportStatusNew && existingNicks[strings.ToLower(rows[i].TgNick)] {
+			rows[i].Status = models.ImportStatusExists
+		}
+	}
+
+	if !result.Valid {
+		countImportRows(&result)
+		return result, nil
+	}
+
+	if dryRun {
+		groups, err := s.repo.GetAllGroups()
+		if err != nil {
+			return models.ImportUsersResult{}, err
+		}
+		known := make(map[string]bool, len(groups))
+		for _, group := range groups {
+			known[group.Code] = true
+		}
+		for _, row := range rows {
+			if row.Status == models.ImportStatusNew && !known[row.Group] {
+				known[row.Group] = true
+				result.GroupsCreated = append(result.GroupsCreated, row.Group)
+			}
+		}
+		sort.Strings(result.GroupsCreated)
+		countImportRows(&result)
+		return result, nil
+	}
+
+	// Каждому новому пользователю выдается токен для установки пароля
+	ttl := s.cfg.Password.ActivationTTL
+	if ttl <= 0 {
+		ttl = defaultActivationTTL
+	}
+	for i := range rows {
+		if rows[i].Status != models.ImportStatusNew {
+			continue
+		}
+		token, err := randomToken(passwordResetTokenBytes)
+		if err != nil {
+			return models.ImportUsersResult{}, err
+		}
+		rows[i].TokenHash = hashToken(token)
+		rows[i].ActivationToken = token
+	}
+
+	groupsCreated, err := s.repo.ImportUsers(rows, adminID, time.Now().Add(ttl))
+	if err != nil {
+		return models.ImportUsersResult{}, err
+	}
+	if groupsCreated != nil {
+		result.GroupsCreated = groupsCreated
+	}
+	for i := range rows {
+		if rows[i].Status != models.ImportStatusCreated {
+			rows[i].ActivationToken = ""
+		}
+	}
+	countImportRows(&result)
+	return result, nil
+}
+
+// validateImportRow проверяет поля строки импорта и приводит ник к виду @username
+func validateImportRow(row *models.ImportUserRow) error {
+	if row.Username == "" || row.TgNick == "" || row.Group == "" {
+		return fmt.Errorf("username, tg_nick and group are required")
+	}
+	if utf8.RuneCountInString(row.Username) > importUsernameMaxLen {
+		return fmt.Errorf("username is too long")
+	}
+	if utf8.RuneCountInString(row.Group) > importGroupMaxLen {
+		return fmt.Errorf("group code is too long")
+	}
+	if !strings.HasPrefix(row.TgNick, "@") {
+		row.TgNick = "@" + row.TgNick
+	}
+	if !tgNickPattern.MatchString(row.TgNick) {
+		return fmt.Errorf("invalid tg_nick")
+	}
+	return nil
+}
+
+// countImportRows подсчитывает создаваемых и существующих пользователей
+func countImportRows(result *models.ImportUsersResult) {
+	result.Created, result.Existing = 0, 0
+	for _, row := range result.Rows {
+		switch row.Status {
+		case models.ImportStatusNew, models.ImportStatusCreated:
+			result.Created++
+		case models.ImportStatusExists:
+			result.Existing++
+		}
+	}
+}
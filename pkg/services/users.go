@@ -2,11 +2,12 @@
 package services
 
 import (
+	"database/sql"
 	"log"
 	"sso/models"
 )
 
-// CreateUser создает нового пользователя с хешированием пароля
+// CreateUser создает нового пользователя с хешированием пароля в группе из приглашения
 func (s *AuthService) CreateUser(user models.RegisterUser) (int, error) {
 	const op = "CreateUser"
 
@@ -17,6 +18,15 @@ func (s *AuthService) CreateUser(user models.RegisterUser) (int, error) {
 		return 0, err
 	}
 	user.Password = hashedPassword
+	user.Status = s.registrationStatus()
+
+	// Группа пользователя определяется приглашением; код группы принимается только при открытой регистрации
+	if user.Invitation != "" {
+		return s.createInvitedUser(user, sql.NullInt64{})
+	}
+	if !s.cfg.Registration.OpenRegistration {
+		return 0, ErrInvitationRequired
+	}
 
 	// Получаем группу по коду
 	group, err := s.repo.GetGroupByCode(user.Group)
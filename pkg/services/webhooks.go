@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"sso/models"
+	"sso/pkg/webhook"
+)
+
+// Параметры webhook-подписок
+const (
+	webhookSecretBytes   = 32  // Длина генерируемого секрета подписи
+	webhookDeliveryLimit = 100 // Число записей журнала доставки в ответе
+)
+
+// CreateWebhook создает webhook-подписку и возвращает ее вместе с секретом подписи
+func (s *AuthService) CreateWebhook(input models.CreateWebhookRequest) (models.Webhook, error) {
+	err := webhook.ValidateURL(input.URL, s.cfg.Webhooks.AllowPrivateNetworks)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if len(input.EventTypes) == 0 {
+		return models.Webhook{}, fmt.Errorf("at least one event type is required")
+	}
+	for _, eventType := range input.EventTypes {
+		if !isWebhookEvent(eventType) {
+			return models.Webhook{}, fmt.Errorf("unknown webhook event type %q", eventType)
+		}
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		if secret, err = randomToken(webhookSecretBytes); err != nil {
+			return models.Webhook{}, err
+		}
+	}
+
+	webhook := models.Webhook{URL: input.URL, Secret: secret, EventTypes: input.EventTypes, IsActive: true}
+	if webhook.ID, err = s.repo.CreateWebhook(webhook); err != nil {
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// GetAllWebhooks возвращает все webhook-подписки
+func (s *AuthService) GetAllWebhooks() ([]models.Webhook, error) {
+	return s.repo.GetAllWebhooks()
+}
+
+// DeleteWebhook удаляет webhook-подписку
+func (s *AuthService) DeleteWebhook(id int) error {
+	return s.repo.DeleteWebhook(id)
+}
+
+// GetWebhookDeliveries возвращает журнал доставки webhook-подписки
+func (s *AuthService) GetWebhookDeliveries(webhookID int) ([]models.WebhookDelivery, error) {
+	if _, err := s.repo.GetWebhookByID(webhookID); err != nil {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	return s.repo.GetWebhookDeliveries(webhookID, webhookDeliveryLimit)
+}
+
+// isWebhookEvent проверяет, можно ли подписаться на событие
+func isWebhookEvent(eventType string) bool {
+	for _, webhookEvent := range models.WebhookEventTypes {
+		if webhookEvent == eventType {
+			return true
+		}
+	}
+	return false
+}
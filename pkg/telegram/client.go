@@ -0,0 +1,144 @@
+// Package telegram содержит клиент Telegram Bot API
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL задает адрес Telegram Bot API по умолчанию
+const DefaultAPIURL = "https://api.telegram.org"
+
+// requestTimeout задает время ожидания ответа на обычные запросы к Bot API
+const requestTimeout = 10 * time.Second
+
+// Client выполняет запросы к Telegram Bot API
+type Client struct {
+	baseURL string       // Адрес Bot API (можно указать локальный сервер для тестов)
+	token   string       // Токен бота
+	http    *http.Client // HTTP клиент
+}
+
+// User представляет пользователя Telegram
+type User struct {
+	ID        int64  `json:"id"`         // Идентификатор пользователя Telegram
+	Username  string `json:"username"`   // Имя пользователя без @
+	FirstName string `json:"first_name"` // Имя
+}
+
+// ChatTypePrivate - тип личного чата пользователя с ботом
+const ChatTypePrivate = "private"
+
+// Chat представляет чат Telegram
+type Chat struct {
+	ID   int64  `json:"id"`   // Идентификатор чата
+	Type string `json:"type"` // Тип чата: private, group, supergroup или channel
+}
+
+// Message представляет входящее сообщение
+type Message struct {
+	MessageID int    `json:"message_id"` // Идентификатор сообщения
+	From      *User  `json:"from"`       // Отправитель
+	Chat      Chat   `json:"chat"`       // Чат, в который пришло сообщение
+	Text      string `json:"text"`       // Текст сообщения
+}
+
+// Update представляет входящее обновление бота
+type Update struct {
+	UpdateID int      `json:"update_id"` // Идентификатор обновления
+	Message  *Message `json:"message"`   // Новое сообщение (если есть)
+}
+
+// apiResponse представляет общий формат ответа Bot API
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// NewClient создает клиент Bot API; пустой baseURL заменяется адресом Telegram
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{},
+	}
+}
+
+// SendMessage отправляет текстовое сообщение в чат
+func (c *Client) SendMessage(chatID int64, text string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	params := map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+	}
+	return c.call(ctx, "sendMessage", params, nil)
+}
+
+// GetUpdates получает новые обновления методом long polling
+func (c *Client) GetUpdates(ctx context.Context, offset int, timeout time.Duration) ([]Update, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout+requestTimeout)
+	defer cancel()
+
+	params := map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}
+	var updates []Update
+	if err := c.call(ctx, "getUpdates", params, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// call вызывает метод Bot API и разбирает результат в result
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// Ошибка содержит URL с токеном бота, поэтому возвращаем только причину
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram %s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("telegram %s: invalid response: %w", method, err)
+	}
+	if !apiResp.OK {
+		return fmt.Errorf("telegram %s: %s", method, apiResp.Description)
+	}
+
+	if result != nil {
+		return json.Unmarshal(apiResp.Result, result)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ошибки проверки данных Telegram Login Widget
+var (
+	ErrLoginSignature  = errors.New("invalid telegram login signature")
+	ErrLoginExpired    = errors.New("telegram login data is expired")
+	ErrLoginFromFuture = errors.New("telegram login auth_date is in the future")
+)
+
+// Ограничения auth_date в данных Telegram Login Widget
+const (
+	DefaultLoginMaxAge = 24 * time.Hour // Давность данных, если она не задана в конфигурации
+	loginClockSkew     = time.Minute    // Допустимое опережение auth_date из-за расхождения часов
+)
+
+// VerifyLogin проверяет подпись данных Telegram Login Widget: HMAC-SHA256 строки проверки
+// с ключом SHA256(токен бота). fields содержит все полученные поля, кроме hash;
+// maxAge ограничивает давность auth_date (при нулевом значении - DefaultLoginMaxAge), а auth_date
+// не может опережать now больше чем на минуту: иначе подписанные данные оставались бы действительными дольше maxAge
+func VerifyLogin(botToken string, fields map[string]string, hash string, authDate, now time.Time, maxAge time.Duration) error {
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(DataCheckString(fields)))
+
+	expected, err := hex.DecodeString(hash)
+	if err != nil || !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrLoginSignature
+	}
+
+	if maxAge <= 0 {
+		maxAge = DefaultLoginMaxAge
+	}
+	age := now.Sub(authDate)
+	if age > maxAge {
+		return ErrLoginExpired
+	}
+	if age < -loginClockSkew {
+		return ErrLoginFromFuture
+	}
+	return nil
+}
+
+// DataCheckString формирует строку проверки: пары key=value с непустыми значениями,
+// отсортированные по ключу и разделенные переводом строки
+func DataCheckString(fields map[string]string) string {
+	pairs := make([]string, 0, len(fields))
+	for key, value := range fields {
+		if value == "" {
+			continue
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\n")
+}
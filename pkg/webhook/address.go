@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// errPrivateAddress возвращается при попытке обратиться к адресу внутренней сети
+var errPrivateAddress = errors.New("webhook url must not point to a private, loopback or link-local address")
+
+// sharedAddressSpace - диапазон адресов операторского NAT (RFC 6598), не маршрутизируемый в интернете
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// ValidateURL проверяет адрес подписки: это абсолютный http или https URL, и, если не разрешены внутренние сети,
+// он не указывает на loopback, частные и link-local адреса. Имена хостов дополнительно проверяются при каждом
+// подключении, так как DNS может вернуть внутренний адрес уже после создания подписки
+func ValidateURL(rawURL string, allowPrivate bool) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return fmt.Errorf("webhook url must be an absolute http or https url")
+	}
+	if allowPrivate {
+		return nil
+	}
+
+	host := strings.ToLower(target.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errPrivateAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// isPrivateIP сообщает, относится ли адрес к внутренним сетям, недоступным для webhook-подписок
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// denyPrivateAddresses запрещает подключение к внутренним адресам уже после разрешения имени хоста
+func denyPrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isPrivateIP(ip) {
+		return errPrivateAddress
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Package webhook содержит доставку событий outbox на webhook-подписки
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sso/models"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Заголовки запроса webhook
+const (
+	SignatureHeader = "X-Webhook-Signature" // Подпись тела: sha256=<hex HMAC-SHA256 с секретом подписки>
+	EventHeader     = "X-Webhook-Event"     // Тип события
+	DeliveryHeader  = "X-Webhook-Delivery"  // ID события, одинаковый при повторной доставке
+)
+
+// Параметры запросов к подпискам
+const (
+	requestTimeout = 10 * time.Second // Время ожидания ответа одной подписки
+	maxErrorBody   = 512              // Размер тела ответа, сохраняемого в журнал при ошибке
+)
+
+// Store хранит подписки и журнал доставки
+type Store interface {
+	GetActiveWebhooks(eventType string) ([]models.Webhook, error)
+	HasSuccessfulWebhookDelivery(webhookID int, messageID int64) (bool, error)
+	AddWebhookDelivery(delivery models.WebhookDelivery) error
+}
+
+// Sink доставляет события outbox на активные подписки; каждой подписке событие доставляется один раз,
+// поэтому при повторе отправляются только подписки, не получившие его ранее
+type Sink struct {
+	stores func(tenantID int) Store
+	client *http.Client
+}
+
+// NewSink создает получателя outbox для webhook-подписок; stores возвращает хранилище подписок арендатора,
+// поэтому событие доставляется только подпискам арендатора, в данных которого оно произошло.
+// Если cfg не разрешает внутренние сети, подключения к loopback, частным и link-local адресам отклоняются
+func NewSink(stores func(tenantID int) Store, cfg models.WebhookConfig) *Sink {
+	dialer := &net.Dialer{Timeout: requestTimeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = denyPrivateAddresses
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	return &Sink{stores: stores, client: &http.Client{Transport: transport}}
+}
+
+// Name возвращает имя получателя outbox
+func (s *Sink) Name() string {
+	return "webhooks"
+}
+
+// Accepts сообщает, можно ли подписаться на событие этого типа
+func (s *Sink) Accepts(eventType string) bool {
+	for _, webhookEvent := range models.WebhookEventTypes {
+		if webhookEvent == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Deliver отправляет событие всем подпискам; ошибка хотя бы одной подписки приводит к повтору
+func (s *Sink) Deliver(ctx context.Context, message models.OutboxMessage) error {
+	store := s.stores(message.TenantID)
+	webhooks, err := store.GetActiveWebhooks(message.EventType)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(models.WebhookPayload{
+		ID:        message.ID,
+		Event:     message.EventType,
+		CreatedAt: message.CreatedAt,
+		Data:      message.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	var pending []models.Webhook
+	for _, webhook := range webhooks {
+		delivered, err := store.HasSuccessfulWebhookDelivery(webhook.ID, message.ID)
+		if err != nil {
+			return err
+		}
+		if !delivered {
+			pending = append(pending, webhook)
+		}
+	}
+
+	// Подписки опрашиваются параллельно, и у каждого запроса свое время ожидания,
+	// поэтому медленная подписка не расходует время доставки остальным
+	deliveries := make([]models.WebhookDelivery, len(pending))
+	var wg sync.WaitGroup
+	for i, webhook := range pending {
+		wg.Add(1)
+		go func(i int, webhook models.Webhook) {
+			defer wg.Done()
+			deliveries[i] = s.send(ctx, webhook, message, body)
+		}(i, webhook)
+	}
+	wg.Wait()
+
+	var failed int
+	for _, delivery := range deliveries {
+		if err := store.AddWebhookDelivery(delivery); err != nil {
+			return err
+		}
+		if !delivery.Success {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d webhooks failed", failed, len(webhooks))
+	}
+	return nil
+}
+
+// send выполняет запрос на подписку и возвращает запись для журнала доставки
+func (s *Sink) send(ctx context.Context, webhook models.Webhook, message models.OutboxMessage, body []byte) models.WebhookDelivery {
+	delivery := models.WebhookDelivery{
+		WebhookID:       webhook.ID,
+		OutboxMessageID: message.ID,
+		EventType:       message.EventType,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = sql.NullString{String: err.Error(), Valid: true}
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, message.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(message.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	delivery.DurationMs = int(time.Since(start).Milliseconds())
+	if err != nil {
+		delivery.Error = sql.NullString{String: err.Error(), Valid: true}
+		return delivery
+	}
+	defer resp.Body.Close()
+
+	delivery.StatusCode = sql.NullInt32{Int32: int32(resp.StatusCode), Valid: true}
+	delivery.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Success {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		delivery.Error = sql.NullString{String: fmt.Sprintf("unexpected status %d: %s", resp.StatusCode, respBody), Valid: true}
+	}
+	return delivery
+}
+
+// Sign возвращает значение заголовка подписи тела запроса
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
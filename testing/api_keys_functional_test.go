@@ -0,0 +1,163 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"sso/models"
+	"testing"
+	"time"
+)
+
+// TestAPIKeys тестирует создание, использование и отзыв API-ключей
+func TestAPIKeys(t *testing.T) {
+	helper := NewTestHelper()
+
+	helper.createTestUser(t, "apikeyuser", "password123", "@apikeyuser", "ИУ7-12Б")
+	token := helper.loginUser(t, "@apikeyuser", "password123")
+
+	// createKey создает ключ и возвращает его вместе с ID
+	createKey := func(t *testing.T, input models.CreateAPIKeyRequest) (int, string) {
+		resp, err := helper.makeRequest("POST", baseURL+"/api/profile/api-keys", input, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var result struct {
+			APIKey models.CreatedAPIKey `json:"api_key"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if result.APIKey.Key == "" || result.APIKey.Prefix == "" {
+			t.Fatalf("Response should contain the key and its prefix")
+		}
+		return result.APIKey.ID, result.APIKey.Key
+	}
+
+	// status выполняет запрос с ключом и возвращает код ответа
+	status := func(t *testing.T, method, url, key string) int {
+		resp, err := helper.makeRequest(method, url, nil, key)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	readID, readKey := createKey(t, models.CreateAPIKeyRequest{Name: "read only"})
+
+	t.Run("ReadKey_Get", func(t *testing.T) {
+		if code := status(t, "GET", baseURL+"/api/profile", readKey); code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", code)
+		}
+	})
+
+	t.Run("ReadKey_XAPIKeyHeader", func(t *testing.T) {
+		req, err := http.NewRequest("GET", baseURL+"/api/profile", nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("X-API-Key", readKey)
+
+		resp, err := helper.client.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ReadKey_WriteForbidden", func(t *testing.T) {
+		if code := status(t, "POST", baseURL+"/api/profile/calendar/reset", readKey); code != http.StatusForbidden {
+			t.Errorf("Expected status 403 for read-only key, got %d", code)
+		}
+	})
+
+	t.Run("WriteKey_CannotManageKeys", func(t *testing.T) {
+		_, writeKey := createKey(t, models.CreateAPIKeyRequest{Name: "write", Scopes: []string{models.APIKeyScopeRead, models.APIKeyScopeWrite}})
+		if code := status(t, "POST", baseURL+"/api/profile/api-keys", writeKey); code != http.StatusForbidden {
+			t.Errorf("Expected status 403 when managing keys with a key, got %d", code)
+		}
+	})
+
+	t.Run("AdminScope_RegularUser", func(t *testing.T) {
+		input := models.CreateAPIKeyRequest{Name: "admin", Scopes: []string{models.APIKeyScopeAdmin}}
+		resp, err := helper.makeRequest("POST", baseURL+"/api/profile/api-keys", input, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for admin scope, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ExpiresInPast", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		input := models.CreateAPIKeyRequest{Name: "expired", ExpiresAt: &past}
+		resp, err := helper.makeRequest("POST", baseURL+"/api/profile/api-keys", input, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for expiry in the past, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ListKeys_HidesSecret", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", baseURL+"/api/profile/api-keys", nil, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var result map[string][]map[string]interface{}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if len(result["api_keys"]) == 0 {
+			t.Fatal("Response should contain created keys")
+		}
+		for _, key := range result["api_keys"] {
+			if _, ok := key["key"]; ok {
+				t.Error("Listed keys should not contain the secret")
+			}
+		}
+	})
+
+	t.Run("RevokeKey", func(t *testing.T) {
+		if code := status(t, "DELETE", fmt.Sprintf("%s/api/profile/api-keys/%d", baseURL, readID), token); code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", code)
+		}
+		if code := status(t, "GET", baseURL+"/api/profile", readKey); code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for revoked key, got %d", code)
+		}
+	})
+
+	t.Run("RevokeKey_OtherUser", func(t *testing.T) {
+		helper.createTestUser(t, "apikeyother", "password123", "@apikeyother", "ИУ7-12Б")
+		otherToken := helper.loginUser(t, "@apikeyother", "password123")
+
+		id, _ := createKey(t, models.CreateAPIKeyRequest{Name: "victim"})
+		if code := status(t, "DELETE", fmt.Sprintf("%s/api/profile/api-keys/%d", baseURL, id), otherToken); code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for someone else's key, got %d", code)
+		}
+	})
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		if code := status(t, "GET", baseURL+"/api/profile", "sso_000000000000_00000000"); code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for unknown key, got %d", code)
+		}
+	})
+}
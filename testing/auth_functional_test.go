@@ -174,6 +174,30 @@ func TestAuthSignIn(t *testing.T) {
 		}
 	})
 
+	t.Run("InvalidSignIn_SameErrorForUnknownUser", func(t *testing.T) {
+		// Ответ не должен выдавать, существует ли пользователь с таким ником
+		signInError := func(authData models.AuthUser) string {
+			resp, err := helper.makeRequest("POST", baseURL+"/auth/sign-in", authData, "")
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var result map[string]interface{}
+			if err := helper.parseResponse(resp, &result); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			message, _ := result["error"].(string)
+			return message
+		}
+
+		wrongPassword := signInError(models.AuthUser{TgNick: "@signintest", Password: "wrongpassword"})
+		unknownUser := signInError(models.AuthUser{TgNick: "@nonexistent", Password: "password123"})
+		if wrongPassword == "" || wrongPassword != unknownUser {
+			t.Errorf("Expected identical errors, got %q and %q", wrongPassword, unknownUser)
+		}
+	})
+
 	t.Run("InvalidSignIn_MissingFields", func(t *testing.T) {
 		authData := models.AuthUser{
 			TgNick: "@signintest",
@@ -0,0 +1,260 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"sso/models"
+	"sso/pkg/repository"
+	"sso/pkg/services"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeUserRepository хранит пользователей и состояние блокировки входа в памяти
+type fakeUserRepository struct {
+	repository.Repository
+	tenantID    int
+	tenants     map[string]models.Tenant
+	users       map[string]models.User
+	failures    map[string]int
+	lockedUntil map[string]time.Time
+	twoFactor   map[int]*models.TwoFactorState
+	recovery    map[int]map[string]bool
+	sessions    map[int]*models.Session
+	groups      map[int]models.Group
+	moderators  map[[2]int]bool
+	invitations map[string]*models.Invitation
+	orgUnits    map[int]*models.OrgUnit
+	unitAdmins  map[[2]int]bool
+	queues      []models.Queue
+	queueUsers  map[[2]int]bool
+	telegramIDs map[int64]int
+}
+
+func newFakeUserRepository(users ...models.User) *fakeUserRepository {
+	repo := &fakeUserRepository{
+		tenantID:    1,
+		tenants:     map[string]models.Tenant{models.DefaultTenantCode: {ID: 1, Code: models.DefaultTenantCode}},
+		users:       make(map[string]models.User),
+		failures:    make(map[string]int),
+		lockedUntil: make(map[string]time.Time),
+		twoFactor:   make(map[int]*models.TwoFactorState),
+		recovery:    make(map[int]map[string]bool),
+		sessions:    make(map[int]*models.Session),
+		groups:      make(map[int]models.Group),
+		moderators:  make(map[[2]int]bool),
+		invitations: make(map[string]*models.Invitation),
+		orgUnits:    make(map[int]*models.OrgUnit),
+		unitAdmins:  make(map[[2]int]bool),
+		queueUsers:  make(map[[2]int]bool),
+		telegramIDs: make(map[int64]int),
+	}
+	for _, user := range users {
+		if user.TenantID == 0 {
+			user.TenantID = repo.tenantID
+		}
+		repo.users[user.TgNick] = user
+	}
+	return repo
+}
+
+func (r *fakeUserRepository) GetUserByTgName(tgNick string) (models.User, error) {
+	for nick, user := range r.users {
+		if strings.EqualFold(nick, tgNick) && user.TenantID == r.tenantID {
+			return user, nil
+		}
+	}
+	return models.User{}, sql.ErrNoRows
+}
+
+func (r *fakeUserRepository) GetUserByID(id int) (models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id && user.TenantID == r.tenantID {
+			return user, nil
+		}
+	}
+	return models.User{}, sql.ErrNoRows
+}
+
+func (r *fakeUserRepository) GetSignInLockedUntil(tgNick string) (sql.NullTime, error) {
+	until, ok := r.lockedUntil[tgNick]
+	return sql.NullTime{Time: until, Valid: ok}, nil
+}
+
+func (r *fakeUserRepository) RecordSignInFailure(tgNick string, maxFailures int, lockout time.Duration) (sql.NullTime, error) {
+	r.failures[tgNick]++
+	if r.failures[tgNick] >= maxFailures {
+		r.failures[tgNick] = 0
+		r.lockedUntil[tgNick] = time.Now().Add(lockout)
+	}
+	until, ok := r.lockedUntil[tgNick]
+	return sql.NullTime{Time: until, Valid: ok}, nil
+}
+
+func (r *fakeUserRepository) ResetSignInFailures(userID int) error {
+	for nick, user := range r.users {
+		if user.ID == userID {
+			delete(r.failures, strings.ToLower(nick))
+			delete(r.lockedUntil, strings.ToLower(nick))
+			return nil
+		}
+	}
+	return errors.New("user not found")
+}
+
+// TestAuthenticate тестирует проверку пароля при входе
+func TestAuthenticate(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	repo := newFakeUserRepository(
+		models.User{ID: 1, Username: "student", TgNick: "@student", PasswordHash: string(hash)},
+		models.User{ID: 2, Username: "tguser", TgNick: "@tguser"},
+	)
+	cfg := models.Config{SignIn: models.SignInConfig{MaxFailures: 3, LockoutDuration: time.Minute}}
+	service := services.NewAuthService(repo, cfg, nil)
+
+	t.Run("Authenticate_ValidPassword", func(t *testing.T) {
+		user, err := service.Authenticate(models.AuthUser{TgNick: "@student", Password: "password123"})
+		if err != nil {
+			t.Fatalf("Expected successful authentication, got %v", err)
+		}
+		if user.ID != 1 {
+			t.Errorf("Expected user 1, got %d", user.ID)
+		}
+	})
+
+	t.Run("Authenticate_WrongPassword", func(t *testing.T) {
+		_, err := service.Authenticate(models.AuthUser{TgNick: "@student", Password: "wrongpassword"})
+		if !errors.Is(err, services.ErrInvalidCredentials) {
+			t.Errorf("Expected invalid credentials, got %v", err)
+		}
+	})
+
+	t.Run("Authenticate_UnknownUserSameError", func(t *testing.T) {
+		_, unknownErr := service.Authenticate(models.AuthUser{TgNick: "@nobody", Password: "password123"})
+		_, wrongErr := service.Authenticate(models.AuthUser{TgNick: "@student", Password: "wrongpassword"})
+		if unknownErr == nil || wrongErr == nil || unknownErr.Error() != wrongErr.Error() {
+			t.Errorf("Expected identical errors, got %v and %v", unknownErr, wrongErr)
+		}
+	})
+
+	t.Run("Authenticate_UserWithoutPassword", func(t *testing.T) {
+		_, err := service.Authenticate(models.AuthUser{TgNick: "@tguser", Password: ""})
+		if !errors.Is(err, services.ErrInvalidCredentials) {
+			t.Errorf("Expected invalid credentials for user without password, got %v", err)
+		}
+	})
+
+	t.Run("Authenticate_UnknownUserComparesHash", func(t *testing.T) {
+		// Для неизвестного пользователя bcrypt выполняется так же, как для существующего
+		measure := func(nick string) time.Duration {
+			start := time.Now()
+			for i := 0; i < 3; i++ {
+				_, _ = service.Authenticate(models.AuthUser{TgNick: nick, Password: "wrongpassword"})
+			}
+			return time.Since(start)
+		}
+		known, unknown := measure("@student"), measure("@nobody")
+		if unknown < known/3 {
+			t.Errorf("Unknown user rejected much faster than wrong password: %v vs %v", unknown, known)
+		}
+	})
+
+	t.Run("SignIn_LockoutAfterFailures", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if _, err := service.SignIn(models.AuthUser{TgNick: "@student", Password: "wrongpassword"}, models.ClientInfo{}); !errors.Is(err, services.ErrInvalidCredentials) {
+				t.Fatalf("Attempt %d: expected invalid credentials, got %v", i+1, err)
+			}
+		}
+
+		var policyErr *services.PolicyError
+		_, err := service.SignIn(models.AuthUser{TgNick: "@student", Password: "wrongpassword"}, models.ClientInfo{})
+		if !errors.As(err, &policyErr) || policyErr.Code != services.PolicyAccountLocked {
+			t.Fatalf("Expected account lockout, got %v", err)
+		}
+
+		// Верный пароль не помогает, пока аккаунт заблокирован
+		_, err = service.SignIn(models.AuthUser{TgNick: "@student", Password: "password123"}, models.ClientInfo{})
+		if !errors.As(err, &policyErr) || policyErr.RetryAfter <= 0 {
+			t.Fatalf("Expected locked account to reject correct password, got %v", err)
+		}
+
+		if err := service.UnlockUser(1); err != nil {
+			t.Fatalf("Failed to unlock user: %v", err)
+		}
+		if _, err := service.SignIn(models.AuthUser{TgNick: "@student", Password: "password123"}, models.ClientInfo{}); err != nil {
+			t.Errorf("Expected sign-in after unlock, got %v", err)
+		}
+	})
+
+	t.Run("SignIn_UnknownNickLockedTheSameWay", func(t *testing.T) {
+		// Ник учитывается без регистра, как и в ключе ограничения частоты входа
+		var policyErr *services.PolicyError
+		for i, nick := range []string{"@Nobody", "@nobody", "@NOBODY"} {
+			_, err := service.SignIn(models.AuthUser{TgNick: nick, Password: "wrongpassword"}, models.ClientInfo{})
+			if i < 2 && !errors.Is(err, services.ErrInvalidCredentials) {
+				t.Fatalf("Attempt %d: expected invalid credentials, got %v", i+1, err)
+			}
+			if i == 2 && (!errors.As(err, &policyErr) || policyErr.Code != services.PolicyAccountLocked) {
+				t.Fatalf("Expected unknown nick to be locked like an existing account, got %v", err)
+			}
+		}
+	})
+}
+
+func (r *fakeUserRepository) GetTwoFactorState(userID int) (models.TwoFactorState, error) {
+	if state, ok := r.twoFactor[userID]; ok {
+		return *state, nil
+	}
+	return models.TwoFactorState{}, nil
+}
+
+func (r *fakeUserRepository) SetTOTPSecret(userID int, secret string) error {
+	if state, ok := r.twoFactor[userID]; ok && state.Enabled {
+		return errors.New("two-factor authentication is already enabled")
+	}
+	r.twoFactor[userID] = &models.TwoFactorState{Secret: sql.NullString{String: secret, Valid: true}}
+	return nil
+}
+
+func (r *fakeUserRepository) EnableTwoFactor(userID int, step int64, codeHashes []string) error {
+	state := r.twoFactor[userID]
+	state.Enabled, state.LastStep = true, sql.NullInt64{Int64: step, Valid: true}
+	return r.ReplaceRecoveryCodes(userID, codeHashes)
+}
+
+func (r *fakeUserRepository) DisableTwoFactor(userID int) error {
+	delete(r.twoFactor, userID)
+	delete(r.recovery, userID)
+	return nil
+}
+
+func (r *fakeUserRepository) UseTOTPStep(userID int, step int64) (bool, error) {
+	state := r.twoFactor[userID]
+	if state.LastStep.Valid && state.LastStep.Int64 >= step {
+		return false, nil
+	}
+	state.LastStep = sql.NullInt64{Int64: step, Valid: true}
+	return true, nil
+}
+
+func (r *fakeUserRepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	if !r.recovery[userID][codeHash] {
+		return false, nil
+	}
+	r.recovery[userID][codeHash] = false
+	return true, nil
+}
+
+func (r *fakeUserRepository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	r.recovery[userID] = make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		r.recovery[userID][hash] = true
+	}
+	return nil
+}
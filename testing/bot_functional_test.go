@@ -0,0 +1,163 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sso/models"
+	"sso/pkg/bot"
+	"sso/pkg/services"
+	"sso/pkg/telegram"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBotService подменяет методы сервиса, которые вызывает бот
+type fakeBotService struct {
+	services.Authorization
+	joined  map[int]bool
+	shifted int
+}
+
+func (s *fakeBotService) ForTenant(tenantID int) services.Authorization {
+	return s
+}
+
+func (s *fakeBotService) GetUserByTelegramChat(chatID int64) (models.User, error) {
+	if chatID != 100 && chatID != 200 {
+		return models.User{}, fmt.Errorf("telegram chat is not linked")
+	}
+	return models.User{ID: int(chatID), Username: "botuser", IsAdmin: chatID == 200}, nil
+}
+
+// LinkTelegramChat в тестах считает код "foreign" выданным в другом арендаторе, где чат уже привязан
+func (s *fakeBotService) LinkTelegramChat(code string, chatID int64) (models.User, error) {
+	if code == "foreign" {
+		return models.User{}, models.ErrTelegramChatLinkedElsewhere
+	}
+	return models.User{}, fmt.Errorf("link code is invalid or expired")
+}
+
+func (s *fakeBotService) GetAvailableQueues(userID int) ([]models.Queue, error) {
+	start := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+	return []models.Queue{{ID: 42, Title: "Консультация", TimeStart: start, TimeEnd: start.Add(time.Hour), Mode: models.QueueModeFIFO}}, nil
+}
+
+func (s *fakeBotService) JoinQueue(queueID, userID int) (int, error) {
+	s.joined[queueID] = true
+	return 1, nil
+}
+
+func (s *fakeBotService) GetQueuePosition(queueID, userID int) (int, error) {
+	if !s.joined[queueID] {
+		return 0, fmt.Errorf("user is not in queue")
+	}
+	return 1, nil
+}
+
+func (s *fakeBotService) ShiftQueue(queueID int, outcome string) error {
+	s.shifted++
+	return nil
+}
+
+func (s *fakeBotService) GetQueueParticipants(queueID int) ([]models.QueueParticipant, error) {
+	return nil, nil
+}
+
+// TestBotCommands тестирует команды бота на локальном поддельном Bot API
+func TestBotCommands(t *testing.T) {
+	messages := []struct {
+		chatID   int64
+		chatType string
+		text     string
+		expect   string
+	}{
+		{100, telegram.ChatTypePrivate, "/queues", "42. Консультация"},
+		{100, telegram.ChatTypePrivate, "/join 42", "ваша позиция: 1"},
+		{100, telegram.ChatTypePrivate, "/position@sso_queue_bot 42", "Ваша позиция: 1"},
+		{100, telegram.ChatTypePrivate, "/next 42", "только ведущим"},
+		{200, telegram.ChatTypePrivate, "/next 42", "Очередь сдвинута"},
+		{300, telegram.ChatTypePrivate, "/join 42", "Чат не привязан"},
+		{100, telegram.ChatTypePrivate, "/join abc", "положительным числом"},
+		{300, telegram.ChatTypePrivate, "/start expired", "недействительна"},
+		{300, telegram.ChatTypePrivate, "/start foreign", "в другой организации"},
+		// Команды и привязка в группе не выполняются, даже если чат группы привязан к ведущему
+		{200, "group", "/next 42", "только в личном чате"},
+		{-500, "supergroup", "/start abc", "только в личном чате"},
+	}
+
+	var mu sync.Mutex
+	replies := make(map[int64][]string)
+	served := false
+	done := make(chan struct{})
+
+	fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+			// Отдаем все команды в первом опросе, дальше обновлений нет
+			var updates []telegram.Update
+			if !served {
+				for i, message := range messages {
+					updates = append(updates, telegram.Update{
+						UpdateID: i + 1,
+						Message:  &telegram.Message{MessageID: i + 1, Chat: telegram.Chat{ID: message.chatID, Type: message.chatType}, Text: message.text},
+					})
+				}
+				served = true
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": updates})
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			var params struct {
+				ChatID int64  `json:"chat_id"`
+				Text   string `json:"text"`
+			}
+			json.NewDecoder(r.Body).Decode(&params)
+			replies[params.ChatID] = append(replies[params.ChatID], params.Text)
+
+			total := 0
+			for _, chatReplies := range replies {
+				total += len(chatReplies)
+			}
+			if total == len(messages) {
+				close(done)
+			}
+			w.Write([]byte(`{"ok":true,"result":{}}`))
+		}
+	}))
+	defer fake.Close()
+
+	service := &fakeBotService{joined: make(map[int]bool)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bot.NewBot(telegram.NewClient(fake.URL, "test-token"), service, time.Second, time.UTC).Run(ctx)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for bot replies")
+	}
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Ответы в каждом чате приходят в порядке команд
+	next := make(map[int64]int)
+	for _, message := range messages {
+		reply := replies[message.chatID][next[message.chatID]]
+		next[message.chatID]++
+		if !strings.Contains(reply, message.expect) {
+			t.Errorf("Command %q: expected reply containing %q, got %q", message.text, message.expect, reply)
+		}
+	}
+	if service.shifted != 1 {
+		t.Errorf("Expected exactly one shift by host, got %d", service.shifted)
+	}
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sso/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCalendarFeeds тестирует персональную ленту iCalendar и выгрузку очереди в .ics
+func TestCalendarFeeds(t *testing.T) {
+	helper := NewTestHelper()
+
+	// Создаем админа и пользователя
+	helper.createTestUser(t, "calendaradmin", "password123", "@calendaradmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@calendaradmin", "password123")
+
+	helper.createTestUser(t, "calendaruser", "password123", "@calendaruser", "ИУ7-12Б")
+	userToken := helper.loginUser(t, "@calendaruser", "password123")
+
+	queueID := helper.createTestQueue(t, adminToken, "Calendar Test Queue")
+	uid := fmt.Sprintf("UID:queue-%d@", queueID)
+
+	// feedPath выдает новую ссылку на ленту и возвращает ее путь
+	feedPath := func(t *testing.T, token string) string {
+		resp, err := helper.makeRequest("POST", baseURL+"/api/profile/calendar/reset", nil, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var result map[string]string
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		index := strings.Index(result["url"], "/calendar/")
+		if index < 0 {
+			t.Fatalf("Unexpected calendar url %q", result["url"])
+		}
+		return result["url"][index:]
+	}
+
+	// fetch загружает календарь без авторизации
+	fetch := func(t *testing.T, url string) (int, string) {
+		resp, err := helper.makeRequest("GET", url, nil, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("IssueLink_ShownOnce", func(t *testing.T) {
+		for _, expected := range []int{http.StatusOK, http.StatusConflict} {
+			resp, err := helper.makeRequest("GET", baseURL+"/api/profile/calendar", nil, adminToken)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != expected {
+				t.Errorf("Expected status %d, got %d", expected, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("UserFeed_ContainsQueue", func(t *testing.T) {
+		status, body := fetch(t, baseURL+feedPath(t, userToken))
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if !strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n") || !strings.Contains(body, uid) {
+			t.Errorf("Expected calendar with queue %d, got %s", queueID, body)
+		}
+	})
+
+	t.Run("UserFeed_UpdatedQueue", func(t *testing.T) {
+		updateData := models.Queue{
+			ID:        queueID,
+			Title:     "Calendar Test Queue Moved",
+			TimeStart: time.Now().Add(24 * time.Hour),
+			TimeEnd:   time.Now().Add(26 * time.Hour),
+		}
+		resp, err := helper.makeRequest("PUT", fmt.Sprintf("%s/api/queues/%d", baseURL, queueID), updateData, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		_, body := fetch(t, baseURL+feedPath(t, userToken))
+		if !strings.Contains(body, "SUMMARY:Calendar Test Queue Moved") || !strings.Contains(body, "SEQUENCE:1") {
+			t.Errorf("Expected updated event with increased sequence, got %s", body)
+		}
+	})
+
+	t.Run("UserFeed_ResetToken", func(t *testing.T) {
+		oldPath := feedPath(t, userToken)
+
+		resp, err := helper.makeRequest("POST", baseURL+"/api/profile/calendar/reset", nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if status, _ := fetch(t, baseURL+oldPath); status != http.StatusNotFound {
+			t.Errorf("Expected status 404 for revoked feed, got %d", status)
+		}
+		if status, _ := fetch(t, baseURL+feedPath(t, userToken)); status != http.StatusOK {
+			t.Errorf("Expected status 200 for new feed, got %d", status)
+		}
+	})
+
+	t.Run("UserFeed_DeletedQueueCancelled", func(t *testing.T) {
+		deletedID := helper.createTestQueue(t, adminToken, "Calendar Deleted Queue")
+		path := feedPath(t, userToken)
+
+		resp, err := helper.makeRequest("DELETE", fmt.Sprintf("%s/api/queues/%d", baseURL, deletedID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		_, body := fetch(t, baseURL+path)
+		deletedUID := fmt.Sprintf("UID:queue-%d@", deletedID)
+		index := strings.Index(body, deletedUID)
+		if index < 0 || !strings.Contains(body[index:index+strings.Index(body[index:], "END:VEVENT")], "STATUS:CANCELLED") {
+			t.Errorf("Expected cancelled event for deleted queue %d, got %s", deletedID, body)
+		}
+	})
+
+	t.Run("QueueCalendar_Download", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d/calendar", baseURL, queueID), nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/calendar") {
+			t.Errorf("Expected calendar content type, got %s", resp.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(body), uid) {
+			t.Errorf("Expected event for queue %d, got %s", queueID, body)
+		}
+	})
+
+	t.Run("QueueCalendar_NotFound", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", baseURL+"/api/queues/999999/calendar", nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+}
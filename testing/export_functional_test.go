@@ -0,0 +1,99 @@
+package test
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sso/models"
+	"strings"
+	"testing"
+)
+
+// TestParticipantsExport тестирует выгрузку участников очереди в CSV и XLSX
+func TestParticipantsExport(t *testing.T) {
+	helper := NewTestHelper()
+
+	// Создаем админа и пользователя
+	helper.createTestUser(t, "exportadmin", "password123", "@exportadmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@exportadmin", "password123")
+
+	helper.createTestUser(t, "exportuser", "password123", "@exportuser", "ИУ7-12Б")
+	userToken := helper.loginUser(t, "@exportuser", "password123")
+
+	queueID := helper.createTestQueue(t, adminToken, "Export Test Queue")
+	resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/join", baseURL, queueID), models.JoinQueueRequest{QueueID: queueID}, userToken)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+
+	t.Run("ExportCSV_FormatParam", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d/participants?format=csv", baseURL, queueID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/csv") {
+			t.Errorf("Expected CSV content type, got %s", resp.Header.Get("Content-Type"))
+		}
+
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to parse CSV: %v", err)
+		}
+		// Ник начинается с @, поэтому экранируется от интерпретации как формулы
+		if len(records) != 2 || records[1][1] != "'@exportuser" {
+			t.Errorf("Expected header and one participant row, got %v", records)
+		}
+	})
+
+	t.Run("ExportXLSX_AcceptHeader", func(t *testing.T) {
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/queues/%d/participants", baseURL, queueID), nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		req.Header.Set("Accept", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+
+		resp, err := helper.client.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if !strings.Contains(resp.Header.Get("Content-Disposition"), ".xlsx") {
+			t.Errorf("Expected xlsx attachment, got %s", resp.Header.Get("Content-Disposition"))
+		}
+	})
+
+	t.Run("ExportCSV_RegularUser", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d/participants?format=csv", baseURL, queueID), nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for non-admin user, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Export_UnsupportedFormat", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d/participants?format=pdf", baseURL, queueID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"sso/models"
+	"sso/pkg/export"
+	"testing"
+	"time"
+)
+
+// TestExportCSVEscapesFormulas тестирует экранирование ячеек CSV, которые табличный редактор принял бы за формулу
+func TestExportCSVEscapesFormulas(t *testing.T) {
+	rows := []models.ParticipantExportRow{
+		{Username: "=HYPERLINK(\"http://evil\")", TgNick: "@SUM(1+1)", GroupCode: "ИУ7-12Б", Position: 1, Status: "waiting", JoinedAt: time.Now()},
+		{Username: "+7", TgNick: "-1", GroupCode: "\tcmd", Position: 2, Status: "waiting", JoinedAt: time.Now()},
+		{Username: "\rcmd", TgNick: "student", GroupCode: "", Position: 3, Status: "waiting", JoinedAt: time.Now()},
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteCSV(&buf, rows); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	expected := [][]string{
+		{"'=HYPERLINK(\"http://evil\")", "'@SUM(1+1)", "ИУ7-12Б"},
+		{"'+7", "'-1", "'\tcmd"},
+		{"'\rcmd", "student", ""},
+	}
+	for i, want := range expected {
+		for j, cell := range want {
+			if got := records[i+1][j]; got != cell {
+				t.Errorf("Row %d column %d: expected %q, got %q", i+1, j, cell, got)
+			}
+		}
+	}
+}
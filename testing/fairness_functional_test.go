@@ -0,0 +1,106 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"sso/models"
+	"testing"
+)
+
+// TestJoinFairness тестирует правила честного присоединения к очередям
+func TestJoinFairness(t *testing.T) {
+	helper := NewTestHelper()
+
+	// Создаем админа и пользователя
+	helper.createTestUser(t, "fairadmin", "password123", "@fairadmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@fairadmin", "password123")
+
+	helper.createTestUser(t, "fairuser", "password123", "@fairuser", "ИУ7-12Б")
+	userToken := helper.loginUser(t, "@fairuser", "password123")
+
+	queueID := helper.createTestQueue(t, adminToken, "Fairness Test Queue")
+
+	helper.createTestUser(t, "fairdaily", "password123", "@fairdaily", "ИУ7-12Б")
+	dailyToken := helper.loginUser(t, "@fairdaily", "password123")
+
+	joinAs := func(t *testing.T, token string, queueID int) (*http.Response, map[string]interface{}) {
+		joinData := models.JoinQueueRequest{QueueID: queueID}
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/join", baseURL, queueID), joinData, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var result map[string]interface{}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return resp, result
+	}
+	join := func(t *testing.T, queueID int) (*http.Response, map[string]interface{}) {
+		return joinAs(t, userToken, queueID)
+	}
+
+	t.Run("RejoinCooldown", func(t *testing.T) {
+		if resp, _ := join(t, queueID); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 on first join, got %d", resp.StatusCode)
+		}
+
+		resp, err := helper.makeRequest("DELETE", fmt.Sprintf("%s/api/queues/%d/leave", baseURL, queueID), nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		resp, result := join(t, queueID)
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("Expected status 429 on immediate rejoin, got %d", resp.StatusCode)
+		}
+		if code, _ := result["code"].(string); code != "rejoin_cooldown" {
+			t.Errorf("Expected code rejoin_cooldown, got %v", result["code"])
+		}
+		if resp.Header.Get("Retry-After") == "" {
+			t.Error("Response should contain Retry-After header")
+		}
+	})
+
+	t.Run("MaxActiveQueues", func(t *testing.T) {
+		// По умолчанию пользователь может состоять не более чем в трех очередях
+		var lastResp *http.Response
+		var lastResult map[string]interface{}
+		for i := 0; i < 4; i++ {
+			id := helper.createTestQueue(t, adminToken, fmt.Sprintf("Fairness Active Queue %d", i))
+			lastResp, lastResult = join(t, id)
+		}
+
+		if lastResp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("Expected status 429 when exceeding active queues, got %d", lastResp.StatusCode)
+		}
+		if code, _ := lastResult["code"].(string); code != "max_active_queues" {
+			t.Errorf("Expected code max_active_queues, got %v", lastResult["code"])
+		}
+	})
+
+	t.Run("DailyJoinLimit", func(t *testing.T) {
+		// По умолчанию за день можно присоединиться к очередям не более 10 раз; выход из очереди не возвращает попытку
+		for i := 0; i < 10; i++ {
+			id := helper.createTestQueue(t, adminToken, fmt.Sprintf("Fairness Daily Queue %d", i))
+			if resp, _ := joinAs(t, dailyToken, id); resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status 200 on join %d, got %d", i+1, resp.StatusCode)
+			}
+			resp, err := helper.makeRequest("DELETE", fmt.Sprintf("%s/api/queues/%d/leave", baseURL, id), nil, dailyToken)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+		}
+
+		id := helper.createTestQueue(t, adminToken, "Fairness Daily Queue Extra")
+		resp, result := joinAs(t, dailyToken, id)
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("Expected status 429 when exceeding daily join limit, got %d", resp.StatusCode)
+		}
+		if code, _ := result["code"].(string); code != "daily_join_limit" {
+			t.Errorf("Expected code daily_join_limit, got %v", result["code"])
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"sso/models"
+	"sso/pkg/services"
+	"testing"
+	"time"
+)
+
+func (r *fakeUserRepository) GetGroupMembers(groupID int) ([]models.GroupMember, error) {
+	var members []models.GroupMember
+	for _, user := range r.users {
+		if !user.InGroup(groupID) {
+			continue
+		}
+		role := models.GroupRoleMember
+		if r.moderators[[2]int{groupID, user.ID}] {
+			role = models.GroupRoleModerator
+		}
+		members = append(members, models.GroupMember{User: user, Role: role})
+	}
+	return members, nil
+}
+
+func (r *fakeUserRepository) AddGroupMember(groupID, userID int) error {
+	for nick, user := range r.users {
+		if user.ID == userID && !user.InGroup(groupID) {
+			user.GroupIDs = append(user.GroupIDs, int64(groupID))
+			r.users[nick] = user
+		}
+	}
+	return nil
+}
+
+func (r *fakeUserRepository) RemoveGroupMember(groupID, userID int) error {
+	for nick, user := range r.users {
+		if user.ID != userID || !user.InGroup(groupID) {
+			continue
+		}
+		var groupIDs []int64
+		for _, id := range user.GroupIDs {
+			if int(id) != groupID {
+				groupIDs = append(groupIDs, id)
+			}
+		}
+		user.GroupIDs = groupIDs
+		r.users[nick] = user
+		delete(r.moderators, [2]int{groupID, userID})
+		return nil
+	}
+	return errors.New("member not found")
+}
+
+func (r *fakeUserRepository) TransferGroupMember(userID, fromGroupID, toGroupID int) error {
+	if err := r.RemoveGroupMember(fromGroupID, userID); err != nil {
+		return err
+	}
+	return r.AddGroupMember(toGroupID, userID)
+}
+
+func (r *fakeUserRepository) GetQueueByID(id int) (models.Queue, error) {
+	for _, queue := range r.queues {
+		if queue.ID == id {
+			return queue, nil
+		}
+	}
+	return models.Queue{}, sql.ErrNoRows
+}
+
+func (r *fakeUserRepository) GetUserQueueIDs(userID int) ([]int, error) {
+	var ids []int
+	for _, queue := range r.queues {
+		if r.queueUsers[[2]int{queue.ID, userID}] {
+			ids = append(ids, queue.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeUserRepository) LeaveQueue(queueID, userID int) error {
+	if !r.queueUsers[[2]int{queueID, userID}] {
+		return errors.New("user not found in queue")
+	}
+	delete(r.queueUsers, [2]int{queueID, userID})
+	return nil
+}
+
+func (r *fakeUserRepository) CancelSlotBooking(queueID, userID int) error {
+	if !r.queueUsers[[2]int{queueID, userID}] {
+		return errors.New("booking not found")
+	}
+	delete(r.queueUsers, [2]int{queueID, userID})
+	return nil
+}
+
+func (r *fakeUserRepository) UpdateUser(id int, input models.User) error {
+	for nick, user := range r.users {
+		if user.ID != id {
+			continue
+		}
+		if input.GroupIDs != nil {
+			user.GroupIDs = input.GroupIDs
+		}
+		r.users[nick] = user
+		return nil
+	}
+	return errors.New("user not found")
+}
+
+// TestGroupMembers тестирует управление составом групп и вывод из недоступных очередей при переводе
+func TestGroupMembers(t *testing.T) {
+	repo := newFakeUserRepository(
+		models.User{ID: 1, Username: "moderator", TgNick: "@moderator", GroupIDs: []int64{7}},
+		models.User{ID: 2, Username: "student", TgNick: "@student", GroupIDs: []int64{7}},
+		models.User{ID: 3, Username: "newcomer", TgNick: "@newcomer"},
+	)
+	repo.groups[7] = models.Group{ID: 7, Code: "ИУ7-12Б"}
+	repo.groups[8] = models.Group{ID: 8, Code: "ИУ7-13Б"}
+	repo.moderators[[2]int{7, 1}] = true
+	service := services.NewAuthService(repo, models.Config{}, nil)
+
+	t.Run("ModeratorOnly", func(t *testing.T) {
+		if _, err := service.GetGroupMembers(2, false, 7); !errors.Is(err, services.ErrGroupModeratorRequired) {
+			t.Errorf("Expected member not to list group members, got %v", err)
+		}
+		if err := service.AddGroupMember(1, false, 8, 3); !errors.Is(err, services.ErrGroupModeratorRequired) {
+			t.Errorf("Expected moderator of another group to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("AddAndRemove", func(t *testing.T) {
+		if err := service.AddGroupMember(1, false, 7, 3); err != nil {
+			t.Fatalf("Expected moderator to add member, got %v", err)
+		}
+		members, err := service.GetGroupMembers(1, false, 7)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(members) != 3 {
+			t.Errorf("Expected 3 members, got %+v", members)
+		}
+
+		if _, err := service.RemoveGroupMember(1, false, 7, 3); err == nil {
+			t.Error("Expected last group of the user not to be removed")
+		}
+		repo.groups[9] = models.Group{ID: 9, Code: "ИУ7-14Б"}
+		if err := service.AddGroupMember(0, true, 9, 3); err != nil {
+			t.Fatalf("Expected admin to add member, got %v", err)
+		}
+		if _, err := service.RemoveGroupMember(1, false, 7, 3); err != nil {
+			t.Errorf("Expected moderator to remove member, got %v", err)
+		}
+		if user, _ := repo.GetUserByID(3); user.InGroup(7) {
+			t.Errorf("Expected user to leave group, got %+v", user.GroupIDs)
+		}
+	})
+
+	t.Run("Transfer", func(t *testing.T) {
+		future := time.Now().Add(time.Hour)
+		repo.queues = []models.Queue{
+			{ID: 1, Title: "ИУ7-12Б", TimeEnd: future, AllowedGroups: []int64{7}},
+			{ID: 2, Title: "ИУ7-12Б, запись", TimeEnd: future, AllowedGroups: []int64{7}, Mode: models.QueueModeSlots},
+			{ID: 3, Title: "Поток", TimeEnd: future, AllowedGroups: []int64{7, 8}},
+			{ID: 4, Title: "Для всех", TimeEnd: future},
+		}
+		for _, queue := range repo.queues {
+			repo.queueUsers[[2]int{queue.ID, 2}] = true
+		}
+
+		input := models.GroupTransferRequest{ToGroupID: 8}
+		if _, err := service.TransferGroupMember(1, false, 7, 2, input); !errors.Is(err, services.ErrGroupModeratorRequired) {
+			t.Errorf("Expected moderator of one group only to be rejected, got %v", err)
+		}
+
+		repo.moderators[[2]int{8, 1}] = true
+		change, err := service.TransferGroupMember(1, false, 7, 2, input)
+		if err != nil {
+			t.Fatalf("Expected moderator of both groups to transfer member, got %v", err)
+		}
+		if len(change.LeftQueues) != 2 || change.LeftQueues[0] != 1 || change.LeftQueues[1] != 2 {
+			t.Errorf("Expected user to leave queues of the old group, got %+v", change.LeftQueues)
+		}
+		if !repo.queueUsers[[2]int{3, 2}] || !repo.queueUsers[[2]int{4, 2}] {
+			t.Error("Expected user to stay in queues still available")
+		}
+		if user, _ := repo.GetUserByID(2); user.InGroup(7) || !user.InGroup(8) {
+			t.Errorf("Expected user to move to group 8, got %+v", user.GroupIDs)
+		}
+	})
+
+	t.Run("UpdateUserGroups", func(t *testing.T) {
+		if _, err := service.UpdateUser(2, models.User{GroupIDs: []int64{}}); !errors.Is(err, services.ErrGroupRequired) {
+			t.Errorf("Expected empty group list to be rejected, got %v", err)
+		}
+		if user, _ := repo.GetUserByID(2); !user.InGroup(8) {
+			t.Errorf("Expected groups to stay unchanged, got %+v", user.GroupIDs)
+		}
+
+		// Смена групп администратором выводит из очередей так же, как перевод модератором
+		change, err := service.UpdateUser(2, models.User{GroupIDs: []int64{9}})
+		if err != nil {
+			t.Fatalf("Expected groups to be updated, got %v", err)
+		}
+		if len(change.LeftQueues) != 1 || change.LeftQueues[0] != 3 {
+			t.Errorf("Expected user to leave queue of the old groups, got %+v", change.LeftQueues)
+		}
+		if !repo.queueUsers[[2]int{4, 2}] {
+			t.Error("Expected user to stay in queue open for everyone")
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"errors"
+	"sso/models"
+	"sso/pkg/services"
+	"testing"
+	"time"
+)
+
+// TestMultipleGroups тестирует допуск в очереди и роли пользователя, состоящего в нескольких группах
+func TestMultipleGroups(t *testing.T) {
+	repo := newFakeUserRepository(
+		models.User{ID: 1, Username: "assistant", TgNick: "@assistant", GroupIDs: []int64{7, 8}},
+	)
+	repo.groups[7] = models.Group{ID: 7, Code: "ИУ7-12Б"}
+	repo.groups[8] = models.Group{ID: 8, Code: "ИУ7-52Б"}
+	repo.groups[9] = models.Group{ID: 9, Code: "РК6-12Б"}
+	repo.moderators[[2]int{8, 1}] = true
+	service := services.NewAuthService(repo, models.Config{}, nil)
+
+	t.Run("QueueEligibility", func(t *testing.T) {
+		future := time.Now().Add(time.Hour)
+		repo.queues = []models.Queue{
+			{ID: 1, Title: "ИУ7-12Б", TimeEnd: future, AllowedGroups: []int64{7}},
+			{ID: 2, Title: "ИУ7-52Б", TimeEnd: future, AllowedGroups: []int64{8}},
+			{ID: 3, Title: "РК6-12Б", TimeEnd: future, AllowedGroups: []int64{9}},
+		}
+		queues, err := service.GetAvailableQueues(1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(queues) != 2 || queues[0].ID != 1 || queues[1].ID != 2 {
+			t.Errorf("Expected queues of both groups, got %+v", queues)
+		}
+	})
+
+	t.Run("RolePerGroup", func(t *testing.T) {
+		if _, err := service.CreateInvitation(1, false, 8, models.CreateInvitationRequest{}); err != nil {
+			t.Errorf("Expected moderator of group 8 to issue invitation, got %v", err)
+		}
+		if _, err := service.CreateInvitation(1, false, 7, models.CreateInvitationRequest{}); !errors.Is(err, services.ErrGroupModeratorRequired) {
+			t.Errorf("Expected member of group 7 not to issue invitation, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"sso/models"
+	"sso/pkg/services"
+	"testing"
+	"time"
+)
+
+func (r *fakeUserRepository) GetGroupByID(id int) (models.Group, error) {
+	group, ok := r.groups[id]
+	if !ok {
+		return models.Group{}, sql.ErrNoRows
+	}
+	return group, nil
+}
+
+func (r *fakeUserRepository) IsGroupModerator(userID, groupID int) (bool, error) {
+	return r.moderators[[2]int{groupID, userID}], nil
+}
+
+func (r *fakeUserRepository) CreateInvitation(invitation models.Invitation, codeHash string) (int, error) {
+	invitation.ID = len(r.invitations) + 1
+	r.invitations[codeHash] = &invitation
+	return invitation.ID, nil
+}
+
+func (r *fakeUserRepository) CreateUserByInvitation(user models.RegisterUser, codeHash string, tgUserID sql.NullInt64) (int, error) {
+	invitation, ok := r.invitations[codeHash]
+	if !ok || invitation.RevokedAt != nil || !invitation.ExpiresAt.After(time.Now()) || invitation.Uses >= invitation.MaxUses {
+		return 0, sql.ErrNoRows
+	}
+	invitation.Uses++
+	id := len(r.users) + 1
+	r.users[user.TgNick] = models.User{ID: id, TenantID: r.tenantID, Username: user.Username, TgNick: user.TgNick, GroupIDs: []int64{int64(invitation.GroupID)}, PasswordHash: user.Password, Status: user.Status}
+	return id, nil
+}
+
+// TestInvitations тестирует выдачу приглашений модератором и регистрацию по ним
+func TestInvitations(t *testing.T) {
+	repo := newFakeUserRepository(models.User{ID: 1, Username: "moderator", TgNick: "@moderator", GroupIDs: []int64{7}})
+	repo.groups[7] = models.Group{ID: 7, Code: "ИУ7-12Б"}
+	repo.moderators[[2]int{7, 1}] = true
+	service := services.NewAuthService(repo, models.Config{}, nil)
+
+	t.Run("SignUp_WithoutInvitation", func(t *testing.T) {
+		_, err := service.CreateUser(models.RegisterUser{Username: "outsider", Password: "password123", TgNick: "@outsider", Group: "ИУ7-12Б"})
+		if !errors.Is(err, services.ErrInvitationRequired) {
+			t.Errorf("Expected invitation to be required, got %v", err)
+		}
+	})
+
+	t.Run("CreateInvitation_NotModerator", func(t *testing.T) {
+		_, err := service.CreateInvitation(2, false, 7, models.CreateInvitationRequest{})
+		if !errors.Is(err, services.ErrGroupModeratorRequired) {
+			t.Errorf("Expected moderator access to be required, got %v", err)
+		}
+	})
+
+	t.Run("CreateInvitation_ExpiresInPast", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		if _, err := service.CreateInvitation(1, false, 7, models.CreateInvitationRequest{ExpiresAt: &past}); err == nil {
+			t.Error("Expected error for expiry in the past")
+		}
+	})
+
+	t.Run("SignUp_LimitedUseInvitation", func(t *testing.T) {
+		invitation, err := service.CreateInvitation(1, false, 7, models.CreateInvitationRequest{MaxUses: 2})
+		if err != nil {
+			t.Fatalf("Failed to create invitation: %v", err)
+		}
+
+		for _, nick := range []string{"@invited1", "@invited2"} {
+			// Код группы из запроса игнорируется, группа берется из приглашения
+			id, err := service.CreateUser(models.RegisterUser{Username: nick, Password: "password123", TgNick: nick, Group: "other", Invitation: invitation.Code})
+			if err != nil {
+				t.Fatalf("Expected registration by invitation, got %v", err)
+			}
+			if user, _ := repo.GetUserByID(id); !user.InGroup(7) {
+				t.Errorf("Expected user in group 7, got %v", user.GroupIDs)
+			}
+		}
+
+		_, err = service.CreateUser(models.RegisterUser{Username: "third", Password: "password123", TgNick: "@invited3", Invitation: invitation.Code})
+		if !errors.Is(err, services.ErrInvalidInvitation) {
+			t.Errorf("Expected exhausted invitation to be rejected, got %v", err)
+		}
+	})
+}
+
+// TestSignUpInvalidInvitation тестирует регистрацию с несуществующим приглашением
+func TestSignUpInvalidInvitation(t *testing.T) {
+	helper := NewTestHelper()
+
+	userData := models.RegisterUser{
+		Username:   "invitee",
+		Password:   "password123",
+		TgNick:     "@invitee",
+		Invitation: "0000000000000000",
+	}
+	resp, err := helper.makeRequest("POST", baseURL+"/auth/sign-up", userData, "")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for unknown invitation, got %d", resp.StatusCode)
+	}
+}
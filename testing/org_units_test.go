@@ -0,0 +1,188 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"sso/models"
+	"sso/pkg/services"
+	"testing"
+	"time"
+)
+
+func (r *fakeUserRepository) CreateOrgUnit(unit models.OrgUnit) (int, error) {
+	unit.ID = len(r.orgUnits) + 1
+	r.orgUnits[unit.ID] = &unit
+	return unit.ID, nil
+}
+
+func (r *fakeUserRepository) GetOrgUnitByID(id int) (models.OrgUnit, error) {
+	unit, ok := r.orgUnits[id]
+	if !ok {
+		return models.OrgUnit{}, sql.ErrNoRows
+	}
+	return *unit, nil
+}
+
+func (r *fakeUserRepository) UpdateOrgUnit(unit models.OrgUnit) error {
+	r.orgUnits[unit.ID] = &unit
+	return nil
+}
+
+func (r *fakeUserRepository) GetOrgUnitPath(id int) ([]int64, error) {
+	var path []int64
+	for unit, ok := r.orgUnits[id]; ok; {
+		path = append(path, int64(unit.ID))
+		if unit.ParentID == nil {
+			break
+		}
+		unit, ok = r.orgUnits[*unit.ParentID]
+	}
+	return path, nil
+}
+
+func (r *fakeUserRepository) GetGroupOrgUnits(groupID int) ([]int64, error) {
+	group, ok := r.groups[groupID]
+	if !ok || group.OrgUnitID == nil {
+		return nil, nil
+	}
+	return r.GetOrgUnitPath(*group.OrgUnitID)
+}
+
+func (r *fakeUserRepository) SetGroupOrgUnit(groupID int, unitID *int) error {
+	group := r.groups[groupID]
+	group.OrgUnitID = unitID
+	r.groups[groupID] = group
+	return nil
+}
+
+func (r *fakeUserRepository) IsOrgUnitAdmin(userID int, unitIDs []int64) (bool, error) {
+	for _, unitID := range unitIDs {
+		if r.unitAdmins[[2]int{int(unitID), userID}] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeUserRepository) GetAllQueues() ([]models.Queue, error) {
+	return r.queues, nil
+}
+
+// TestOrgUnits тестирует права администраторов факультетов и кафедр и допуск в очереди по дереву
+func TestOrgUnits(t *testing.T) {
+	repo := newFakeUserRepository(
+		models.User{ID: 1, Username: "dean", TgNick: "@dean"},
+		models.User{ID: 2, Username: "student", TgNick: "@student", GroupIDs: []int64{7}},
+		models.User{ID: 3, Username: "other", TgNick: "@other", GroupIDs: []int64{8}},
+	)
+	repo.groups[7] = models.Group{ID: 7, Code: "ИУ7-12Б"}
+	repo.groups[8] = models.Group{ID: 8, Code: "РК6-12Б"}
+	service := services.NewAuthService(repo, models.Config{}, nil)
+
+	var facultyID, departmentID int
+
+	t.Run("Create_RootRequiresAdmin", func(t *testing.T) {
+		_, err := service.CreateOrgUnit(1, false, models.OrgUnitRequest{Kind: models.OrgUnitFaculty, Code: "ИУ"})
+		if !errors.Is(err, services.ErrOrgUnitAdminRequired) {
+			t.Errorf("Expected admin access to be required, got %v", err)
+		}
+		if _, err := service.CreateOrgUnit(1, true, models.OrgUnitRequest{Kind: "chair", Code: "ИУ"}); err == nil {
+			t.Error("Expected unknown kind to be rejected")
+		}
+
+		facultyID, err = service.CreateOrgUnit(1, true, models.OrgUnitRequest{Kind: models.OrgUnitFaculty, Code: "ИУ"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		repo.unitAdmins[[2]int{facultyID, 1}] = true
+	})
+
+	t.Run("Create_ByFacultyAdmin", func(t *testing.T) {
+		var err error
+		departmentID, err = service.CreateOrgUnit(1, false, models.OrgUnitRequest{ParentID: &facultyID, Kind: models.OrgUnitDepartment, Code: "ИУ7"})
+		if err != nil {
+			t.Fatalf("Expected faculty admin to create department, got %v", err)
+		}
+		if err := service.SetGroupOrgUnit(1, false, departmentID, 7); err != nil {
+			t.Fatalf("Expected faculty admin to add group to department, got %v", err)
+		}
+		if _, err := service.CreateOrgUnit(2, false, models.OrgUnitRequest{ParentID: &facultyID, Kind: models.OrgUnitDepartment, Code: "ИУ5"}); !errors.Is(err, services.ErrOrgUnitAdminRequired) {
+			t.Errorf("Expected student not to create department, got %v", err)
+		}
+	})
+
+	t.Run("Update_NoCycles", func(t *testing.T) {
+		err := service.UpdateOrgUnit(1, true, facultyID, models.OrgUnitRequest{ParentID: &departmentID, Kind: models.OrgUnitFaculty, Code: "ИУ"})
+		if err == nil {
+			t.Error("Expected moving unit into its own subtree to be rejected")
+		}
+	})
+
+	t.Run("FacultyAdmin_ModeratesGroups", func(t *testing.T) {
+		if _, err := service.CreateInvitation(1, false, 7, models.CreateInvitationRequest{}); err != nil {
+			t.Errorf("Expected faculty admin to moderate group in subtree, got %v", err)
+		}
+		if _, err := service.CreateInvitation(1, false, 8, models.CreateInvitationRequest{}); !errors.Is(err, services.ErrGroupModeratorRequired) {
+			t.Errorf("Expected group outside subtree to require moderator, got %v", err)
+		}
+	})
+
+	t.Run("QueueEligibility", func(t *testing.T) {
+		future := time.Now().Add(time.Hour)
+		repo.queues = []models.Queue{
+			{ID: 1, Title: "Факультет", TimeEnd: future, AllowedUnits: []int64{int64(facultyID)}},
+			{ID: 2, Title: "Группа", TimeEnd: future, AllowedGroups: []int64{8}},
+			{ID: 3, Title: "Открытая", TimeEnd: future},
+		}
+
+		expected := map[int][]int{2: {1, 3}, 3: {2, 3}}
+		for userID, queueIDs := range expected {
+			queues, err := service.GetAvailableQueues(userID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(queues) != len(queueIDs) {
+				t.Fatalf("User %d: expected queues %v, got %+v", userID, queueIDs, queues)
+			}
+			for i, queue := range queues {
+				if queue.ID != queueIDs[i] {
+					t.Errorf("User %d: expected queues %v, got %+v", userID, queueIDs, queues)
+				}
+			}
+		}
+	})
+
+	t.Run("UnitAdmin_ManagesQueues", func(t *testing.T) {
+		repo.queues = append(repo.queues, models.Queue{ID: 4, Title: "Кафедра", AllowedGroups: []int64{7}})
+
+		for queueID, allowed := range map[int]bool{1: true, 2: false, 3: false, 4: true} {
+			err := service.CheckQueueManager(1, false, queueID)
+			if allowed && err != nil {
+				t.Errorf("Queue %d: expected faculty admin to manage queue, got %v", queueID, err)
+			}
+			if !allowed && !errors.Is(err, services.ErrOrgUnitAdminRequired) {
+				t.Errorf("Queue %d: expected org unit admin access to be required, got %v", queueID, err)
+			}
+		}
+		if err := service.CheckQueueManager(2, false, 1); !errors.Is(err, services.ErrOrgUnitAdminRequired) {
+			t.Errorf("Expected student not to manage queue, got %v", err)
+		}
+
+		mixed := models.Queue{AllowedUnits: []int64{int64(departmentID)}, AllowedGroups: []int64{8}}
+		if err := service.CheckQueueRestrictions(1, false, mixed); !errors.Is(err, services.ErrOrgUnitAdminRequired) {
+			t.Errorf("Expected group outside subtree to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("UnitAdmin_StatsFilteredByUnit", func(t *testing.T) {
+		if err := service.CheckStatsAccess(1, false, models.StatsFilter{OrgUnitID: departmentID}); err != nil {
+			t.Errorf("Expected faculty admin to see department stats, got %v", err)
+		}
+		if err := service.CheckStatsAccess(1, false, models.StatsFilter{}); !errors.Is(err, services.ErrOrgUnitAdminRequired) {
+			t.Errorf("Expected unfiltered stats to require admin, got %v", err)
+		}
+		if err := service.CheckStatsAccess(2, false, models.StatsFilter{OrgUnitID: facultyID}); !errors.Is(err, services.ErrOrgUnitAdminRequired) {
+			t.Errorf("Expected student not to see faculty stats, got %v", err)
+		}
+	})
+}
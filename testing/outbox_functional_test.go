@@ -0,0 +1,246 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sso/models"
+	"sso/pkg/outbox"
+	"testing"
+	"time"
+)
+
+// memoryOutbox хранит сообщения outbox в памяти для проверки диспетчера
+type memoryOutbox struct {
+	messages map[int64]*models.OutboxMessage
+	limits   []int // Размеры запрошенных выборок
+}
+
+func (m *memoryOutbox) ClaimOutboxMessages(limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	m.limits = append(m.limits, limit)
+	var claimed []models.OutboxMessage
+	for _, message := range m.messages {
+		if message.Status == models.OutboxStatusPending && !message.NextAttemptAt.After(time.Now()) && len(claimed) < limit {
+			message.NextAttemptAt = time.Now().Add(lease)
+			claimed = append(claimed, *message)
+		}
+	}
+	return claimed, nil
+}
+
+func (m *memoryOutbox) PruneOutboxMessages(deliveredBefore time.Time) (int, error) {
+	pruned := 0
+	for id, message := range m.messages {
+		if message.Status == models.OutboxStatusDelivered && message.CreatedAt.Before(deliveredBefore) {
+			delete(m.messages, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// due делает ожидающие сообщения готовыми к доставке, как будто задержка повтора истекла
+func (m *memoryOutbox) due() {
+	for _, message := range m.messages {
+		message.NextAttemptAt = time.Time{}
+	}
+}
+
+func (m *memoryOutbox) CompleteOutboxMessage(id int64, deliveredSinks []string) error {
+	m.messages[id].Status = models.OutboxStatusDelivered
+	m.messages[id].DeliveredSinks = deliveredSinks
+	return nil
+}
+
+func (m *memoryOutbox) RetryOutboxMessage(id int64, deliveredSinks []string, nextAttemptAt time.Time, lastError string) error {
+	m.messages[id].Attempts++
+	m.messages[id].DeliveredSinks = deliveredSinks
+	m.messages[id].NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (m *memoryOutbox) DeadLetterOutboxMessage(id int64, deliveredSinks []string, lastError string) error {
+	m.messages[id].Attempts++
+	m.messages[id].Status = models.OutboxStatusDead
+	m.messages[id].DeliveredSinks = deliveredSinks
+	return nil
+}
+
+// countingSink считает доставки и отказывает первые failures раз
+type countingSink struct {
+	name      string
+	failures  int
+	delivered int
+}
+
+func (s *countingSink) Name() string { return s.name }
+func (s *countingSink) Accepts(eventType string) bool {
+	return eventType == models.OutboxParticipantServed
+}
+
+func (s *countingSink) Deliver(ctx context.Context, message models.OutboxMessage) error {
+	if s.failures > 0 {
+		s.failures--
+		return fmt.Errorf("sink %s is unavailable", s.name)
+	}
+	s.delivered++
+	return nil
+}
+
+// TestOutboxDispatcher тестирует повторы, независимую доставку получателям и dead-letter
+func TestOutboxDispatcher(t *testing.T) {
+	cfg := models.OutboxConfig{BatchSize: 10, MaxAttempts: 3, BaseBackoff: time.Second, MaxBackoff: 3 * time.Second}
+
+	newStore := func(eventType string) *memoryOutbox {
+		return &memoryOutbox{messages: map[int64]*models.OutboxMessage{
+			1: {ID: 1, EventType: eventType, Payload: []byte(`{}`), Status: models.OutboxStatusPending},
+		}}
+	}
+
+	t.Run("Backoff_ExponentialWithCap", func(t *testing.T) {
+		dispatcher := outbox.NewDispatcher(newStore(models.OutboxParticipantServed), cfg)
+		expected := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+		for i, delay := range expected {
+			if got := dispatcher.Backoff(i + 1); got != delay {
+				t.Errorf("Attempt %d: expected backoff %s, got %s", i+1, delay, got)
+			}
+		}
+	})
+
+	t.Run("Retry_DeliversOncePerSink", func(t *testing.T) {
+		store := newStore(models.OutboxParticipantServed)
+		healthy := &countingSink{name: "healthy"}
+		flaky := &countingSink{name: "flaky", failures: 1}
+		dispatcher := outbox.NewDispatcher(store, cfg, healthy, flaky)
+
+		dispatcher.DispatchBatch(context.Background())
+		if store.messages[1].Status != models.OutboxStatusPending || store.messages[1].Attempts != 1 {
+			t.Fatalf("Expected pending message with one failed attempt, got %+v", store.messages[1])
+		}
+
+		store.due()
+		dispatcher.DispatchBatch(context.Background())
+		if store.messages[1].Status != models.OutboxStatusDelivered {
+			t.Fatalf("Expected delivered message, got %+v", store.messages[1])
+		}
+		if healthy.delivered != 1 || flaky.delivered != 1 {
+			t.Errorf("Expected one delivery per sink, got healthy=%d flaky=%d", healthy.delivered, flaky.delivered)
+		}
+	})
+
+	t.Run("DeadLetter_AfterMaxAttempts", func(t *testing.T) {
+		store := newStore(models.OutboxParticipantServed)
+		dispatcher := outbox.NewDispatcher(store, cfg, &countingSink{name: "down", failures: 10})
+
+		for i := 0; i < cfg.MaxAttempts; i++ {
+			store.due()
+			dispatcher.DispatchBatch(context.Background())
+		}
+		if store.messages[1].Status != models.OutboxStatusDead || store.messages[1].Attempts != cfg.MaxAttempts {
+			t.Errorf("Expected dead-lettered message after %d attempts, got %+v", cfg.MaxAttempts, store.messages[1])
+		}
+	})
+
+	t.Run("Batch_ClaimsMessagesOneByOne", func(t *testing.T) {
+		store := newStore(models.OutboxParticipantServed)
+		store.messages[2] = &models.OutboxMessage{ID: 2, EventType: models.OutboxParticipantServed, Payload: []byte(`{}`), Status: models.OutboxStatusPending}
+		sink := &countingSink{name: "slow", failures: 1}
+
+		// Неудачно доставленное сообщение не выдается повторно в той же пачке
+		if dispatched := outbox.NewDispatcher(store, cfg, sink).DispatchBatch(context.Background()); dispatched != 2 {
+			t.Fatalf("Expected 2 dispatched messages, got %d", dispatched)
+		}
+		for _, limit := range store.limits {
+			if limit != 1 {
+				t.Errorf("Expected each message to be claimed separately, got claims of %v", store.limits)
+				break
+			}
+		}
+	})
+
+	t.Run("Prune_DeliveredOnly", func(t *testing.T) {
+		old := time.Now().Add(-30 * 24 * time.Hour)
+		store := &memoryOutbox{messages: map[int64]*models.OutboxMessage{
+			1: {ID: 1, Status: models.OutboxStatusDelivered, CreatedAt: old},
+			2: {ID: 2, Status: models.OutboxStatusDead, CreatedAt: old},
+			3: {ID: 3, Status: models.OutboxStatusDelivered, CreatedAt: time.Now()},
+		}}
+		outbox.NewDispatcher(store, cfg).Prune()
+		if _, ok := store.messages[1]; ok || len(store.messages) != 2 {
+			t.Errorf("Expected only the old delivered message to be pruned, got %v", store.messages)
+		}
+	})
+
+	t.Run("UnsubscribedEvent_Completed", func(t *testing.T) {
+		store := newStore(models.OutboxQueueCreated)
+		sink := &countingSink{name: "served-only"}
+		outbox.NewDispatcher(store, cfg, sink).DispatchBatch(context.Background())
+
+		if store.messages[1].Status != models.OutboxStatusDelivered || sink.delivered != 0 {
+			t.Errorf("Expected message completed without delivery, got %+v", store.messages[1])
+		}
+	})
+}
+
+// TestOutboxMessages тестирует просмотр outbox администратором
+func TestOutboxMessages(t *testing.T) {
+	helper := NewTestHelper()
+
+	helper.createTestUser(t, "outboxadmin", "password123", "@outboxadmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@outboxadmin", "password123")
+
+	helper.createTestUser(t, "outboxuser", "password123", "@outboxuser", "ИУ7-12Б")
+	userToken := helper.loginUser(t, "@outboxuser", "password123")
+
+	helper.createTestQueue(t, adminToken, "Outbox Test Queue")
+
+	t.Run("GetOutbox_Admin", func(t *testing.T) {
+		for _, status := range []string{"", models.OutboxStatusPending, models.OutboxStatusDelivered} {
+			resp, err := helper.makeRequest("GET", baseURL+"/api/admin/outbox?status="+status, nil, adminToken)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Status %q: expected 200, got %d", status, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("GetOutbox_UnknownStatus", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", baseURL+"/api/admin/outbox?status=lost", nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetOutbox_RegularUser", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", baseURL+"/api/admin/outbox", nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for non-admin user, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("RetryOutbox_NotDead", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", baseURL+"/api/admin/outbox/999999999/retry", nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"sso/models"
+	"testing"
+)
+
+// TestPasswordChange тестирует смену пароля текущим пользователем
+func TestPasswordChange(t *testing.T) {
+	helper := NewTestHelper()
+
+	helper.createTestUser(t, "passworduser", "password123", "@passworduser", "ИУ7-12Б")
+	token := helper.loginUser(t, "@passworduser", "password123")
+	otherToken := helper.loginUser(t, "@passworduser", "password123")
+
+	t.Run("ChangePassword_WrongOldPassword", func(t *testing.T) {
+		input := models.ChangePasswordRequest{OldPassword: "wrongpassword", NewPassword: "newpassword123"}
+		resp, err := helper.makeRequest("PUT", baseURL+"/api/profile/password", input, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for wrong old password, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ChangePassword_TooShort", func(t *testing.T) {
+		input := models.ChangePasswordRequest{OldPassword: "password123", NewPassword: "short"}
+		resp, err := helper.makeRequest("PUT", baseURL+"/api/profile/password", input, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for short password, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ChangePassword_Success", func(t *testing.T) {
+		input := models.ChangePasswordRequest{OldPassword: "password123", NewPassword: "newpassword123"}
+		resp, err := helper.makeRequest("PUT", baseURL+"/api/profile/password", input, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		helper.loginUser(t, "@passworduser", "newpassword123")
+
+		// Смена пароля завершает остальные сессии пользователя, текущая продолжает действовать
+		for name, tc := range map[string]struct {
+			token  string
+			status int
+		}{"current": {token, http.StatusOK}, "other": {otherToken, http.StatusUnauthorized}} {
+			resp, err := helper.makeRequest("GET", baseURL+"/api/profile", nil, tc.token)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.status {
+				t.Errorf("Expected status %d for %s session, got %d", tc.status, name, resp.StatusCode)
+			}
+		}
+	})
+}
+
+// TestPasswordReset тестирует сброс пароля по ссылке, выданной администратором
+func TestPasswordReset(t *testing.T) {
+	helper := NewTestHelper()
+
+	helper.createTestUser(t, "resetadmin", "password123", "@resetadmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@resetadmin", "password123")
+
+	userID := helper.createTestUser(t, "resetuser", "password123", "@resetuser", "ИУ7-12Б")
+	userToken := helper.loginUser(t, "@resetuser", "password123")
+
+	var reset models.PasswordResetToken
+
+	t.Run("CreateReset_RegularUser", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/admin/users/%d/password-reset", baseURL, userID), nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for non-admin user, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CreateReset_Admin", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/admin/users/%d/password-reset", baseURL, userID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var result struct {
+			Reset models.PasswordResetToken `json:"reset"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if result.Reset.Token == "" || result.Reset.ExpiresAt.IsZero() {
+			t.Fatalf("Expected reset token and expiration, got %+v", result.Reset)
+		}
+		reset = result.Reset
+	})
+
+	t.Run("ResetPassword_InvalidToken", func(t *testing.T) {
+		input := models.ResetPasswordRequest{Token: "invalid", NewPassword: "resetpassword123"}
+		resp, err := helper.makeRequest("POST", baseURL+"/auth/password-reset", input, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for invalid token, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ResetPassword_Success", func(t *testing.T) {
+		input := models.ResetPasswordRequest{Token: reset.Token, NewPassword: "resetpassword123"}
+		resp, err := helper.makeRequest("POST", baseURL+"/auth/password-reset", input, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		helper.loginUser(t, "@resetuser", "resetpassword123")
+	})
+
+	t.Run("ResetPassword_TokenIsSingleUse", func(t *testing.T) {
+		input := models.ResetPasswordRequest{Token: reset.Token, NewPassword: "anotherpassword123"}
+		resp, err := helper.makeRequest("POST", baseURL+"/auth/password-reset", input, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for used token, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// TestPasswordResetTelegram тестирует сброс пароля по коду из Telegram
+func TestPasswordResetTelegram(t *testing.T) {
+	helper := NewTestHelper()
+
+	helper.createTestUser(t, "tgresetuser", "password123", "@tgresetuser", "ИУ7-12Б")
+
+	t.Run("ForgotPassword_UnknownUser", func(t *testing.T) {
+		input := models.ForgotPasswordRequest{TgNick: "@nosuchresetuser"}
+		resp, err := helper.makeRequest("POST", baseURL+"/auth/password-reset/telegram", input, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			t.Skip("Telegram bot is not configured")
+		}
+		// Ответ для неизвестного пользователя не должен отличаться от ответа для существующего
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ForgotPassword_MissingNick", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", baseURL+"/auth/password-reset/telegram", map[string]string{}, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ResetWithCode_NoCodeRequested", func(t *testing.T) {
+		input := models.ResetPasswordByCodeRequest{TgNick: "@tgresetuser", Code: "000000", NewPassword: "codepassword123"}
+		resp, err := helper.makeRequest("POST", baseURL+"/auth/password-reset/telegram/confirm", input, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 without requested code, got %d", resp.StatusCode)
+		}
+
+		helper.loginUser(t, "@tgresetuser", "password123")
+	})
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"errors"
+	"sso/models"
+	"sso/pkg/services"
+	"testing"
+)
+
+func (r *fakeUserRepository) GetPendingUsers(groupID int) ([]models.User, error) {
+	var users []models.User
+	for _, user := range r.users {
+		if user.InGroup(groupID) && user.Status == models.UserStatusPending {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepository) ApproveUser(userID, groupID, approvedBy int) error {
+	for nick, user := range r.users {
+		if user.ID == userID && user.InGroup(groupID) && user.Status == models.UserStatusPending {
+			user.Status = models.UserStatusActive
+			r.users[nick] = user
+			return nil
+		}
+	}
+	return errors.New("pending user not found")
+}
+
+func (r *fakeUserRepository) RejectUser(userID, groupID int) error {
+	for nick, user := range r.users {
+		if user.ID == userID && user.InGroup(groupID) && user.Status == models.UserStatusPending {
+			delete(r.users, nick)
+			return nil
+		}
+	}
+	return errors.New("pending user not found")
+}
+
+// TestRegistrationApproval тестирует подтверждение самостоятельной регистрации модератором группы
+func TestRegistrationApproval(t *testing.T) {
+	repo := newFakeUserRepository(models.User{ID: 1, Username: "moderator", TgNick: "@moderator", GroupIDs: []int64{7}, Status: models.UserStatusActive})
+	repo.groups[7] = models.Group{ID: 7, Code: "ИУ7-12Б"}
+	repo.moderators[[2]int{7, 1}] = true
+	cfg := models.Config{Registration: models.RegistrationConfig{RequireApproval: true}}
+	service := services.NewAuthService(repo, cfg, nil).ForTenant(1)
+
+	invitation, err := service.CreateInvitation(1, false, 7, models.CreateInvitationRequest{MaxUses: 2})
+	if err != nil {
+		t.Fatalf("Failed to create invitation: %v", err)
+	}
+	signUp := func(nick string) int {
+		id, err := service.CreateUser(models.RegisterUser{Username: nick, Password: "password123", TgNick: nick, Invitation: invitation.Code})
+		if err != nil {
+			t.Fatalf("Expected registration by invitation, got %v", err)
+		}
+		return id
+	}
+	studentID := signUp("@student")
+	credentials := models.AuthUser{TgNick: "@student", Password: "password123"}
+
+	t.Run("SignIn_Pending", func(t *testing.T) {
+		if _, err := service.SignIn(credentials, models.ClientInfo{}); !errors.Is(err, services.ErrAccountPendingApproval) {
+			t.Errorf("Expected pending account to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("PendingUsers_NotModerator", func(t *testing.T) {
+		if _, err := service.GetPendingUsers(studentID, false, 7); !errors.Is(err, services.ErrGroupModeratorRequired) {
+			t.Errorf("Expected moderator access to be required, got %v", err)
+		}
+		if err := service.ApproveUser(studentID, false, 7, studentID); !errors.Is(err, services.ErrGroupModeratorRequired) {
+			t.Errorf("Expected pending user not to approve himself, got %v", err)
+		}
+	})
+
+	t.Run("PendingUsers_Moderator", func(t *testing.T) {
+		users, err := service.GetPendingUsers(1, false, 7)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(users) != 1 || users[0].ID != studentID {
+			t.Errorf("Expected only the new student in approval queue, got %+v", users)
+		}
+	})
+
+	t.Run("Approve", func(t *testing.T) {
+		if err := service.ApproveUser(1, false, 7, studentID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		result, err := service.SignIn(credentials, models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Expected approved user to sign in, got %v", err)
+		}
+		if _, err := service.ParseToken(result.Token); err != nil {
+			t.Errorf("Expected token of approved user to be valid, got %v", err)
+		}
+		if err := service.ApproveUser(1, false, 7, studentID); err == nil {
+			t.Error("Expected already approved user not to be in approval queue")
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		id := signUp("@stranger")
+		if err := service.RejectUser(1, false, 7, id); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := service.SignIn(models.AuthUser{TgNick: "@stranger", Password: "password123"}, models.ClientInfo{}); err == nil {
+			t.Error("Expected rejected user to be deleted")
+		}
+	})
+}
@@ -0,0 +1,242 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sso/models"
+	"sso/pkg/services"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (r *fakeUserRepository) CreateSession(session models.Session) (int, error) {
+	session.ID = len(r.sessions) + 1
+	session.CreatedAt, session.LastSeenAt = time.Now(), time.Now()
+	r.sessions[session.ID] = &session
+	return session.ID, nil
+}
+
+func (r *fakeUserRepository) GetActiveSession(id, userID int) (models.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok || session.UserID != userID || session.RevokedAt != nil || !session.ExpiresAt.After(time.Now()) {
+		return models.Session{}, sql.ErrNoRows
+	}
+	user, err := r.GetUserByID(userID)
+	if err != nil {
+		return models.Session{}, sql.ErrNoRows
+	}
+	session.UserStatus = user.Status
+	return *session, nil
+}
+
+func (r *fakeUserRepository) GetActiveSessions(userID int) ([]models.Session, error) {
+	var sessions []models.Session
+	for id := range r.sessions {
+		if session, err := r.GetActiveSession(id, userID); err == nil {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeUserRepository) TouchSession(id int) error {
+	r.sessions[id].LastSeenAt = time.Now()
+	return nil
+}
+
+func (r *fakeUserRepository) RevokeSession(id, userID int) error {
+	if _, err := r.GetActiveSession(id, userID); err != nil {
+		return errors.New("session not found")
+	}
+	now := time.Now()
+	r.sessions[id].RevokedAt = &now
+	return nil
+}
+
+func (r *fakeUserRepository) RevokeUserSessions(userID int) (int, error) {
+	sessions, _ := r.GetActiveSessions(userID)
+	for _, session := range sessions {
+		_ = r.RevokeSession(session.ID, userID)
+	}
+	return len(sessions), nil
+}
+
+// SignOutEverywhere в тестах отзывает сессии; API-ключей и чатов бота у поддельного репозитория нет
+func (r *fakeUserRepository) SignOutEverywhere(userID int) (models.SignOutResult, error) {
+	if _, err := r.GetUserByID(userID); err != nil {
+		return models.SignOutResult{}, fmt.Errorf("user not found")
+	}
+	revoked, err := r.RevokeUserSessions(userID)
+	return models.SignOutResult{Sessions: revoked}, err
+}
+
+func (r *fakeUserRepository) RevokeOtherSessions(userID, keepID int) (int, error) {
+	sessions, _ := r.GetActiveSessions(userID)
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID != keepID && r.RevokeSession(session.ID, userID) == nil {
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (r *fakeUserRepository) UpdatePasswordHash(userID int, passwordHash string) error {
+	for nick, user := range r.users {
+		if user.ID == userID {
+			user.PasswordHash = passwordHash
+			r.users[nick] = user
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// TestSessions тестирует привязку JWT токенов к сессиям и их отзыв
+func TestSessions(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	repo := newFakeUserRepository(models.User{ID: 1, Username: "student", TgNick: "@student", PasswordHash: string(hash)})
+	service := services.NewAuthService(repo, models.Config{}, nil).ForTenant(1)
+
+	signIn := func(t *testing.T, userAgent string) string {
+		result, err := service.SignIn(models.AuthUser{TgNick: "@student", Password: "password123"},
+			models.ClientInfo{UserAgent: userAgent, IP: "127.0.0.1"})
+		if err != nil {
+			t.Fatalf("Failed to sign in: %v", err)
+		}
+		return result.Token
+	}
+
+	laptop, phone := signIn(t, "laptop"), signIn(t, "phone")
+
+	t.Run("ListSessions", func(t *testing.T) {
+		identity, err := service.ParseToken(laptop)
+		if err != nil {
+			t.Fatalf("Expected valid token, got %v", err)
+		}
+		sessions, err := service.GetSessions(1, identity.SessionID)
+		if err != nil {
+			t.Fatalf("Failed to list sessions: %v", err)
+		}
+		if len(sessions) != 2 {
+			t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+		}
+		for _, session := range sessions {
+			if session.Current != (session.UserAgent == "laptop") {
+				t.Errorf("Session %q has wrong current flag", session.UserAgent)
+			}
+		}
+	})
+
+	t.Run("RevokeSession", func(t *testing.T) {
+		identity, _ := service.ParseToken(phone)
+		if err := service.RevokeSession(1, identity.SessionID); err != nil {
+			t.Fatalf("Failed to revoke session: %v", err)
+		}
+		if _, err := service.ParseToken(phone); err == nil {
+			t.Error("Expected token of revoked session to be rejected")
+		}
+		if _, err := service.ParseToken(laptop); err != nil {
+			t.Errorf("Expected other session to stay active, got %v", err)
+		}
+		if err := service.RevokeSession(2, identity.SessionID); err == nil {
+			t.Error("Expected revoking a session of another user to fail")
+		}
+	})
+
+	t.Run("ChangePassword_RevokesOtherSessions", func(t *testing.T) {
+		tablet := signIn(t, "tablet")
+		identity, _ := service.ParseToken(laptop)
+		input := models.ChangePasswordRequest{OldPassword: "password123", NewPassword: "newpassword123"}
+		if err := service.ChangePassword(1, identity.SessionID, input); err != nil {
+			t.Fatalf("Failed to change password: %v", err)
+		}
+		if _, err := service.ParseToken(tablet); err == nil {
+			t.Error("Expected other sessions to be revoked after password change")
+		}
+		if _, err := service.ParseToken(laptop); err != nil {
+			t.Errorf("Expected current session to stay active, got %v", err)
+		}
+		input = models.ChangePasswordRequest{OldPassword: "newpassword123", NewPassword: "password123"}
+		if err := service.ChangePassword(1, identity.SessionID, input); err != nil {
+			t.Fatalf("Failed to restore password: %v", err)
+		}
+	})
+
+	t.Run("SignOutEverywhere", func(t *testing.T) {
+		signIn(t, "tablet")
+		result, err := service.SignOutEverywhere(1)
+		if err != nil || result.Sessions != 2 {
+			t.Fatalf("Expected 2 revoked sessions, got %d (%v)", result.Sessions, err)
+		}
+		if _, err := service.ParseToken(laptop); err == nil {
+			t.Error("Expected all tokens to be rejected after force logout")
+		}
+		if _, err := service.SignOutEverywhere(999); err == nil {
+			t.Error("Expected error for unknown user")
+		}
+	})
+}
+
+// TestSessionEndpoints тестирует список сессий и выход из сессии через API
+func TestSessionEndpoints(t *testing.T) {
+	helper := NewTestHelper()
+
+	helper.createTestUser(t, "sessionuser", "password123", "@sessionuser", "ИУ7-12Б")
+	token := helper.loginUser(t, "@sessionuser", "password123")
+	other := helper.loginUser(t, "@sessionuser", "password123")
+
+	var result struct {
+		Sessions []models.Session `json:"sessions"`
+	}
+	resp, err := helper.makeRequest("GET", baseURL+"/api/profile/sessions", nil, token)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if err := helper.parseResponse(resp, &result); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(result.Sessions) < 2 {
+		t.Fatalf("Expected at least 2 sessions, got %d", len(result.Sessions))
+	}
+
+	// Завершаем все сессии, кроме текущей
+	for _, session := range result.Sessions {
+		if session.Current {
+			continue
+		}
+		resp, err := helper.makeRequest("DELETE", fmt.Sprintf("%s/api/profile/sessions/%d", baseURL, session.ID), nil, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	}
+
+	resp, err = helper.makeRequest("GET", baseURL+"/api/profile", nil, other)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for revoked session, got %d", resp.StatusCode)
+	}
+
+	resp, err = helper.makeRequest("GET", baseURL+"/api/profile", nil, token)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected current session to stay active, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"sso/models"
+	"sso/pkg/ratelimit"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestRateLimiter тестирует ограничение частоты по алгоритму token bucket
+func TestRateLimiter(t *testing.T) {
+	t.Run("MemoryStore_BurstThenReject", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), 1, 3)
+		for i := 0; i < 3; i++ {
+			if allowed, _, _ := limiter.Allow("ip:127.0.0.1"); !allowed {
+				t.Fatalf("Attempt %d: expected to be allowed within burst", i+1)
+			}
+		}
+
+		allowed, retryAfter, err := limiter.Allow("ip:127.0.0.1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if allowed {
+			t.Fatalf("Expected attempt over burst to be rejected")
+		}
+		if retryAfter <= 0 || retryAfter > time.Minute {
+			t.Errorf("Expected retry after up to one minute, got %v", retryAfter)
+		}
+
+		// Корзины разных ключей независимы
+		if allowed, _, _ := limiter.Allow("ip:127.0.0.2"); !allowed {
+			t.Errorf("Expected another key to be allowed")
+		}
+	})
+
+	t.Run("Limiter_Disabled", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), 0, 0)
+		for i := 0; i < 100; i++ {
+			if allowed, _, _ := limiter.Allow("account:@user"); !allowed {
+				t.Fatalf("Expected disabled limiter to allow every attempt")
+			}
+		}
+	})
+
+	t.Run("Refill_RestoresTokens", func(t *testing.T) {
+		tokens, allowed, wait := ratelimit.Refill(0, 30*time.Second, 1.0/60, 5)
+		if allowed || wait != 30*time.Second || tokens != 0.5 {
+			t.Errorf("Expected half a token and 30s wait, got tokens=%v allowed=%v wait=%v", tokens, allowed, wait)
+		}
+
+		tokens, allowed, _ = ratelimit.Refill(0, time.Hour, 1.0/60, 5)
+		if !allowed || tokens != 4 {
+			t.Errorf("Expected bucket capped at burst, got tokens=%v allowed=%v", tokens, allowed)
+		}
+	})
+}
+
+// TestSignInProtection тестирует ограничение попыток входа и снятие блокировки администратором
+func TestSignInProtection(t *testing.T) {
+	helper := NewTestHelper()
+
+	helper.createTestUser(t, "lockadmin", "password123", "@lockadmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@lockadmin", "password123")
+
+	userID := helper.createTestUser(t, "lockuser", "password123", "@lockuser", "ИУ7-12Б")
+	userToken := helper.loginUser(t, "@lockuser", "password123")
+
+	t.Run("SignIn_RateLimitedPerAccount", func(t *testing.T) {
+		input := models.AuthUser{TgNick: fmt.Sprintf("@bruteforce%d", time.Now().UnixNano()), Password: "guess"}
+		for i := 0; i < 50; i++ {
+			resp, err := helper.makeRequest("POST", baseURL+"/auth/sign-in", input, "")
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode == http.StatusTooManyRequests {
+				if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err != nil || seconds <= 0 {
+					t.Errorf("Expected positive Retry-After header, got %q", resp.Header.Get("Retry-After"))
+				}
+				return
+			}
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Fatalf("Attempt %d: expected status 401 or 429, got %d", i+1, resp.StatusCode)
+			}
+		}
+		t.Skip("Sign-in rate limiting is disabled in configuration")
+	})
+
+	t.Run("UnlockUser_RegularUser", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/admin/users/%d/unlock", baseURL, userID), nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for non-admin user, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UnlockUser_Admin", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/admin/users/%d/unlock", baseURL, userID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UnlockUser_NotFound", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", baseURL+"/api/admin/users/999999/unlock", nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404 for unknown user, got %d", resp.StatusCode)
+		}
+	})
+}
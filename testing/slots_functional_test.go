@@ -0,0 +1,272 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"sso/models"
+	"testing"
+	"time"
+)
+
+// TestQueueSlots тестирует очереди в режиме записи на слоты
+func TestQueueSlots(t *testing.T) {
+	helper := NewTestHelper()
+
+	// Создаем админа и пользователей
+	helper.createTestUser(t, "slotadmin", "password123", "@slotadmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@slotadmin", "password123")
+
+	helper.createTestUser(t, "slotuser1", "password123", "@slotuser1", "ИУ7-12Б")
+	user1Token := helper.loginUser(t, "@slotuser1", "password123")
+
+	helper.createTestUser(t, "slotuser2", "password123", "@slotuser2", "ИУ7-12Б")
+	user2Token := helper.loginUser(t, "@slotuser2", "password123")
+
+	// Создаем очередь на час с 10-минутными слотами
+	// Время без долей секунды, чтобы при изменении очереди начало совпадало с сохраненным в базе
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	queueData := models.CreateQueueRequest{
+		Title:        "Slots Test Queue",
+		TimeStart:    start,
+		TimeEnd:      start.Add(time.Hour),
+		Mode:         models.QueueModeSlots,
+		SlotDuration: 10,
+	}
+	resp, err := helper.makeRequest("POST", baseURL+"/api/queues", queueData, adminToken)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var created map[string]interface{}
+	if err := helper.parseResponse(resp, &created); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Failed to create slot queue, status: %d", resp.StatusCode)
+	}
+	queueID := int(created["id"].(float64))
+
+	t.Run("CreateSlotQueue_WithoutDuration", func(t *testing.T) {
+		invalid := queueData
+		invalid.SlotDuration = 0
+
+		resp, err := helper.makeRequest("POST", baseURL+"/api/queues", invalid, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected error for slot queue without slot duration, got success")
+		}
+	})
+
+	t.Run("GetSlots", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d/slots", baseURL, queueID), nil, user1Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var result struct {
+			Slots []models.QueueSlot `json:"slots"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if len(result.Slots) != 6 {
+			t.Errorf("Expected 6 slots, got %d", len(result.Slots))
+		}
+	})
+
+	t.Run("JoinSlotQueue", func(t *testing.T) {
+		joinData := models.JoinQueueRequest{QueueID: queueID}
+
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/join", baseURL, queueID), joinData, user1Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected error when joining slot queue as FIFO, got success")
+		}
+	})
+
+	t.Run("BookSlot", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/booking", baseURL, queueID), models.BookSlotRequest{Slot: 2}, user1Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("BookSlot_AlreadyBooked", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/booking", baseURL, queueID), models.BookSlotRequest{Slot: 2}, user2Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected error for already booked slot, got success")
+		}
+	})
+
+	t.Run("BookSlot_OutOfRange", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/booking", baseURL, queueID), models.BookSlotRequest{Slot: 7}, user2Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("RescheduleSlot", func(t *testing.T) {
+		resp, err := helper.makeRequest("PUT", fmt.Sprintf("%s/api/queues/%d/booking", baseURL, queueID), models.BookSlotRequest{Slot: 4}, user1Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UpdateQueue_ScheduleLockedByBookings", func(t *testing.T) {
+		update := func(change func(queue *models.Queue)) int {
+			queue := models.Queue{Title: queueData.Title, TimeStart: start, TimeEnd: start.Add(time.Hour), Mode: models.QueueModeSlots, SlotDuration: 10}
+			change(&queue)
+			resp, err := helper.makeRequest("PUT", fmt.Sprintf("%s/api/queues/%d", baseURL, queueID), queue, adminToken)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		// Запись на слот 4 не дает сдвинуть начало, изменить длину слота или убрать ее слот из расписания
+		if status := update(func(queue *models.Queue) { queue.TimeStart = start.Add(5 * time.Minute) }); status != http.StatusConflict {
+			t.Errorf("Expected status 409 when moving queue start, got %d", status)
+		}
+		if status := update(func(queue *models.Queue) { queue.SlotDuration = 15 }); status != http.StatusConflict {
+			t.Errorf("Expected status 409 when changing slot duration, got %d", status)
+		}
+		if status := update(func(queue *models.Queue) { queue.TimeEnd = start.Add(30 * time.Minute) }); status != http.StatusConflict {
+			t.Errorf("Expected status 409 when removing booked slot, got %d", status)
+		}
+		if status := update(func(queue *models.Queue) { queue.TimeEnd = start.Add(2 * time.Hour) }); status != http.StatusOK {
+			t.Errorf("Expected status 200 when extending queue, got %d", status)
+		}
+		if status := update(func(queue *models.Queue) {}); status != http.StatusOK {
+			t.Errorf("Expected status 200 when restoring queue end, got %d", status)
+		}
+	})
+
+	t.Run("GetTimetable_Admin", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d/timetable", baseURL, queueID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var result struct {
+			Timetable []models.QueueSlot `json:"timetable"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if len(result.Timetable) != 6 || result.Timetable[3].TgNick != "@slotuser1" {
+			t.Error("Timetable should show the booking in slot 4")
+		}
+	})
+
+	t.Run("GetTimetable_RegularUser", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d/timetable", baseURL, queueID), nil, user2Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for non-admin user, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CancelSlot", func(t *testing.T) {
+		resp, err := helper.makeRequest("DELETE", fmt.Sprintf("%s/api/queues/%d/booking", baseURL, queueID), nil, user1Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CancelSlot_NoBooking", func(t *testing.T) {
+		resp, err := helper.makeRequest("DELETE", fmt.Sprintf("%s/api/queues/%d/booking", baseURL, queueID), nil, user1Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected error for cancelling a missing booking, got success")
+		}
+	})
+
+	t.Run("ClosedQueue_BookingFrozen", func(t *testing.T) {
+		booking := fmt.Sprintf("%s/api/queues/%d/booking", baseURL, queueID)
+		resp, err := helper.makeRequest("POST", booking, models.BookSlotRequest{Slot: 5}, user2Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Failed to book slot, status: %d", resp.StatusCode)
+		}
+
+		resp, err = helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/close", baseURL, queueID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Failed to close queue, status: %d", resp.StatusCode)
+		}
+
+		// После закрытия очереди запись нельзя ни перенести, ни отменить
+		resp, err = helper.makeRequest("PUT", booking, models.BookSlotRequest{Slot: 6}, user2Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected error for rescheduling in closed queue, got success")
+		}
+
+		resp, err = helper.makeRequest("DELETE", booking, nil, user2Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected error for cancelling in closed queue, got success")
+		}
+	})
+}
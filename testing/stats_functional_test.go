@@ -0,0 +1,113 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"sso/models"
+	"testing"
+)
+
+// TestQueueStats тестирует статистику участия в очередях
+func TestQueueStats(t *testing.T) {
+	helper := NewTestHelper()
+
+	// Создаем админа и пользователей
+	helper.createTestUser(t, "statsadmin", "password123", "@statsadmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@statsadmin", "password123")
+
+	helper.createTestUser(t, "statsuser1", "password123", "@statsuser1", "ИУ7-12Б")
+	user1Token := helper.loginUser(t, "@statsuser1", "password123")
+
+	helper.createTestUser(t, "statsuser2", "password123", "@statsuser2", "ИУ7-12Б")
+	user2Token := helper.loginUser(t, "@statsuser2", "password123")
+
+	queueID := helper.createTestQueue(t, adminToken, "Stats Test Queue")
+
+	// Оба пользователя встают в очередь: первый принят, второй не явился
+	for _, token := range []string{user1Token, user2Token} {
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/join", baseURL, queueID), models.JoinQueueRequest{QueueID: queueID}, token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+	}
+	for _, outcome := range []string{"served", "no_show"} {
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/shift?outcome=%s", baseURL, queueID, outcome), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	t.Run("GetQueueStats_Admin", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d/stats", baseURL, queueID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var result struct {
+			Stats models.StatsReport `json:"stats"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if result.Stats.Total.Joined != 2 || result.Stats.Total.Served != 1 || result.Stats.Total.NoShow != 1 {
+			t.Errorf("Unexpected totals: %+v", result.Stats.Total)
+		}
+		if result.Stats.Total.NoShowRate != 0.5 {
+			t.Errorf("Expected no-show rate 0.5, got %v", result.Stats.Total.NoShowRate)
+		}
+	})
+
+	t.Run("ShiftQueue_InvalidOutcome", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/shift?outcome=unknown", baseURL, queueID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetAdminStats_DateRange", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", baseURL+"/api/admin/stats?from=2000-01-01&to=2100-01-01", nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetAdminStats_InvalidDate", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", baseURL+"/api/admin/stats?from=yesterday", nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetStats_RegularUser", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d/stats", baseURL, queueID), nil, user1Token)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for non-admin user, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"sso/models"
+	"sso/pkg/services"
+	"sso/pkg/telegram"
+	"testing"
+	"time"
+)
+
+func (r *fakeUserRepository) GetUserByTelegramID(tgUserID int64) (models.User, error) {
+	userID, ok := r.telegramIDs[tgUserID]
+	if !ok {
+		return models.User{}, sql.ErrNoRows
+	}
+	return r.GetUserByID(userID)
+}
+
+func (r *fakeUserRepository) LinkTelegramUser(userID int, tgUserID int64) error {
+	for _, linked := range r.telegramIDs {
+		if linked == userID {
+			return errors.New("user already has another telegram account")
+		}
+	}
+	r.telegramIDs[tgUserID] = userID
+	return nil
+}
+
+// TestTelegramLoginLinking тестирует, что вход через Telegram не подключается к чужому аккаунту с паролем
+func TestTelegramLoginLinking(t *testing.T) {
+	const botToken = "123456:test-bot-token"
+	repo := newFakeUserRepository(
+		models.User{ID: 1, Username: "squatter", TgNick: "@ivan_tg", PasswordHash: "hash"},
+		models.User{ID: 2, Username: "imported", TgNick: "@petr_tg"},
+		models.User{ID: 3, Username: "owner", TgNick: "@ivan", PasswordHash: "hash"},
+		models.User{ID: 4, Username: "imported", TgNick: "@sidor_tg"},
+	)
+	cfg := models.Config{Telegram: models.TelegramConfig{BotToken: botToken, LoginMaxAge: time.Hour}}
+	service := services.NewAuthService(repo, cfg, nil).ForTenant(1)
+
+	// loginAt возвращает подписанные так же, как это делает Telegram, данные виджета
+	loginAt := func(id int64, username string, authDate time.Time) models.TelegramLoginRequest {
+		request := models.TelegramLoginRequest{ID: id, FirstName: "Иван", Username: username, AuthDate: authDate.Unix()}
+		secret := sha256.Sum256([]byte(botToken))
+		mac := hmac.New(sha256.New, secret[:])
+		mac.Write([]byte(telegram.DataCheckString(request.CheckFields())))
+		request.Hash = hex.EncodeToString(mac.Sum(nil))
+		return request
+	}
+	login := func(id int64, username string) models.TelegramLoginRequest {
+		return loginAt(id, username, time.Now())
+	}
+
+	t.Run("SignIn_AccountWithPasswordNotLinked", func(t *testing.T) {
+		if _, err := service.SignInWithTelegram(login(777000, "ivan_tg"), models.ClientInfo{}); !errors.Is(err, services.ErrTelegramLinkRequired) {
+			t.Errorf("Expected link confirmation to be required, got %v", err)
+		}
+		if _, ok := repo.telegramIDs[777000]; ok {
+			t.Error("Expected telegram account not to be linked to the squatter")
+		}
+	})
+
+	t.Run("SignIn_AccountWithoutPasswordLinked", func(t *testing.T) {
+		result, err := service.SignInWithTelegram(login(777001, "petr_tg"), models.ClientInfo{})
+		if err != nil || result.Token == "" {
+			t.Fatalf("Expected imported account to sign in, got %v", err)
+		}
+		if repo.telegramIDs[777001] != 2 {
+			t.Errorf("Expected telegram account to be linked to user 2, got %v", repo.telegramIDs)
+		}
+	})
+
+	t.Run("SignIn_NickCaseInsensitive", func(t *testing.T) {
+		if _, err := service.SignInWithTelegram(login(777002, "Sidor_TG"), models.ClientInfo{}); err != nil {
+			t.Fatalf("Expected imported account to sign in regardless of nick case, got %v", err)
+		}
+		if repo.telegramIDs[777002] != 4 {
+			t.Errorf("Expected telegram account to be linked to user 4, got %v", repo.telegramIDs)
+		}
+	})
+
+	t.Run("SignIn_FutureAuthDate", func(t *testing.T) {
+		_, err := service.SignInWithTelegram(loginAt(777001, "petr_tg", time.Now().Add(time.Hour)), models.ClientInfo{})
+		if !errors.Is(err, telegram.ErrLoginFromFuture) {
+			t.Errorf("Expected auth_date in the future to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("LinkFromProfile", func(t *testing.T) {
+		if err := service.LinkTelegramLogin(3, login(777000, "ivan_tg")); err != nil {
+			t.Fatalf("Expected signed in user to link telegram, got %v", err)
+		}
+		result, err := service.SignInWithTelegram(login(777000, "ivan_tg"), models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Expected linked telegram to sign in, got %v", err)
+		}
+		identity, err := service.ParseToken(result.Token)
+		if err != nil || identity.UserID != 3 {
+			t.Errorf("Expected token of user 3, got %+v (%v)", identity, err)
+		}
+		if err := service.LinkTelegramLogin(1, login(777000, "ivan_tg")); err == nil {
+			t.Error("Expected telegram account linked to another user to be rejected")
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sso/models"
+	"sso/pkg/notifier"
+	"sso/pkg/telegram"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTelegramLink тестирует выдачу ссылки для привязки чата Telegram
+func TestTelegramLink(t *testing.T) {
+	helper := NewTestHelper()
+
+	helper.createTestUser(t, "telegramuser", "password123", "@telegramuser", "ИУ7-12Б")
+	userToken := helper.loginUser(t, "@telegramuser", "password123")
+
+	t.Run("CreateLink_Success", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", baseURL+"/api/profile/telegram", nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var result struct {
+			Telegram struct {
+				Code string `json:"code"`
+				URL  string `json:"url"`
+			} `json:"telegram"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if result.Telegram.Code == "" || !strings.HasSuffix(result.Telegram.URL, "?start="+result.Telegram.Code) {
+			t.Errorf("Expected t.me deep link with code, got %+v", result.Telegram)
+		}
+	})
+
+	t.Run("CreateLink_Unauthorized", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", baseURL+"/api/profile/telegram", nil, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UnlinkChat", func(t *testing.T) {
+		resp, err := helper.makeRequest("DELETE", baseURL+"/api/profile/telegram", nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// TestTelegramClient тестирует клиент Bot API на локальном поддельном сервере
+func TestTelegramClient(t *testing.T) {
+	var sent map[string]interface{}
+	fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bottest-token/sendMessage":
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+		case "/bottest-token/getUpdates":
+			w.Write([]byte(`{"ok":true,"result":[{"update_id":7,"message":{"message_id":2,"chat":{"id":42},"text":"/start abc"}}]}`))
+		default:
+			w.Write([]byte(`{"ok":false,"description":"Not Found"}`))
+		}
+	}))
+	defer fake.Close()
+
+	client := telegram.NewClient(fake.URL, "test-token")
+
+	t.Run("SendMessage", func(t *testing.T) {
+		if err := client.SendMessage(42, "Вас вызывают"); err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		if sent["chat_id"] != float64(42) || sent["text"] != "Вас вызывают" {
+			t.Errorf("Unexpected sendMessage payload: %v", sent)
+		}
+	})
+
+	t.Run("GetUpdates", func(t *testing.T) {
+		updates, err := client.GetUpdates(context.Background(), 0, time.Second)
+		if err != nil {
+			t.Fatalf("Failed to get updates: %v", err)
+		}
+		if len(updates) != 1 || updates[0].Message == nil || updates[0].Message.Chat.ID != 42 {
+			t.Errorf("Unexpected updates: %+v", updates)
+		}
+	})
+
+	t.Run("APIError", func(t *testing.T) {
+		err := telegram.NewClient(fake.URL, "wrong-token").SendMessage(42, "text")
+		if err == nil || !strings.Contains(err.Error(), "Not Found") {
+			t.Errorf("Expected API error, got %v", err)
+		}
+	})
+}
+
+// fakeChatStore сопоставляет пользователям чаты Telegram
+type fakeChatStore map[int]int64
+
+func (s fakeChatStore) GetTelegramChatID(userID int) (int64, error) {
+	return s[userID], nil
+}
+
+// TestTelegramNotifierQueueCancelled тестирует, что об отмене очереди каждый пользователь уведомляется своим сообщением
+// и сбой отправки одному пользователю повторяет только его сообщение
+func TestTelegramNotifierQueueCancelled(t *testing.T) {
+	var delivered []float64
+	fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var sent map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&sent)
+		if sent["chat_id"] == float64(20) {
+			w.Write([]byte(`{"ok":false,"description":"Too Many Requests: retry after 5"}`))
+			return
+		}
+		delivered = append(delivered, sent["chat_id"].(float64))
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer fake.Close()
+
+	chats := fakeChatStore{1: 10, 2: 20, 3: 30}
+	sink := notifier.NewTelegram(telegram.NewClient(fake.URL, "test-token"), func(int) notifier.ChatStore { return chats })
+
+	// Пользователь 4 не привязал чат, его сообщение считается доставленным
+	for userID, expectRetry := range map[int]bool{1: false, 2: true, 3: false, 4: false} {
+		payload, _ := json.Marshal(models.OutboxPayload{QueueTitle: "Лабораторная", UserID: userID})
+		err := sink.Deliver(context.Background(), models.OutboxMessage{ID: int64(userID), EventType: models.OutboxQueueCancelled, Payload: payload})
+		if (err != nil) != expectRetry {
+			t.Errorf("User %d: expected retry %v, got %v", userID, expectRetry, err)
+		}
+	}
+	if len(delivered) != 2 {
+		t.Errorf("Expected users 1 and 3 to be notified once, got chats %v", delivered)
+	}
+}
+
+// TestTelegramLogin тестирует проверку данных Telegram Login Widget
+func TestTelegramLogin(t *testing.T) {
+	helper := NewTestHelper()
+	const botToken = "123456:test-bot-token"
+	now := time.Now()
+
+	// sign подписывает данные так же, как это делает Telegram
+	sign := func(request models.TelegramLoginRequest, token string) string {
+		secret := sha256.Sum256([]byte(token))
+		mac := hmac.New(sha256.New, secret[:])
+		mac.Write([]byte(telegram.DataCheckString(request.CheckFields())))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	request := models.TelegramLoginRequest{
+		ID:        777000,
+		FirstName: "Иван",
+		Username:  "ivan_tg",
+		AuthDate:  now.Add(-time.Minute).Unix(),
+	}
+
+	t.Run("DataCheckString_SortedWithoutEmpty", func(t *testing.T) {
+		expected := "auth_date=" + fmt.Sprint(request.AuthDate) + "\nfirst_name=Иван\nid=777000\nusername=ivan_tg"
+		if got := telegram.DataCheckString(request.CheckFields()); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("VerifyLogin_Valid", func(t *testing.T) {
+		err := telegram.VerifyLogin(botToken, request.CheckFields(), sign(request, botToken), time.Unix(request.AuthDate, 0), now, 24*time.Hour)
+		if err != nil {
+			t.Errorf("Expected valid signature, got %v", err)
+		}
+	})
+
+	t.Run("VerifyLogin_ForeignBot", func(t *testing.T) {
+		err := telegram.VerifyLogin(botToken, request.CheckFields(), sign(request, "other:token"), time.Unix(request.AuthDate, 0), now, 24*time.Hour)
+		if err != telegram.ErrLoginSignature {
+			t.Errorf("Expected signature error, got %v", err)
+		}
+	})
+
+	t.Run("VerifyLogin_TamperedData", func(t *testing.T) {
+		hash := sign(request, botToken)
+		tampered := request
+		tampered.Username = "someone_else"
+		err := telegram.VerifyLogin(botToken, tampered.CheckFields(), hash, time.Unix(tampered.AuthDate, 0), now, 24*time.Hour)
+		if err != telegram.ErrLoginSignature {
+			t.Errorf("Expected signature error, got %v", err)
+		}
+	})
+
+	t.Run("VerifyLogin_Expired", func(t *testing.T) {
+		old := request
+		old.AuthDate = now.Add(-48 * time.Hour).Unix()
+		err := telegram.VerifyLogin(botToken, old.CheckFields(), sign(old, botToken), time.Unix(old.AuthDate, 0), now, 24*time.Hour)
+		if err != telegram.ErrLoginExpired {
+			t.Errorf("Expected expiration error, got %v", err)
+		}
+	})
+
+	t.Run("VerifyLogin_ZeroMaxAgeUsesDefault", func(t *testing.T) {
+		old := request
+		old.AuthDate = now.Add(-telegram.DefaultLoginMaxAge - time.Hour).Unix()
+		err := telegram.VerifyLogin(botToken, old.CheckFields(), sign(old, botToken), time.Unix(old.AuthDate, 0), now, 0)
+		if err != telegram.ErrLoginExpired {
+			t.Errorf("Expected expiration error, got %v", err)
+		}
+	})
+
+	t.Run("VerifyLogin_FutureAuthDate", func(t *testing.T) {
+		future := request
+		future.AuthDate = now.Add(time.Hour).Unix()
+		err := telegram.VerifyLogin(botToken, future.CheckFields(), sign(future, botToken), time.Unix(future.AuthDate, 0), now, 24*time.Hour)
+		if err != telegram.ErrLoginFromFuture {
+			t.Errorf("Expected future auth_date error, got %v", err)
+		}
+	})
+
+	t.Run("SignIn_InvalidHash", func(t *testing.T) {
+		invalid := request
+		invalid.Hash = "deadbeef"
+		resp, err := helper.makeRequest("POST", baseURL+"/auth/telegram", invalid, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			t.Errorf("Expected sign-in with invalid hash to be rejected, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("SignIn_MissingFields", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", baseURL+"/auth/telegram", map[string]string{"username": "ivan_tg"}, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+}
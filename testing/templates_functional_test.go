@@ -0,0 +1,202 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"sso/models"
+	"testing"
+	"time"
+)
+
+// TestQueueTemplatesAndClone тестирует шаблоны очередей и клонирование
+func TestQueueTemplatesAndClone(t *testing.T) {
+	helper := NewTestHelper()
+
+	// Создаем админа и обычного пользователя
+	helper.createTestUser(t, "templateadmin", "password123", "@templateadmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@templateadmin", "password123")
+
+	helper.createTestUser(t, "templateuser", "password123", "@templateuser", "ИУ7-12Б")
+	userToken := helper.loginUser(t, "@templateuser", "password123")
+
+	queueID := helper.createTestQueue(t, adminToken, "Clone Source Queue")
+	start := time.Now().Add(24 * time.Hour)
+
+	t.Run("CloneQueue_Once", func(t *testing.T) {
+		cloneData := models.ScheduleRequest{
+			TimeStart: start,
+			TimeEnd:   start.Add(time.Hour),
+		}
+
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/clone", baseURL, queueID), cloneData, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var result struct {
+			IDs []int `json:"ids"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if len(result.IDs) != 1 {
+			t.Errorf("Expected 1 cloned queue, got %d", len(result.IDs))
+		}
+	})
+
+	t.Run("CloneQueue_Semester", func(t *testing.T) {
+		cloneData := models.ScheduleRequest{
+			TimeStart:  start,
+			Title:      "Консультация {n} ({date})",
+			RepeatDays: 7,
+			Until:      start.AddDate(0, 0, 7*15),
+		}
+
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/clone", baseURL, queueID), cloneData, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var result struct {
+			IDs []int `json:"ids"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if len(result.IDs) != 16 {
+			t.Errorf("Expected 16 cloned queues, got %d", len(result.IDs))
+		}
+	})
+
+	t.Run("CloneQueue_RegularUser", func(t *testing.T) {
+		cloneData := models.ScheduleRequest{TimeStart: start}
+
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/clone", baseURL, queueID), cloneData, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for non-admin user, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CreateTemplateAndQueues", func(t *testing.T) {
+		templateData := models.CreateQueueTemplateRequest{
+			Title:    "Защита {date}",
+			Duration: 90,
+			Capacity: 20,
+			Desks:    2,
+		}
+
+		resp, err := helper.makeRequest("POST", baseURL+"/api/queue-templates", templateData, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var created map[string]interface{}
+		if err := helper.parseResponse(resp, &created); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		templateID := int(created["id"].(float64))
+
+		scheduleData := models.ScheduleRequest{
+			TimeStart:  start,
+			RepeatDays: 14,
+			Until:      start.AddDate(0, 0, 28),
+		}
+		resp, err = helper.makeRequest("POST", fmt.Sprintf("%s/api/queue-templates/%d/queues", baseURL, templateID), scheduleData, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var result struct {
+			IDs []int `json:"ids"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if len(result.IDs) != 3 {
+			t.Errorf("Expected 3 queues from template, got %d", len(result.IDs))
+		}
+	})
+
+	t.Run("CreateTemplate_OrgUnits", func(t *testing.T) {
+		unit := models.OrgUnitRequest{Kind: models.OrgUnitFaculty, Code: fmt.Sprintf("T%d", time.Now().UnixNano())}
+		resp, err := helper.makeRequest("POST", baseURL+"/api/org-units", unit, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var createdUnit map[string]interface{}
+		if err := helper.parseResponse(resp, &createdUnit); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("Failed to create org unit, status: %d", resp.StatusCode)
+		}
+		unitID := int64(createdUnit["id"].(float64))
+
+		templateData := models.CreateQueueTemplateRequest{Title: "Экзамен факультета", Duration: 60, AllowedUnits: []int64{unitID}}
+		resp, err = helper.makeRequest("POST", baseURL+"/api/queue-templates", templateData, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var created map[string]interface{}
+		if err := helper.parseResponse(resp, &created); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("Failed to create template, status: %d", resp.StatusCode)
+		}
+		templateID := int(created["id"].(float64))
+
+		resp, err = helper.makeRequest("POST", fmt.Sprintf("%s/api/queue-templates/%d/queues", baseURL, templateID), models.ScheduleRequest{TimeStart: start}, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var result struct {
+			IDs []int `json:"ids"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil || len(result.IDs) != 1 {
+			t.Fatalf("Failed to create queue from template, status: %d", resp.StatusCode)
+		}
+
+		// Очередь по шаблону получает те же факультеты и кафедры, что и шаблон
+		resp, err = helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d", baseURL, result.IDs[0]), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var queue struct {
+			Queue models.Queue `json:"queue"`
+		}
+		if err := helper.parseResponse(resp, &queue); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if len(queue.Queue.AllowedUnits) != 1 || queue.Queue.AllowedUnits[0] != unitID {
+			t.Errorf("Expected queue to inherit org units of the template, got %v", queue.Queue.AllowedUnits)
+		}
+	})
+
+	t.Run("CreateTemplate_RegularUser", func(t *testing.T) {
+		templateData := models.CreateQueueTemplateRequest{Title: "Template", Duration: 60}
+
+		resp, err := helper.makeRequest("POST", baseURL+"/api/queue-templates", templateData, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for non-admin user, got %d", resp.StatusCode)
+		}
+	})
+}
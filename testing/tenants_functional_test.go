@@ -0,0 +1,163 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sso/models"
+	"testing"
+)
+
+// makeTenantRequest выполняет HTTP запрос от имени арендатора из заголовка X-Tenant
+func (h *TestHelper) makeTenantRequest(method, url, tenant string, body interface{}, token string) (*http.Response, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant", tenant)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return h.client.Do(req)
+}
+
+// TestTenantIsolation тестирует, что пользователи, очереди и группы одного арендатора недоступны другому
+func TestTenantIsolation(t *testing.T) {
+	helper := NewTestHelper()
+
+	// Создаем админа основного арендатора
+	helper.createTestUser(t, "tenantadmin", "password123", "@tenantadmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@tenantadmin", "password123")
+
+	tenantData := models.CreateTenantRequest{
+		Code:  "tenant-test",
+		Title: "Test university",
+		Admin: models.RegisterUser{Username: "tenantrector", Password: "password123", TgNick: "@tenantrector"},
+	}
+	resp, err := helper.makeRequest("POST", baseURL+"/api/admin/tenants", tenantData, adminToken)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for tenant creation, got %d", resp.StatusCode)
+	}
+
+	// Администратор нового арендатора входит с заголовком X-Tenant
+	credentials := models.AuthUser{TgNick: "@tenantrector", Password: "password123"}
+	resp, err = helper.makeTenantRequest("POST", baseURL+"/auth/sign-in", "tenant-test", credentials, "")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var signIn map[string]interface{}
+	if err := helper.parseResponse(resp, &signIn); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	rectorToken, ok := signIn["token"].(string)
+	if !ok {
+		t.Fatalf("Expected token for tenant admin, got %v", signIn)
+	}
+
+	t.Run("SignIn_UnknownTenant", func(t *testing.T) {
+		resp, err := helper.makeTenantRequest("POST", baseURL+"/auth/sign-in", "no-such-tenant", credentials, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404 for unknown tenant, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("SignIn_OtherTenant", func(t *testing.T) {
+		resp, err := helper.makeRequest("POST", baseURL+"/auth/sign-in", credentials, "")
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected user of another tenant not to sign in, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ManageTenants_OtherTenantAdmin", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", baseURL+"/api/admin/tenants", nil, rectorToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for admin of another tenant, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Users_Isolated", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", baseURL+"/api/admin/users", nil, rectorToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var result struct {
+			Users []models.User `json:"users"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if len(result.Users) != 1 || result.Users[0].TgNick != "@tenantrector" {
+			t.Errorf("Expected only users of the new tenant, got %+v", result.Users)
+		}
+	})
+
+	t.Run("Queues_Isolated", func(t *testing.T) {
+		queueID := helper.createTestQueue(t, rectorToken, "Очередь другого арендатора")
+
+		// Заголовок X-Tenant не меняет арендатора токена
+		resp, err := helper.makeTenantRequest("GET", fmt.Sprintf("%s/api/queues/%d", baseURL, queueID), "tenant-test", nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected queue of another tenant not to be found")
+		}
+
+		resp, err = helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/join", baseURL, queueID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected user not to join queue of another tenant")
+		}
+
+		resp, err = helper.makeRequest("GET", fmt.Sprintf("%s/api/queues/%d", baseURL, queueID), nil, rectorToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected queue to be found in its tenant, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Groups_Isolated", func(t *testing.T) {
+		groupID := helper.createTestGroup(t, adminToken, "ИУ7-19Б", "Group of the default tenant")
+
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/groups/%d", baseURL, groupID), nil, rectorToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected group of another tenant not to be found")
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"sso/models"
+	"sso/pkg/repository"
+	"sso/pkg/services"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ForTenant возвращает представление хранилища, в котором видны только пользователи арендатора
+func (r *fakeUserRepository) ForTenant(tenantID int) repository.Repository {
+	view := *r
+	view.tenantID = tenantID
+	return &view
+}
+
+func (r *fakeUserRepository) GetTenantByCode(code string) (models.Tenant, error) {
+	tenant, ok := r.tenants[code]
+	if !ok {
+		return models.Tenant{}, sql.ErrNoRows
+	}
+	return tenant, nil
+}
+
+func (r *fakeUserRepository) GetTenants() ([]models.Tenant, error) {
+	var tenants []models.Tenant
+	for _, tenant := range r.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return tenants, nil
+}
+
+func (r *fakeUserRepository) CreateTenant(tenant models.Tenant, admin models.RegisterUser) (int, error) {
+	if _, ok := r.tenants[tenant.Code]; ok {
+		return 0, errors.New("tenant already exists")
+	}
+	tenant.ID = len(r.tenants) + 1
+	r.tenants[tenant.Code] = tenant
+	r.users[admin.TgNick] = models.User{ID: 100 + tenant.ID, TenantID: tenant.ID, Username: admin.Username,
+		TgNick: admin.TgNick, PasswordHash: admin.Password, IsAdmin: true, Status: models.UserStatusActive}
+	return tenant.ID, nil
+}
+
+func (r *fakeUserRepository) GetAllUsers() ([]models.User, error) {
+	var users []models.User
+	for _, user := range r.users {
+		if user.TenantID == r.tenantID {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// TestTenants тестирует арендатора в JWT токене, изоляцию данных арендаторов и управление арендаторами
+func TestTenants(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	repo := newFakeUserRepository(
+		models.User{ID: 1, Username: "admin", TgNick: "@admin", PasswordHash: string(hash), IsAdmin: true},
+		models.User{ID: 2, Username: "student", TgNick: "@student", PasswordHash: string(hash)},
+		models.User{ID: 3, TenantID: 2, Username: "guest", TgNick: "@guest", PasswordHash: string(hash), IsAdmin: true},
+	)
+	repo.tenants["msu"] = models.Tenant{ID: 2, Code: "msu"}
+	root := services.NewAuthService(repo, models.Config{}, nil)
+	defaultTenant, otherTenant := root.ForTenant(1), root.ForTenant(2)
+	credentials := func(tgNick string) models.AuthUser {
+		return models.AuthUser{TgNick: tgNick, Password: "password123"}
+	}
+
+	t.Run("TokenCarriesTenant", func(t *testing.T) {
+		result, err := otherTenant.SignIn(credentials("@guest"), models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Failed to sign in: %v", err)
+		}
+		identity, err := root.ParseToken(result.Token)
+		if err != nil {
+			t.Fatalf("Expected valid token, got %v", err)
+		}
+		if identity.TenantID != 2 || identity.UserID != 3 {
+			t.Errorf("Expected user 3 of tenant 2, got %+v", identity)
+		}
+	})
+
+	t.Run("UsersIsolated", func(t *testing.T) {
+		if _, err := otherTenant.SignIn(credentials("@student"), models.ClientInfo{}); !errors.Is(err, services.ErrInvalidCredentials) {
+			t.Errorf("Expected user of another tenant not to sign in, got %v", err)
+		}
+		users, err := otherTenant.GetAllUsers()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(users) != 1 || users[0].ID != 3 {
+			t.Errorf("Expected only users of tenant 2, got %+v", users)
+		}
+		if _, err := otherTenant.CreatePasswordReset(2, 3); err == nil || err.Error() != "user not found" {
+			t.Errorf("Expected user of another tenant not to be found, got %v", err)
+		}
+	})
+
+	t.Run("SessionsIsolated", func(t *testing.T) {
+		result, err := defaultTenant.SignIn(credentials("@student"), models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Failed to sign in: %v", err)
+		}
+		identity, err := root.ParseToken(result.Token)
+		if err != nil {
+			t.Fatalf("Expected valid token, got %v", err)
+		}
+		if err := otherTenant.RevokeSession(2, identity.SessionID); err == nil {
+			t.Error("Expected session of another tenant not to be revoked")
+		}
+		if _, err := root.ParseToken(result.Token); err != nil {
+			t.Errorf("Expected session to stay active, got %v", err)
+		}
+	})
+
+	t.Run("ManageTenants", func(t *testing.T) {
+		if _, err := otherTenant.GetTenants(); !errors.Is(err, services.ErrTenantAdminRequired) {
+			t.Errorf("Expected admin of another tenant to be rejected, got %v", err)
+		}
+
+		input := models.CreateTenantRequest{Code: "bmstu", Title: "МГТУ",
+			Admin: models.RegisterUser{Username: "rector", TgNick: "@rector", Password: "password123"}}
+		id, err := defaultTenant.CreateTenant(input)
+		if err != nil {
+			t.Fatalf("Expected default tenant admin to create tenant, got %v", err)
+		}
+		tenant, err := root.GetTenantByCode("bmstu")
+		if err != nil || tenant.ID != id {
+			t.Fatalf("Expected tenant %d to be found by code, got %+v (%v)", id, tenant, err)
+		}
+
+		result, err := root.ForTenant(id).SignIn(credentials("@rector"), models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Expected tenant admin to sign in, got %v", err)
+		}
+		identity, err := root.ParseToken(result.Token)
+		if err != nil || identity.TenantID != id || !identity.IsAdmin {
+			t.Errorf("Expected admin token of tenant %d, got %+v (%v)", id, identity, err)
+		}
+	})
+}
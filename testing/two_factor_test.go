@@ -0,0 +1,193 @@
+package test
+
+import (
+	"errors"
+	"net/url"
+	"sso/models"
+	"sso/pkg/services"
+	"sso/pkg/totp"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// GetUserByTelegramChat в тестах считает ID чата равным ID пользователя
+func (r *fakeUserRepository) GetUserByTelegramChat(chatID int64) (models.User, error) {
+	return r.GetUserByID(int(chatID))
+}
+
+// TestTOTP тестирует вычисление и проверку кодов по тестовым векторам RFC 6238
+func TestTOTP(t *testing.T) {
+	// Секрет "12345678901234567890" из приложения B RFC 6238 в base32
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range vectors {
+		code, err := totp.Code(secret, totp.Step(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if code != expected {
+			t.Errorf("Time %d: expected %s, got %s", unix, expected, code)
+		}
+	}
+
+	t.Run("Validate_ClockSkew", func(t *testing.T) {
+		now := time.Unix(1111111109, 0)
+		previous, _ := totp.Code(secret, totp.Step(now)-1)
+		if step, ok := totp.Validate(secret, previous, now, 1); !ok || step != totp.Step(now)-1 {
+			t.Errorf("Expected code of previous period to be accepted")
+		}
+		old, _ := totp.Code(secret, totp.Step(now)-2)
+		if _, ok := totp.Validate(secret, old, now, 1); ok {
+			t.Errorf("Expected code outside of skew window to be rejected")
+		}
+	})
+
+	t.Run("URI", func(t *testing.T) {
+		uri, err := url.Parse(totp.URI("SSO Queues", "@student", secret))
+		if err != nil {
+			t.Fatalf("Invalid URI: %v", err)
+		}
+		if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Query().Get("secret") != secret || uri.Query().Get("issuer") != "SSO Queues" {
+			t.Errorf("Unexpected otpauth URI: %s", uri)
+		}
+	})
+}
+
+// TestTwoFactorSignIn тестирует подключение TOTP и двухшаговый вход
+func TestTwoFactorSignIn(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	repo := newFakeUserRepository(
+		models.User{ID: 1, Username: "admin", TgNick: "@admin", PasswordHash: string(hash), IsAdmin: true},
+	)
+	cfg := models.Config{TwoFactor: models.TwoFactorConfig{RequiredForAdmins: true}}
+	service := services.NewAuthService(repo, cfg, nil).ForTenant(1)
+	credentials := models.AuthUser{TgNick: "@admin", Password: "password123"}
+
+	t.Run("SignIn_AdminWithoutTwoFactor", func(t *testing.T) {
+		result, err := service.SignIn(credentials, models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.TwoFactorSetupRequired {
+			t.Errorf("Expected two-factor setup to be required for admin")
+		}
+		if identity, err := service.ParseToken(result.Token); err != nil || identity.IsAdmin {
+			t.Errorf("Expected token without admin rights until 2FA is enabled, got admin=%v err=%v", identity.IsAdmin, err)
+		}
+	})
+
+	t.Run("Bot_AdminWithoutTwoFactor", func(t *testing.T) {
+		user, err := service.GetUserByTelegramChat(1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if user.IsAdmin {
+			t.Error("Expected admin without 2FA not to act as admin in the bot")
+		}
+	})
+
+	var recoveryCodes []string
+	var secret string
+	var confirmedStep int64
+
+	t.Run("Setup_AndConfirm", func(t *testing.T) {
+		setup, err := service.SetupTwoFactor(1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		secret = setup.Secret
+
+		if _, err := service.ConfirmTwoFactor(1, "000000"); !errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			t.Errorf("Expected wrong confirmation code to be rejected, got %v", err)
+		}
+
+		confirmedStep = totp.Step(time.Now())
+		code, _ := totp.Code(secret, confirmedStep)
+		recoveryCodes, err = service.ConfirmTwoFactor(1, code)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(recoveryCodes) != 10 {
+			t.Errorf("Expected 10 recovery codes, got %d", len(recoveryCodes))
+		}
+	})
+
+	t.Run("SignIn_RequiresSecondStep", func(t *testing.T) {
+		result, err := service.SignIn(credentials, models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.TwoFactorRequired || result.Token != "" || result.PreAuthToken == "" {
+			t.Fatalf("Expected pre-auth token only, got %+v", result)
+		}
+		if _, err := service.ParseToken(result.PreAuthToken); err == nil {
+			t.Errorf("Pre-auth token must not grant API access")
+		}
+
+		// Код, использованный при подтверждении, повторно не принимается
+		code, _ := totp.Code(secret, confirmedStep)
+		_, err = service.CompleteTwoFactorSignIn(models.TwoFactorSignInRequest{PreAuthToken: result.PreAuthToken, Code: code}, models.ClientInfo{})
+		if !errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			t.Errorf("Expected reused code to be rejected, got %v", err)
+		}
+
+		token, err := service.CompleteTwoFactorSignIn(models.TwoFactorSignInRequest{PreAuthToken: result.PreAuthToken, Code: recoveryCodes[0]}, models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Expected recovery code to be accepted, got %v", err)
+		}
+		if identity, err := service.ParseToken(token); err != nil || !identity.IsAdmin {
+			t.Errorf("Expected admin token after second step, got admin=%v err=%v", identity.IsAdmin, err)
+		}
+
+		_, err = service.CompleteTwoFactorSignIn(models.TwoFactorSignInRequest{PreAuthToken: result.PreAuthToken, Code: recoveryCodes[0]}, models.ClientInfo{})
+		if !errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			t.Errorf("Expected used recovery code to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("CompleteSignIn_InvalidPreAuthToken", func(t *testing.T) {
+		token, _ := service.CompleteTwoFactorSignIn(models.TwoFactorSignInRequest{PreAuthToken: "invalid", Code: recoveryCodes[1]}, models.ClientInfo{})
+		if token != "" {
+			t.Errorf("Expected invalid pre-auth token to be rejected")
+		}
+	})
+
+	t.Run("RegenerateRecoveryCodes", func(t *testing.T) {
+		codes, err := service.RegenerateRecoveryCodes(1, recoveryCodes[1])
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := service.RegenerateRecoveryCodes(1, recoveryCodes[2]); !errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			t.Errorf("Expected previous recovery codes to be revoked, got %v", err)
+		}
+		recoveryCodes = codes
+	})
+
+	t.Run("Bot_AdminWithTwoFactor", func(t *testing.T) {
+		user, err := service.GetUserByTelegramChat(1)
+		if err != nil || !user.IsAdmin {
+			t.Errorf("Expected admin with 2FA to act as admin in the bot, got admin=%v err=%v", user.IsAdmin, err)
+		}
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		if err := service.DisableTwoFactor(1, recoveryCodes[0]); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		result, err := service.SignIn(credentials, models.ClientInfo{})
+		if err != nil || result.TwoFactorRequired || !result.TwoFactorSetupRequired {
+			t.Errorf("Expected single-step sign-in without admin rights after disabling, got %+v, %v", result, err)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"sso/models"
+	"sso/pkg/importer"
+	"sso/pkg/services"
+	"strings"
+	"testing"
+	"time"
+)
+
+func (r *fakeUserRepository) GetExistingTgNicks(tgNicks []string) ([]string, error) {
+	var existing []string
+	for stored := range r.users {
+		for _, nick := range tgNicks {
+			if strings.EqualFold(stored, nick) {
+				existing = append(existing, stored)
+				break
+			}
+		}
+	}
+	return existing, nil
+}
+
+func (r *fakeUserRepository) GetAllGroups() ([]models.Group, error) {
+	var groups []models.Group
+	for _, group := range r.groups {
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func (r *fakeUserRepository) ImportUsers(rows []models.ImportUserRow, createdBy int, expiresAt time.Time) ([]string, error) {
+	var created []string
+	for i := range rows {
+		if rows[i].Status != models.ImportStatusNew {
+			continue
+		}
+		known := false
+		for _, group := range r.groups {
+			known = known || group.Code == rows[i].Group
+		}
+		if !known {
+			r.groups[len(r.groups)+1] = models.Group{ID: len(r.groups) + 1, Code: rows[i].Group}
+			created = append(created, rows[i].Group)
+		}
+		rows[i].UserID = len(r.users) + 1
+		rows[i].Status = models.ImportStatusCreated
+		r.users[rows[i].TgNick] = models.User{ID: rows[i].UserID, TenantID: r.tenantID, Username: rows[i].Username, TgNick: rows[i].TgNick}
+	}
+	return created, nil
+}
+
+// TestReadUsersCSV тестирует чтение файла импорта
+func TestReadUsersCSV(t *testing.T) {
+	t.Run("SemicolonWithBOM", func(t *testing.T) {
+		data := "\xef\xbb\xbfGroup;Username;TG_Nick\nИУ7-12Б;Иван Петров;@ivan\n\n;;\nИУ7-13Б;Анна;anna\n"
+		rows, err := importer.ReadUsersCSV(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("Expected 2 rows, got %d", len(rows))
+		}
+		if rows[0].Username != "Иван Петров" || rows[0].Group != "ИУ7-12Б" || rows[0].Line != 2 {
+			t.Errorf("Unexpected first row: %+v", rows[0])
+		}
+		if rows[1].TgNick != "anna" || rows[1].Line != 5 {
+			t.Errorf("Unexpected second row: %+v", rows[1])
+		}
+	})
+
+	t.Run("MissingColumn", func(t *testing.T) {
+		if _, err := importer.ReadUsersCSV(strings.NewReader("username,group\nivan,ИУ7-12Б\n")); err == nil {
+			t.Error("Expected error for missing tg_nick column")
+		}
+	})
+}
+
+// TestImportUsers тестирует проверку строк, пробный запуск и импорт
+func TestImportUsers(t *testing.T) {
+	repo := newFakeUserRepository(models.User{ID: 1, Username: "admin", TgNick: "@admin"})
+	repo.groups[1] = models.Group{ID: 1, Code: "ИУ7-12Б"}
+	service := services.NewAuthService(repo, models.Config{}, nil)
+
+	t.Run("InvalidRows", func(t *testing.T) {
+		data := "username,tg_nick,group\nИван,@ivan,ИУ7-12Б\nИван 2,@IVAN,ИУ7-12Б\nБез группы,@nogroup,\nПлохой,@bad nick,ИУ7-12Б\n"
+		result, err := service.ImportUsers(1, strings.NewReader(data), false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Valid {
+			t.Fatal("Expected file to be invalid")
+		}
+		for i, status := range []string{models.ImportStatusNew, models.ImportStatusError, models.ImportStatusError, models.ImportStatusError} {
+			if result.Rows[i].Status != status {
+				t.Errorf("Line %d: expected %s, got %s (%s)", result.Rows[i].Line, status, result.Rows[i].Status, result.Rows[i].Error)
+			}
+		}
+		if _, ok := repo.users["@ivan"]; ok {
+			t.Error("Invalid file must not create users")
+		}
+	})
+
+	// Ник уже зарегистрированного админа указан в другом регистре
+	data := "username,tg_nick,group\nИван,ivan,ИУ7-12Б\nАнна,@anna,ИУ7-13Б\nАдмин,@Admin,ИУ7-12Б\n"
+
+	t.Run("DryRun", func(t *testing.T) {
+		result, err := service.ImportUsers(1, strings.NewReader(data), true)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.Valid || result.Created != 2 || result.Existing != 1 {
+			t.Errorf("Unexpected dry run result: %+v", result)
+		}
+		if len(result.GroupsCreated) != 1 || result.GroupsCreated[0] != "ИУ7-13Б" {
+			t.Errorf("Expected group ИУ7-13Б to be created, got %v", result.GroupsCreated)
+		}
+		if _, ok := repo.users["@ivan"]; ok {
+			t.Error("Dry run must not create users")
+		}
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		result, err := service.ImportUsers(1, strings.NewReader(data), false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Created != 2 || result.Existing != 1 {
+			t.Errorf("Unexpected import result: %+v", result)
+		}
+		for _, row := range result.Rows {
+			hasToken := row.ActivationToken != ""
+			if hasToken != (row.Status == models.ImportStatusCreated) {
+				t.Errorf("Line %d: activation token %q does not match status %s", row.Line, row.ActivationToken, row.Status)
+			}
+		}
+		if _, ok := repo.users["@ivan"]; !ok {
+			t.Error("Expected nick without @ to be normalized and imported")
+		}
+		if _, ok := repo.users["@Admin"]; ok {
+			t.Error("Expected existing nick in another case not to be imported again")
+		}
+	})
+}
@@ -0,0 +1,284 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sso/models"
+	"sso/pkg/webhook"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryWebhooks хранит подписки и журнал доставки в памяти для проверки получателя webhook
+type memoryWebhooks struct {
+	webhooks   []models.Webhook
+	deliveries []models.WebhookDelivery
+}
+
+func (m *memoryWebhooks) GetActiveWebhooks(eventType string) ([]models.Webhook, error) {
+	var active []models.Webhook
+	for _, hook := range m.webhooks {
+		for _, subscribed := range hook.EventTypes {
+			if subscribed == eventType {
+				active = append(active, hook)
+			}
+		}
+	}
+	return active, nil
+}
+
+func (m *memoryWebhooks) HasSuccessfulWebhookDelivery(webhookID int, messageID int64) (bool, error) {
+	for _, delivery := range m.deliveries {
+		if delivery.WebhookID == webhookID && delivery.OutboxMessageID == messageID && delivery.Success {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryWebhooks) AddWebhookDelivery(delivery models.WebhookDelivery) error {
+	m.deliveries = append(m.deliveries, delivery)
+	return nil
+}
+
+// TestWebhookSink тестирует подпись и доставку событий на webhook-подписки
+func TestWebhookSink(t *testing.T) {
+	const secret = "webhook-secret"
+	var mu sync.Mutex
+	received := make(map[string]int)
+	failing := true
+
+	// Подписки опрашиваются параллельно
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhook.SignatureHeader) != webhook.Sign(secret, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received[r.URL.Path]++
+		if r.URL.Path == "/flaky" && failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	store := &memoryWebhooks{webhooks: []models.Webhook{
+		{ID: 1, URL: receiver.URL + "/stable", Secret: secret, EventTypes: []string{models.OutboxParticipantServed}},
+		{ID: 2, URL: receiver.URL + "/flaky", Secret: secret, EventTypes: []string{models.OutboxParticipantServed}},
+		{ID: 3, URL: receiver.URL + "/other", Secret: secret, EventTypes: []string{models.OutboxQueueCreated}},
+	}}
+	// Подписки другого арендатора не должны получать событие
+	foreign := &memoryWebhooks{webhooks: []models.Webhook{
+		{ID: 4, URL: receiver.URL + "/foreign", Secret: secret, EventTypes: []string{models.OutboxParticipantServed}},
+	}}
+	stores := func(tenantID int) webhook.Store {
+		if tenantID == 1 {
+			return store
+		}
+		return foreign
+	}
+	// Тестовый получатель слушает loopback, поэтому внутренние адреса разрешены
+	sink := webhook.NewSink(stores, models.WebhookConfig{AllowPrivateNetworks: true})
+	message := models.OutboxMessage{
+		ID:        10,
+		TenantID:  1,
+		EventType: models.OutboxParticipantServed,
+		Payload:   json.RawMessage(`{"queue_id":1,"user_id":2}`),
+		CreatedAt: time.Now(),
+	}
+
+	t.Run("Accepts_SubscribableEvents", func(t *testing.T) {
+		if !sink.Accepts(models.OutboxQueueClosed) || sink.Accepts(models.OutboxParticipantUpcoming) {
+			t.Errorf("Unexpected set of accepted events")
+		}
+	})
+
+	t.Run("Deliver_FailedWebhookReturnsError", func(t *testing.T) {
+		if err := sink.Deliver(context.Background(), message); err == nil {
+			t.Fatalf("Expected error while one webhook is failing")
+		}
+		if received["/stable"] != 1 || received["/flaky"] != 1 || received["/other"] != 0 || received["/foreign"] != 0 {
+			t.Errorf("Unexpected deliveries: %v", received)
+		}
+		if len(store.deliveries) != 2 || !store.deliveries[1].StatusCode.Valid || store.deliveries[1].StatusCode.Int32 != http.StatusServiceUnavailable {
+			t.Errorf("Expected delivery log with failed attempt, got %+v", store.deliveries)
+		}
+	})
+
+	t.Run("Deliver_RetrySkipsDelivered", func(t *testing.T) {
+		failing = false
+		if err := sink.Deliver(context.Background(), message); err != nil {
+			t.Fatalf("Expected successful retry, got %v", err)
+		}
+		if received["/stable"] != 1 || received["/flaky"] != 2 {
+			t.Errorf("Expected only the failed webhook to be retried, got %v", received)
+		}
+	})
+
+	t.Run("Deliver_PrivateAddressRejected", func(t *testing.T) {
+		guarded := webhook.NewSink(stores, models.WebhookConfig{})
+		retried := message
+		retried.ID = 11
+		if err := guarded.Deliver(context.Background(), retried); err == nil {
+			t.Fatalf("Expected error for webhooks on loopback address")
+		}
+		if received["/stable"] != 1 || received["/flaky"] != 2 {
+			t.Errorf("Expected no requests to loopback receiver, got %v", received)
+		}
+	})
+
+	t.Run("ValidateURL", func(t *testing.T) {
+		for _, rawURL := range []string{"http://127.0.0.1/hook", "http://localhost:8080", "https://10.0.0.5", "http://[::1]/", "http://169.254.169.254/latest", "ftp://lms.example.com"} {
+			if webhook.ValidateURL(rawURL, false) == nil {
+				t.Errorf("Expected %s to be rejected", rawURL)
+			}
+		}
+		if err := webhook.ValidateURL("https://lms.example.com/hooks", false); err != nil {
+			t.Errorf("Expected public url to be accepted, got %v", err)
+		}
+		if err := webhook.ValidateURL("http://127.0.0.1/hook", true); err != nil {
+			t.Errorf("Expected loopback url to be accepted when private networks are allowed, got %v", err)
+		}
+	})
+}
+
+// TestWebhooks тестирует управление webhook-подписками и закрытие очереди
+func TestWebhooks(t *testing.T) {
+	helper := NewTestHelper()
+
+	helper.createTestUser(t, "webhookadmin", "password123", "@webhookadmin", "ИУ7-12Б")
+	adminToken := helper.loginUser(t, "@webhookadmin", "password123")
+
+	helper.createTestUser(t, "webhookuser", "password123", "@webhookuser", "ИУ7-12Б")
+	userToken := helper.loginUser(t, "@webhookuser", "password123")
+
+	var webhookID int
+
+	t.Run("CreateWebhook_Admin", func(t *testing.T) {
+		input := models.CreateWebhookRequest{
+			URL:        "https://lms.example.com/hooks/queues",
+			EventTypes: []string{models.OutboxParticipantServed, models.OutboxQueueClosed},
+		}
+		resp, err := helper.makeRequest("POST", baseURL+"/api/admin/webhooks", input, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var result struct {
+			ID     int    `json:"id"`
+			Secret string `json:"secret"`
+		}
+		if err := helper.parseResponse(resp, &result); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if result.ID == 0 || result.Secret == "" {
+			t.Errorf("Expected webhook id and generated secret, got %+v", result)
+		}
+		webhookID = result.ID
+	})
+
+	t.Run("CreateWebhook_InvalidInput", func(t *testing.T) {
+		inputs := []models.CreateWebhookRequest{
+			{URL: "ftp://lms.example.com", EventTypes: []string{models.OutboxQueueCreated}},
+			{URL: "https://lms.example.com", EventTypes: []string{"participant.teleported"}},
+			{URL: "http://127.0.0.1:8080/hooks", EventTypes: []string{models.OutboxQueueCreated}},
+		}
+		for _, input := range inputs {
+			resp, err := helper.makeRequest("POST", baseURL+"/api/admin/webhooks", input, adminToken)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Input %+v: expected status 400, got %d", input, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("GetWebhooks_SecretHidden", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", baseURL+"/api/admin/webhooks", nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || strings.Contains(string(body), "secret") {
+			t.Errorf("Expected list without secrets, got %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("GetDeliveries_Admin", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", fmt.Sprintf("%s/api/admin/webhooks/%d/deliveries", baseURL, webhookID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Webhooks_RegularUser", func(t *testing.T) {
+		resp, err := helper.makeRequest("GET", baseURL+"/api/admin/webhooks", nil, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403 for non-admin user, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CloseQueue_RejectsJoin", func(t *testing.T) {
+		queueID := helper.createTestQueue(t, adminToken, "Webhook Close Queue")
+
+		resp, err := helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/close", baseURL, queueID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		resp, err = helper.makeRequest("POST", fmt.Sprintf("%s/api/queues/%d/join", baseURL, queueID), models.JoinQueueRequest{QueueID: queueID}, userToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for closed queue, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("DeleteWebhook_Admin", func(t *testing.T) {
+		resp, err := helper.makeRequest("DELETE", fmt.Sprintf("%s/api/admin/webhooks/%d", baseURL, webhookID), nil, adminToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+}